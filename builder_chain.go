@@ -3,20 +3,63 @@ package cmdchain
 import (
 	"context"
 	"io"
+	"os"
 	"os/exec"
 	"sync"
+	"time"
 )
 
 type chain struct {
 	cmdDescriptors []cmdDescriptor
+	sequences      []sequence
 	inputs         []io.Reader
+	variables      map[string]string
+	globPolicy     GlobPolicy
+	builtins       map[string]Builtin
 	buildErrors    MultipleErrors
 	streamErrors   MultipleErrors
 
-	streamRoutinesWg sync.WaitGroup
-	errorChecker     ErrorChecker
-
-	hooks []hook
+	// streamErrorsMutex guards the streamErrors (the stream goroutines can set their errors at any time)
+	streamErrorsMutex sync.Mutex
+	streamRoutinesWg  sync.WaitGroup
+	errorChecker      ErrorChecker
+	resultPolicy      ResultPolicy
+	stdoutTail        int
+	stderrTail        int
+
+	ctx               context.Context
+	cancelSignal      os.Signal
+	cancelGracePeriod time.Duration
+	timeout           time.Duration
+
+	// runCtx is the context of the current run (see start). It is done if the chain's context is done or the chain
+	// has timed out. The nested chains (e.g. command substitutions) are bound to it. It is nil if the chain can not
+	// be canceled.
+	runCtx context.Context
+
+	// running is the running chain of the current run (see start). The nested chains of the preparers (e.g. command
+	// substitutions) are run by it, so they can be signaled together with the chain (see runningChain.runNested).
+	running *runningChain
+
+	hooks []commandHook
+
+	// template is true if the chain only holds the configuration of its commands. A template will never be started
+	// itself: each run gets its own instance of the chain, which links the streams of the commands (see instance).
+	template bool
+
+	// the configuration of the finalized streams of a template (see FinalizedBuilder.WithOutput and
+	// FinalizedBuilder.WithError). The replace flags are true if the streams replace the output forks of the commands.
+	finalized      bool
+	outputTargets  []io.Writer
+	errorTargets   []io.Writer
+	replaceOutputs bool
+	replaceErrors  bool
+
+	// shellCommands contains the shell commands of a template. They will be parsed again for each instance.
+	shellCommands []joinedShellCmd
+
+	// runMutex guards the commands of a template while an instance is created (see instance)
+	runMutex sync.Mutex
 }
 
 type cmdDescriptor struct {
@@ -28,6 +71,30 @@ type cmdDescriptor struct {
 	commandApplier []CommandApplier
 	errorChecker   ErrorChecker
 
+	// preparers are called right before the command is started (before the commandApplier). If one of them fails,
+	// the command will not be started.
+	preparers []func(command *exec.Cmd) error
+
+	// stage will be started instead of the command (if present)
+	stage stage
+
+	// ctx is the context of the command (see JoinWithContext)
+	ctx context.Context
+
+	// closeAfterStart contains the files which are passed to the command's process. They must
+	// be closed after the process is started.
+	closeAfterStart []io.Closer
+
+	// timeout is the maximum duration of the command (0 means no timeout)
+	timeout time.Duration
+
+	// contextLost is true if the command of a template was bound to a context which could not be passed to its
+	// copy for the next run (see instance)
+	contextLost bool
+
+	// inputRedirected is true if the command's input was replaced by a redirection (see redirectInput)
+	inputRedirected bool
+
 	inputStreams  []io.Reader
 	outputStreams []io.Writer
 	errorStreams  []io.Writer
@@ -41,6 +108,7 @@ func Builder() FirstCommandBuilder {
 		buildErrors:      buildErrors(),
 		streamErrors:     streamErrors(),
 		streamRoutinesWg: sync.WaitGroup{},
+		template:         true,
 	}
 }
 
@@ -49,19 +117,47 @@ func (c *chain) WithInput(sources ...io.Reader) ChainBuilder {
 	return c
 }
 
+func (c *chain) WithVariables(variables map[string]string) FirstCommandBuilder {
+	c.variables = variables
+	return c
+}
+
+func (c *chain) WithGlobExpansion(policy GlobPolicy) FirstCommandBuilder {
+	c.globPolicy = policy
+	return c
+}
+
+func (c *chain) WithBuiltins(builtins map[string]Builtin) FirstCommandBuilder {
+	if c.builtins == nil {
+		c.builtins = map[string]Builtin{}
+	}
+	for name, builtin := range builtins {
+		c.builtins[name] = builtin
+	}
+	return c
+}
+
 func (c *chain) JoinCmd(cmd *exec.Cmd) CommandBuilder {
 	if cmd == nil {
 		return c
 	}
 
-	c.cmdDescriptors = append(c.cmdDescriptors, cmdDescriptor{
+	return c.joinDescriptor(cmdDescriptor{
 		command: cmd,
 		outToIn: true,
 	})
+}
+
+// joinDescriptor joins the command of the given descriptor. The streams of a template's commands will not be linked
+// until the template is instanced (see instance).
+func (c *chain) joinDescriptor(cmdDesc cmdDescriptor) CommandBuilder {
+	c.cmdDescriptors = append(c.cmdDescriptors, cmdDesc)
+	c.streamErrorsMutex.Lock()
 	c.streamErrors.addError(nil)
+	c.streamErrorsMutex.Unlock()
 
-	if len(c.cmdDescriptors) > 1 {
-		c.linkStreams(cmd)
+	if !c.template && !c.isPipelineStart(len(c.cmdDescriptors)-1) {
+		c.linkStreams(cmdDesc.command)
 	}
 
 	return c
@@ -72,15 +168,32 @@ func (c *chain) Join(name string, args ...string) CommandBuilder {
 }
 
 func (c *chain) JoinWithContext(ctx context.Context, name string, args ...string) CommandBuilder {
-	return c.JoinCmd(exec.CommandContext(ctx, name, args...))
+	c.JoinCmd(exec.CommandContext(ctx, name, args...))
+	c.cmdDescriptors[len(c.cmdDescriptors)-1].ctx = ctx
+
+	return c
+}
+
+func (c *chain) JoinFunc(fn CommandFunc) CommandBuilder {
+	return c.joinFunc(fn, funcCommandName)
 }
 
 func (c *chain) Finalize() FinalizedBuilder {
+	if c.template {
+		// the streams will be finalized for each instance
+		c.finalized = true
+		return &finalizedChain{c}
+	}
+
 	if len(c.cmdDescriptors) == 0 {
-		return c
+		return &finalizedChain{c}
 	}
 
 	firstCmdDesc := &(c.cmdDescriptors[0])
+	if firstCmdDesc.inputRedirected {
+		// the redirection replaces the chain's input
+		return c.finalizeOutputs()
+	}
 
 	is := firstCmdDesc.inputStreams
 	firstCmdDesc.inputStreams = append([]io.Reader{}, c.inputs...)
@@ -91,18 +204,23 @@ func (c *chain) Finalize() FinalizedBuilder {
 	} else if len(c.inputs) > 1 {
 		var err error
 		firstCmdDesc.command.Stdin, err = c.combineStreamForCommand(0, c.inputs...)
-		if c.streamErrors.Errors()[0] == nil {
-			c.streamErrors.setError(0, err)
+		if err != nil {
+			c.setStreamError(0, err)
 		}
 	}
 
-	lastCmdDesc := &(c.cmdDescriptors[len(c.cmdDescriptors)-1])
-	if lastCmdDesc.outFork != nil {
-		lastCmdDesc.command.Stdout = lastCmdDesc.outFork
-	}
-	if lastCmdDesc.errFork != nil {
-		lastCmdDesc.command.Stderr = lastCmdDesc.errFork
+	return c.finalizeOutputs()
+}
+
+func (c *chain) finalizeOutputs() FinalizedBuilder {
+	for _, lastCmdDesc := range c.lastCmdDescriptors() {
+		if lastCmdDesc.outFork != nil {
+			lastCmdDesc.command.Stdout = lastCmdDesc.outFork
+		}
+		if lastCmdDesc.errFork != nil {
+			lastCmdDesc.command.Stderr = lastCmdDesc.errFork
+		}
 	}
 
-	return c
+	return &finalizedChain{c}
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"time"
 )
 
 func (c *chain) Apply(applier CommandApplier) CommandBuilder {
@@ -18,6 +20,12 @@ func (c *chain) ApplyBeforeStart(applier CommandApplier) CommandBuilder {
 	return c
 }
 
+// prepareBeforeStart adds a preparer to the last command (see cmdDescriptor.preparers).
+func (c *chain) prepareBeforeStart(preparer func(command *exec.Cmd) error) {
+	i := len(c.cmdDescriptors) - 1
+	c.cmdDescriptors[i].preparers = append(c.cmdDescriptors[i].preparers, preparer)
+}
+
 func (c *chain) ForwardError() CommandBuilder {
 	c.cmdDescriptors[len(c.cmdDescriptors)-1].errToIn = true
 	return c
@@ -82,7 +90,8 @@ func (c *chain) WithInjections(sources ...io.Reader) CommandBuilder {
 	cmdDesc := &(c.cmdDescriptors[len(c.cmdDescriptors)-1])
 	cmdDesc.inputStreams = append(cmdDesc.inputStreams, sources...)
 
-	if len(sources) > 0 {
+	// the sources of a template's command will be injected into each instance
+	if len(sources) > 0 && !c.template {
 		combineSrc := make([]io.Reader, 0, len(sources)+1)
 		if cmdDesc.command.Stdin != nil {
 			combineSrc = append(combineSrc, cmdDesc.command.Stdin)
@@ -100,7 +109,7 @@ func (c *chain) WithInjections(sources ...io.Reader) CommandBuilder {
 			var err error
 			cmdDesc.command.Stdin, err = c.combineStream(combineSrc...)
 			if err != nil {
-				c.streamErrors.setError(len(c.cmdDescriptors)-1, err)
+				c.setStreamError(len(c.cmdDescriptors)-1, err)
 			}
 		}
 	}
@@ -188,3 +197,8 @@ func (c *chain) WithErrorChecker(errChecker ErrorChecker) CommandBuilder {
 	c.cmdDescriptors[len(c.cmdDescriptors)-1].errorChecker = errChecker
 	return c
 }
+
+func (c *chain) WithTimeout(timeout time.Duration) CommandBuilder {
+	c.cmdDescriptors[len(c.cmdDescriptors)-1].timeout = timeout
+	return c
+}
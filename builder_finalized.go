@@ -2,86 +2,368 @@ package cmdchain
 
 import (
 	"bytes"
-	"fmt"
+	"context"
+	"errors"
 	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"time"
 )
 
-func (c *chain) WithOutput(targets ...io.Writer) FinalizedBuilder {
-	cmdDesc := &(c.cmdDescriptors[len(c.cmdDescriptors)-1])
-	cmdDesc.outputStreams = targets
+// finalizedChain is the finalized view of a chain. It is necessary because some methods of the FinalizedBuilder have
+// the same name as methods of the CommandBuilder (but with a different return type).
+type finalizedChain struct {
+	*chain
+}
+
+func (c *finalizedChain) WithOutput(targets ...io.Writer) FinalizedBuilder {
+	if c.template {
+		c.outputTargets, c.replaceOutputs = slices.Clone(targets), true
+		return c
+	}
 
-	if len(targets) == 1 {
-		cmdDesc.command.Stdout = targets[0]
-	} else if len(targets) > 1 {
-		cmdDesc.command.Stdout = io.MultiWriter(targets...)
+	for _, cmdDesc := range c.lastCmdDescriptors() {
+		cmdDesc.outputStreams = targets
+
+		if len(targets) == 1 {
+			cmdDesc.command.Stdout = targets[0]
+		} else if len(targets) > 1 {
+			cmdDesc.command.Stdout = io.MultiWriter(targets...)
+		}
 	}
 
 	return c
 }
 
-func (c *chain) WithAdditionalOutput(targets ...io.Writer) FinalizedBuilder {
-	cmdDesc := &(c.cmdDescriptors[len(c.cmdDescriptors)-1])
-	cmdDesc.outputStreams = append(cmdDesc.outputStreams, targets...)
+func (c *finalizedChain) WithAdditionalOutput(targets ...io.Writer) FinalizedBuilder {
+	if c.template {
+		c.outputTargets = append(c.outputTargets, targets...)
+		return c
+	}
+
+	for _, cmdDesc := range c.lastCmdDescriptors() {
+		cmdDesc.outputStreams = append(cmdDesc.outputStreams, targets...)
 
-	if len(cmdDesc.outputStreams) == 1 {
-		cmdDesc.command.Stdout = cmdDesc.outputStreams[0]
-	} else if len(cmdDesc.outputStreams) > 1 {
-		cmdDesc.command.Stdout = io.MultiWriter(cmdDesc.outputStreams...)
+		if len(cmdDesc.outputStreams) == 1 {
+			cmdDesc.command.Stdout = cmdDesc.outputStreams[0]
+		} else if len(cmdDesc.outputStreams) > 1 {
+			cmdDesc.command.Stdout = io.MultiWriter(cmdDesc.outputStreams...)
+		}
 	}
 
 	return c
 }
 
-func (c *chain) WithError(targets ...io.Writer) FinalizedBuilder {
-	cmdDesc := &(c.cmdDescriptors[len(c.cmdDescriptors)-1])
-	cmdDesc.errorStreams = targets
+func (c *finalizedChain) WithError(targets ...io.Writer) FinalizedBuilder {
+	if c.template {
+		c.errorTargets, c.replaceErrors = slices.Clone(targets), true
+		return c
+	}
+
+	for _, cmdDesc := range c.lastCmdDescriptors() {
+		cmdDesc.errorStreams = targets
 
-	if len(targets) == 1 {
-		cmdDesc.command.Stderr = targets[0]
-	} else if len(targets) > 1 {
-		cmdDesc.command.Stderr = io.MultiWriter(targets...)
+		if len(targets) == 1 {
+			cmdDesc.command.Stderr = targets[0]
+		} else if len(targets) > 1 {
+			cmdDesc.command.Stderr = io.MultiWriter(targets...)
+		}
 	}
 
 	return c
 }
 
-func (c *chain) WithAdditionalError(targets ...io.Writer) FinalizedBuilder {
-	cmdDesc := &(c.cmdDescriptors[len(c.cmdDescriptors)-1])
-	cmdDesc.errorStreams = append(cmdDesc.errorStreams, targets...)
+func (c *finalizedChain) WithAdditionalError(targets ...io.Writer) FinalizedBuilder {
+	if c.template {
+		c.errorTargets = append(c.errorTargets, targets...)
+		return c
+	}
+
+	for _, cmdDesc := range c.lastCmdDescriptors() {
+		cmdDesc.errorStreams = append(cmdDesc.errorStreams, targets...)
 
-	if len(cmdDesc.errorStreams) == 1 {
-		cmdDesc.command.Stderr = cmdDesc.errorStreams[0]
-	} else if len(cmdDesc.errorStreams) > 1 {
-		cmdDesc.command.Stderr = io.MultiWriter(cmdDesc.errorStreams...)
+		if len(cmdDesc.errorStreams) == 1 {
+			cmdDesc.command.Stderr = cmdDesc.errorStreams[0]
+		} else if len(cmdDesc.errorStreams) > 1 {
+			cmdDesc.command.Stderr = io.MultiWriter(cmdDesc.errorStreams...)
+		}
 	}
 
 	return c
 }
 
-func (c *chain) WithGlobalErrorChecker(errorChecker ErrorChecker) FinalizedBuilder {
+func (c *finalizedChain) WithGlobalErrorChecker(errorChecker ErrorChecker) FinalizedBuilder {
 	c.errorChecker = errorChecker
 	return c
 }
 
-func (c *chain) RunAndGet() (string, string, error) {
+func (c *finalizedChain) WithContext(ctx context.Context) FinalizedBuilder {
+	c.ctx = ctx
+	return c
+}
+
+func (c *finalizedChain) WithCancelSignal(sig os.Signal, gracePeriod time.Duration) FinalizedBuilder {
+	c.cancelSignal = sig
+	c.cancelGracePeriod = gracePeriod
+	return c
+}
+
+func (c *finalizedChain) WithTimeout(timeout time.Duration) FinalizedBuilder {
+	c.timeout = timeout
+	return c
+}
+
+func (c *finalizedChain) WithResultPolicy(policy ResultPolicy) FinalizedBuilder {
+	c.resultPolicy = policy
+	return c
+}
+
+func (c *finalizedChain) WithStdoutTail(maxBytes int) FinalizedBuilder {
+	c.stdoutTail = maxBytes
+	return c
+}
+
+func (c *finalizedChain) WithStderrTail(maxBytes int) FinalizedBuilder {
+	c.stderrTail = maxBytes
+	return c
+}
+
+func (c *finalizedChain) RunAndGet() (string, string, error) {
 	streamOut := &bytes.Buffer{}
 	streamErr := &bytes.Buffer{}
 
-	err := c.WithAdditionalOutput(streamOut).WithAdditionalError(streamErr).Run()
+	err := c.runWithStreams(streamOut, streamErr)
 
 	return streamOut.String(), streamErr.String(), err
 }
 
+func (c *finalizedChain) RunAndGetLimited(maxOut, maxErr int, mode CaptureMode) (Capture, Capture, error) {
+	streamOut := NewCaptureWriter(maxOut, mode)
+	streamErr := NewCaptureWriter(maxErr, mode)
+
+	err := c.runWithStreams(streamOut, streamErr)
+
+	return streamOut.Capture(), streamErr.Capture(), err
+}
+
+// runWithStreams runs the chain and writes its stdout and stderr additionally into the given streams.
+func (c *finalizedChain) runWithStreams(streamOut, streamErr io.Writer) error {
+	// the streams are only relevant for this run - so they are added to its instance only
+	r := &finalizedChain{c.runnable()}
+	r.WithAdditionalOutput(streamOut).WithAdditionalError(streamErr)
+
+	return r.run()
+}
+
 func (c *chain) Run() error {
+	return c.runnable().run()
+}
+
+func (c *chain) run() error {
+	running, err := c.start(false)
+	if err != nil {
+		return err
+	}
+
+	return running.Wait()
+}
+
+func (c *chain) Start() (RunningChain, error) {
+	// the caller can request a report of the running chain - so the streams must be counted
+	return c.runnable().start(true)
+}
+
+// start starts the chain. The streams of the commands are only counted for the report if withReport is true (see
+// runningChain.countStreams).
+func (c *chain) start(withReport bool) (RunningChain, error) {
 	if c.buildErrors.hasError {
-		return c.buildErrors
+		return nil, c.buildErrors
+	}
+	if c.ctx != nil && c.ctx.Err() != nil {
+		return nil, &CanceledError{Cause: context.Cause(c.ctx)}
+	}
+
+	r := newRunningChain(c)
+	r.withReport = withReport
+	pipelines := c.pipelines()
+
+	// the timeout includes the start of the first pipeline (e.g. its command substitutions)
+	ctx, cancel := c.ctx, context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, cancel = context.WithTimeoutCause(ctx, c.timeout, &chainTimeout{timeout: c.timeout})
+	}
+	c.runCtx, c.running = ctx, r
+	if ctx != nil {
+		go r.cancelOnDone(ctx)
+	}
+
+	// the first pipeline will always be started (unless the chain is canceled while its commands are prepared). So
+	// that start errors can be returned immediately.
+	started, err := r.startPipeline(pipelines[0])
+	if err != nil {
+		close(r.done)
+		cancel()
+		c.executeAfterRunHooks()
+		return nil, err
+	}
+
+	go func() {
+		defer close(r.done)
+		defer cancel()
+		defer c.executeAfterRunHooks()
+
+		r.err = r.run(pipelines, started)
+	}()
+
+	return r, nil
+}
+
+// run waits for the first (already started) pipeline and executes all following pipelines. If the first pipeline
+// was not started, it will be skipped such as the following pipelines of a stopped chain.
+func (r *runningChain) run(pipelines []pipeline, started bool) error {
+	c := r.chain
+
+	// the result of the last executed pipeline of the current statement
+	var runErrors MultipleErrors
+	var skipped []pipeline
+	statementErrors := statementErrors()
+
+	for i, p := range pipelines {
+		if i > 0 && p.operator == sequenceAlways {
+			// the previous statement is done
+			statementErrors.addError(runErrors.orNil())
+			runErrors = MultipleErrors{}
+		}
+
+		if i > 0 {
+			if r.exitRequested || !p.shouldRun(runErrors.hasError) {
+				r.skipPipeline(p)
+				skipped = append(skipped, p)
+				continue
+			}
+
+			var err error
+			started, err = r.startPipeline(p)
+			if err != nil {
+				return err
+			}
+		}
+		if !started {
+			// the chain is stopped (e.g. canceled) - so the pipeline will never be started
+			r.skipPipeline(p)
+			skipped = append(skipped, p)
+			if canceledErrors := r.canceledErrors(p); canceledErrors.hasError {
+				runErrors = canceledErrors
+			}
+			continue
+		}
+
+		runErrors = r.waitPipeline(p)
+		r.exitRequested = c.completePipeline(p)
+	}
+
+	//according to documentation of command's StdoutPipe()/StderrPipe() we have to wait for all stream reads are done
+	//after that we can wait for the commands:
+	//   "[...] It is thus incorrect to call Wait before all reads from the pipe have completed. [...]"
+	c.streamRoutinesWg.Wait()
+	c.mergeSubstitutionErrors()
+
+	if len(statementErrors.errors) > 0 {
+		statementErrors.addError(runErrors.orNil())
+		runErrors = statementErrors
+	}
+
+	// the streams of skipped commands are closed intentionally
+	streamErrors := c.streamErrorsWithout(skipped)
+
+	switch {
+	case runErrors.hasError && streamErrors.hasError:
+		return MultipleErrors{
+			errorMessage: "run and stream errors occurred",
+			errors:       []error{runErrors, streamErrors},
+			hasError:     true,
+		}
+	case runErrors.hasError:
+		return runErrors
+	case streamErrors.hasError:
+		return streamErrors
+	default:
+		return nil
+	}
+}
+
+func (r *runningChain) skipPipeline(p pipeline) {
+	r.chain.skipPipeline(p)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for cmdIndex := p.from; cmdIndex < p.to; cmdIndex++ {
+		r.states[cmdIndex].Status = CommandSkipped
+	}
+}
+
+// startPipeline starts all commands of the given pipeline. If the chain is stopped, the pipeline will not be started.
+func (r *runningChain) startPipeline(p pipeline) (bool, error) {
+	c := r.chain
+
+	r.mutex.Lock()
+	if r.stopped {
+		r.mutex.Unlock()
+		return false, nil
 	}
+	c.executeBeforeRunHooks(p)
+	r.mutex.Unlock()
 
-	c.executeBeforeRunHooks()
-	defer c.executeAfterRunHooks()
+	// the preparers can take a while (e.g. command substitutions). So the chain must be accessible in the meantime.
+	prepared, err := r.preparePipeline(p)
+	if err != nil {
+		return false, err
+	}
+
+	// the commands must not be signaled while they are starting
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.stopped {
+		return false, nil
+	}
+
+	for cmdIndex := p.from; cmdIndex < p.to; cmdIndex++ {
+		if command := prepared[cmdIndex-p.from]; command != nil {
+			cmdDescriptor := &(c.cmdDescriptors[cmdIndex])
+			cmdDescriptor.command.Args, cmdDescriptor.command.Env = command.Args, command.Env
+			cmdDescriptor.command.Dir = command.Dir
+			cmdDescriptor.preparers = nil
+		}
+	}
 
 	//we have to start all commands (non blocking!)
-	for cmdIndex, cmdDescriptor := range c.cmdDescriptors {
+	for cmdIndex := p.from; cmdIndex < p.to; cmdIndex++ {
+		cmdDescriptor := &(c.cmdDescriptors[cmdIndex])
+
+		var pipes []io.Closer
+		if cmdDescriptor.stage != nil {
+			pipes = c.stagePipes(cmdIndex)
+		}
+
+		if cmdDescriptor.ctx != nil {
+			sig, gracePeriod := c.cancelSignalAndGracePeriod()
+			cancelGracefully(cmdDescriptor.command, sig, gracePeriod)
+			if retry, isRetry := cmdDescriptor.stage.(*retryStage); isRetry {
+				// each attempt of the command must be canceled in the same way
+				retry.cancelSignal, retry.cancelGracePeriod = sig, gracePeriod
+			}
+		}
+		if retry, isRetry := cmdDescriptor.stage.(*retryStage); isRetry {
+			// no further attempt will be started if the chain is canceled (or timed out)
+			retry.chainCtx = c.runCtx
+		}
+
 		for _, applier := range cmdDescriptor.commandApplier {
 			applier(cmdIndex, cmdDescriptor.command)
 		}
@@ -90,21 +372,108 @@ func (c *chain) Run() error {
 		//and such functions have the potential to "lock" some memory
 		cmdDescriptor.commandApplier = nil
 
-		err := cmdDescriptor.command.Start()
+		if _, isRetry := cmdDescriptor.stage.(*retryStage); isRetry {
+			// the files are needed by all attempts of the command - so they will be closed by the stage
+			pipes = append(pipes, cmdDescriptor.closeAfterStart...)
+			cmdDescriptor.closeAfterStart = nil
+		}
+
+		r.countStreams(cmdIndex)
+		r.captureOutputTails(cmdIndex)
+		r.startTimes[cmdIndex] = time.Now()
+
+		var err error
+		if cmdDescriptor.stage != nil {
+			err = cmdDescriptor.stage.start(cmdDescriptor.command, pipes)
+		} else {
+			err = cmdDescriptor.command.Start()
+		}
 		if err != nil {
-			return fmt.Errorf("failed to start command: %w", err)
+			r.states[cmdIndex].Status = CommandFailed
+			r.states[cmdIndex].Err = err
+
+			// the start error of a nested chain (e.g. of a group) is passed through unchanged
+			if !errors.As(err, new(*startError)) {
+				err = &startError{err: err}
+			}
+			return false, err
+		}
+
+		r.states[cmdIndex].Status = CommandRunning
+		if cmdDescriptor.command.Process != nil {
+			r.states[cmdIndex].Pid = cmdDescriptor.command.Process.Pid
+		}
+		r.startTimer(cmdIndex)
+
+		for _, closer := range cmdDescriptor.closeAfterStart {
+			_ = closer.Close()
+		}
+		cmdDescriptor.closeAfterStart = nil
+	}
+
+	return true, nil
+}
+
+// preparePipeline calls the preparers of all commands of the given pipeline. The mutex must not be locked by the
+// caller: the preparers work on copies of the commands, which will be returned (nil for commands without preparers).
+func (r *runningChain) preparePipeline(p pipeline) ([]*exec.Cmd, error) {
+	prepared := make([]*exec.Cmd, p.to-p.from)
+
+	for cmdIndex := p.from; cmdIndex < p.to; cmdIndex++ {
+		cmdDescriptor := r.chain.cmdDescriptors[cmdIndex]
+		if len(cmdDescriptor.preparers) == 0 {
+			continue
+		}
+
+		command := &exec.Cmd{
+			Path: cmdDescriptor.command.Path,
+			Args: slices.Clone(cmdDescriptor.command.Args),
+			Env:  slices.Clone(cmdDescriptor.command.Env),
+			Dir:  cmdDescriptor.command.Dir,
+		}
+		for _, prepare := range cmdDescriptor.preparers {
+			if err := prepare(command); err != nil {
+				r.mutex.Lock()
+				r.states[cmdIndex].Status = CommandFailed
+				r.states[cmdIndex].Err = err
+				r.mutex.Unlock()
+
+				return nil, &startError{err: err}
+			}
 		}
+		prepared[cmdIndex-p.from] = command
 	}
 
+	return prepared, nil
+}
+
+func (r *runningChain) waitPipeline(p pipeline) MultipleErrors {
+	c := r.chain
+
 	runErrors := runErrors()
-	runErrors.errors = make([]error, len(c.cmdDescriptors))
+	runErrors.errors = make([]error, p.to-p.from)
 
 	// here we have to wait in reversed order because if the last command will not read their stdin anymore
 	// the previous command will wait endless for continuing writing to stdout
-	for cmdIndex := len(c.cmdDescriptors) - 1; cmdIndex >= 0; cmdIndex-- {
+	for cmdIndex := p.to - 1; cmdIndex >= p.from; cmdIndex-- {
 		cmdDescriptor := c.cmdDescriptors[cmdIndex]
 
-		err := cmdDescriptor.command.Wait()
+		var err error
+		if cmdDescriptor.stage != nil {
+			err = cmdDescriptor.stage.wait()
+		} else {
+			err = cmdDescriptor.command.Wait()
+		}
+		err = r.exited(cmdIndex, err)
+
+		if cmdIndex+1 < p.to && isBrokenPipe(err) && r.states[cmdIndex+1].InputClosedEarly {
+			// the downstream command has exited before this command could write all its output (e.g. `... | head -1`)
+			err = nil
+		}
+
+		// the input must be checked before it is closed (and even if it can not be closed, e.g. a here-string)
+		r.inputClosed(cmdIndex)
+
 		if closer, isCloser := cmdDescriptor.command.Stdin.(io.Closer); isCloser {
 			// This is little hard to understand. Let's assume we have the chain: cmd1->cmd2
 			//
@@ -118,7 +487,7 @@ func (c *chain) Run() error {
 		}
 
 		if err == nil {
-			runErrors.setError(cmdIndex, nil)
+			runErrors.setError(cmdIndex-p.from, nil)
 		} else {
 			shouldAdd := true
 
@@ -131,30 +500,12 @@ func (c *chain) Run() error {
 			}
 
 			if shouldAdd {
-				runErrors.setError(cmdIndex, err)
+				runErrors.setError(cmdIndex-p.from, r.commandError(cmdIndex, err))
 			} else {
-				runErrors.setError(cmdIndex, nil)
+				runErrors.setError(cmdIndex-p.from, nil)
 			}
 		}
 	}
 
-	//according to documentation of command's StdoutPipe()/StderrPipe() we have to wait for all stream reads are done
-	//after that we can wait for the commands:
-	//   "[...] It is thus incorrect to call Wait before all reads from the pipe have completed. [...]"
-	c.streamRoutinesWg.Wait()
-
-	switch {
-	case runErrors.hasError && c.streamErrors.hasError:
-		return MultipleErrors{
-			errorMessage: "run and stream errors occurred",
-			errors:       []error{runErrors, c.streamErrors},
-			hasError:     true,
-		}
-	case runErrors.hasError:
-		return runErrors
-	case c.streamErrors.hasError:
-		return c.streamErrors
-	default:
-		return nil
-	}
+	return c.resultPolicy.apply(runErrors)
 }
@@ -53,7 +53,7 @@ func (c *chain) linkStreams(cmd *exec.Cmd) {
 			}
 		}
 
-		cmd.Stdin, err = c.combineStream(outR, errR)
+		cmd.Stdin, err = c.combineLinkedStreams(outR, errR)
 	} else {
 		//this should never be happen!
 		err = errors.New("invalid stream configuration")
@@ -111,26 +111,61 @@ func (c *chain) forkStream(src io.ReadCloser, target io.Writer) (io.Reader, erro
 		//input from pipeWriter will redirected to pipeReader (the input for
 		//the next command)
 		_, err := io.Copy(io.MultiWriter(pipeWriter, target), src)
-		c.streamErrors.setError(cmdIndex, err)
+		c.setStreamError(cmdIndex, err)
 	}(len(c.cmdDescriptors)-1, src)
 
 	return pipeReader, nil
 }
 
+// redirectInput replaces the input of the last command with the given source. Such as a shell would do, the link
+// to the previous command's output will be stopped: the previous command's output will not be read anymore.
+func (c *chain) redirectInput(source io.Reader) {
+	cmdIndex := len(c.cmdDescriptors) - 1
+	cmdDesc := &(c.cmdDescriptors[cmdIndex])
+
+	if closer, isCloser := cmdDesc.command.Stdin.(io.Closer); isCloser && !c.isPipelineStart(cmdIndex) {
+		_ = closer.Close()
+	}
+
+	cmdDesc.command.Stdin = source
+	cmdDesc.inputStreams = append(cmdDesc.inputStreams, source)
+	cmdDesc.inputRedirected = true
+}
+
+// setStreamError sets the stream error of the given command. The stream goroutines are running while the chain is
+// built and run, so the stream errors must only be accessed under the lock.
+func (c *chain) setStreamError(cmdIndex int, err error) {
+	c.streamErrorsMutex.Lock()
+	defer c.streamErrorsMutex.Unlock()
+
+	c.streamErrors.setError(cmdIndex, err)
+}
+
 func (c *chain) combineStream(sources ...io.Reader) (*os.File, error) {
 	cmdIndex := len(c.cmdDescriptors) - 1
 	return c.combineStreamForCommand(cmdIndex, sources...)
 }
 
+// combineLinkedStreams combines the given output streams of the previous command. Unlike the streams of the user,
+// they will be closed if they can not be copied anymore (e.g. the command has exited before reading all of its
+// input). Otherwise, the previous command would wait for eternity until its output is read.
+func (c *chain) combineLinkedStreams(sources ...io.Reader) (*os.File, error) {
+	cmdIndex := len(c.cmdDescriptors) - 1
+	return c.combineStreams(cmdIndex, true, sources...)
+}
+
 func (c *chain) combineStreamForCommand(cmdIndex int, sources ...io.Reader) (*os.File, error) {
+	return c.combineStreams(cmdIndex, false, sources...)
+}
+
+func (c *chain) combineStreams(cmdIndex int, closeSources bool, sources ...io.Reader) (*os.File, error) {
 	pipeReader, pipeWriter, err := os.Pipe()
 	if err != nil {
 		return nil, err
 	}
 
-	streamErrors := MultipleErrors{
-		errors: make([]error, len(sources)),
-	}
+	// each goroutine sets only its own error, so they can be collected without lock
+	copyErrors := make([]error, len(sources))
 
 	wg := sync.WaitGroup{}
 	wg.Add(len(sources))
@@ -143,8 +178,20 @@ func (c *chain) combineStreamForCommand(cmdIndex int, sources ...io.Reader) (*os
 			defer wg.Done()
 
 			_, err := io.Copy(pipeWriter, src)
-			if err != nil {
-				streamErrors.setError(i, err)
+			if err != nil && closeSources {
+				// the previous command's pipe will be closed after the previous command has exited
+				// (see exec.Cmd.Wait), so it is expected that it is closed already in this case
+				if errors.Is(err, os.ErrClosed) {
+					err = nil
+				} else if closer, isCloser := src.(io.Closer); isCloser {
+					_ = closer.Close()
+				}
+			}
+
+			// a broken pipe means that the command has stopped reading its input (e.g. "head"),
+			// this is not an error of the stream itself
+			if err != nil && !isBrokenPipe(err) {
+				copyErrors[i] = err
 			}
 		}(i, src)
 	}
@@ -153,12 +200,19 @@ func (c *chain) combineStreamForCommand(cmdIndex int, sources ...io.Reader) (*os
 	go func() {
 		//we have to make sure that the pipe will be closed after all source streams
 		//are read. otherwise this will cause a never ending wait for finishing the command execution!
-		defer pipeWriter.Close()
-		defer c.streamErrors.setError(cmdIndex, streamErrors)
 		defer c.streamRoutinesWg.Done()
+		defer pipeWriter.Close()
 
 		//wait until all streams are read
 		wg.Wait()
+
+		streamErrors := MultipleErrors{
+			errors: make([]error, len(sources)),
+		}
+		for i, err := range copyErrors {
+			streamErrors.setError(i, err)
+		}
+		c.setStreamError(cmdIndex, streamErrors)
 	}()
 
 	return pipeReader, nil
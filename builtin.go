@@ -0,0 +1,219 @@
+package cmdchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Builtin is a command of shell command lines (see ChainBuilder.JoinShellCmd) which is implemented in Go. It will be
+// executed inside the current process instead of starting a command with the same name. The given command contains
+// the configuration of the builtin: its arguments, streams (stdin, stdout and stderr), working directory and
+// environment. The streams are never nil. The builtin is able to change the given state of the shell (e.g. the
+// working directory). Such as in the shell, these changes are only visible for the following statements if the
+// builtin is the only command of its pipeline (e.g. `cd dir && command` but not `cd dir | command`).
+type Builtin func(ctx context.Context, state *ShellState, command *exec.Cmd) error
+
+// ShellState is the state of a shell command line while it is executed. It will be changed by builtins (e.g. cd) and
+// applied to the following commands right before they are started.
+type ShellState struct {
+	// WorkingDirectory is the working directory of the following commands. A relative directory will be resolved
+	// relative to the working directory of the commands (see CommandBuilder.WithWorkingDirectory).
+	WorkingDirectory string
+
+	// Environment contains the additional environment variables (key=value) of the following commands.
+	Environment []string
+
+	lookup func(name string) (string, bool)
+}
+
+// LookupVariable returns the value of the variable with the given name (see ChainBuilder.JoinShellCmd). The variables
+// are looked up as they were when the builtin's statement was parsed.
+func (s *ShellState) LookupVariable(name string) (string, bool) {
+	if s.lookup == nil {
+		return os.LookupEnv(name)
+	}
+	return s.lookup(name)
+}
+
+func (s *ShellState) clone() ShellState {
+	return ShellState{
+		WorkingDirectory: s.WorkingDirectory,
+		Environment:      slices.Clone(s.Environment),
+	}
+}
+
+// merge merges the changes of a nested state (e.g. of a brace group) into this state. The working directory of
+// the nested state is relative to the working directory of this state.
+func (s *ShellState) merge(nested ShellState) {
+	s.WorkingDirectory = resolveDirectory(s.WorkingDirectory, nested.WorkingDirectory)
+	s.Environment = append(s.Environment, nested.Environment...)
+}
+
+// apply applies the state to the given command. The given environment variables (key=value) will be applied
+// after the state's environment. So they take precedence (e.g. the assignments of the command itself).
+func (s *ShellState) apply(command *exec.Cmd, env []string) {
+	if len(s.Environment) > 0 {
+		if len(command.Env) == 0 {
+			command.Env = os.Environ()
+		}
+		command.Env = append(command.Env, s.Environment...)
+		command.Env = append(command.Env, env...)
+	}
+	command.Dir = resolveDirectory(command.Dir, s.WorkingDirectory)
+}
+
+// BuiltinExitError is returned by builtins which are exited with a non-zero exit code (e.g. false or exit 1).
+type BuiltinExitError struct {
+	Code int
+}
+
+func (e *BuiltinExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code of the builtin.
+func (e *BuiltinExitError) ExitCode() int {
+	return e.Code
+}
+
+// exitRequest will be returned by the exit builtin. It stops the execution of the following statements.
+type exitRequest struct {
+	code int
+}
+
+func (e *exitRequest) Error() string {
+	return fmt.Sprintf("exit %d", e.code)
+}
+
+var defaultBuiltins = map[string]Builtin{
+	"cd":     cdBuiltin,
+	"export": exportBuiltin,
+	"exit":   exitBuiltin,
+}
+
+// lookupBuiltin returns the builtin with the given name. The builtins of the chain take precedence over the
+// default builtins. A builtin of the chain which is nil disables the default builtin with the same name.
+func (s *shellParser) lookupBuiltin(name string) (Builtin, bool) {
+	if builtin, found := s.chain.builtins[name]; found {
+		return builtin, builtin != nil
+	}
+
+	builtin, found := defaultBuiltins[name]
+	return builtin, found
+}
+
+// cdBuiltin changes the working directory of the following commands. It fails if the directory does not exist.
+func cdBuiltin(_ context.Context, state *ShellState, command *exec.Cmd) error {
+	args := command.Args[1:]
+	if len(args) > 1 {
+		return errors.New("cd: too many arguments")
+	}
+
+	var dir string
+	if len(args) == 0 {
+		var found bool
+		dir, found = state.LookupVariable("HOME")
+		if !found {
+			return errors.New("cd: HOME not set")
+		}
+	} else {
+		dir = args[0]
+	}
+	if dir == "-" {
+		return errors.New("cd: changing to the previous directory is not supported")
+	}
+
+	info, err := os.Stat(resolveDirectory(command.Dir, dir))
+	if err != nil {
+		return fmt.Errorf("cd: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("cd: %s: not a directory", dir)
+	}
+
+	state.WorkingDirectory = resolveDirectory(state.WorkingDirectory, dir)
+	return nil
+}
+
+// exportBuiltin passes the given variables (name=value or name) to the environment of the following commands.
+func exportBuiltin(_ context.Context, state *ShellState, command *exec.Cmd) error {
+	for _, arg := range command.Args[1:] {
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !variableNamePattern.MatchString(name) {
+			return fmt.Errorf("export: '%s': not a valid identifier", arg)
+		}
+
+		if !hasValue {
+			var found bool
+			value, found = state.LookupVariable(name)
+			if !found {
+				continue
+			}
+		}
+
+		state.Environment = append(state.Environment, name+"="+value)
+	}
+
+	return nil
+}
+
+// exitBuiltin stops the execution of the following statements. The chain will exit with the given exit code.
+func exitBuiltin(_ context.Context, _ *ShellState, command *exec.Cmd) error {
+	args := command.Args[1:]
+	if len(args) > 1 {
+		return errors.New("exit: too many arguments")
+	}
+
+	code := 0
+	if len(args) == 1 {
+		var err error
+		code, err = strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("exit: '%s': numeric argument required", args[0])
+		}
+	}
+
+	return &exitRequest{code: code}
+}
+
+// TrueBuiltin is a builtin which does nothing successfully. It is not registered by default (see
+// FirstCommandBuilder.WithBuiltins).
+func TrueBuiltin(context.Context, *ShellState, *exec.Cmd) error {
+	return nil
+}
+
+// FalseBuiltin is a builtin which does nothing unsuccessfully (exit code 1). It is not registered by default (see
+// FirstCommandBuilder.WithBuiltins).
+func FalseBuiltin(context.Context, *ShellState, *exec.Cmd) error {
+	return &BuiltinExitError{Code: 1}
+}
+
+// EchoBuiltin writes its arguments into stdout. The option -n suppresses the trailing newline. Other options (e.g.
+// -e) are not supported. It is not registered by default (see FirstCommandBuilder.WithBuiltins).
+func EchoBuiltin(_ context.Context, _ *ShellState, command *exec.Cmd) error {
+	args := command.Args[1:]
+	newline := "\n"
+	if len(args) > 0 && args[0] == "-n" {
+		args = args[1:]
+		newline = ""
+	}
+
+	_, err := io.WriteString(command.Stdout, strings.Join(args, " ")+newline)
+	return err
+}
+
+// resolveDirectory resolves the given directory relative to the base directory.
+func resolveDirectory(base, dir string) string {
+	if base == "" || filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(base, dir)
+}
@@ -0,0 +1,106 @@
+package cmdchain
+
+import (
+	"sync"
+)
+
+// CaptureMode defines which part of a stream will be captured by a CaptureWriter if the stream is longer than its
+// limit.
+type CaptureMode int
+
+const (
+	// CaptureHead captures the first bytes of the stream.
+	CaptureHead CaptureMode = iota
+
+	// CaptureTail captures the last bytes of the stream.
+	CaptureTail
+
+	// CaptureHeadAndTail captures the first and the last bytes of the stream (each half of the limit).
+	CaptureHeadAndTail
+)
+
+// Capture is the captured part of a stream (see CaptureWriter and FinalizedBuilder.RunAndGetLimited).
+type Capture struct {
+	// Data contains the captured bytes. If the head and the tail of the stream are captured (see CaptureHeadAndTail)
+	// and the stream is truncated, the tail directly follows the head.
+	Data string
+
+	// Truncated is true if the stream was longer than the limit. So Data does not contain the complete stream.
+	Truncated bool
+
+	// Size is the total number of bytes which were written into the stream.
+	Size int64
+}
+
+// CaptureWriter is a writer which captures a limited number of the written bytes. Which part of the written bytes
+// will be captured depends on its mode (see CaptureMode). So the memory is bounded. The writer accepts all bytes,
+// so it will never slow down or break the stream. It can be used as fork of a command (see
+// CommandBuilder.WithOutputForks).
+type CaptureWriter struct {
+	mutex    sync.Mutex
+	headSize int
+	head     []byte
+	tail     *tailWriter
+	size     int64
+}
+
+// NewCaptureWriter creates a new CaptureWriter which captures at most limit bytes in the given mode.
+func NewCaptureWriter(limit int, mode CaptureMode) *CaptureWriter {
+	limit = max(limit, 0)
+
+	w := &CaptureWriter{}
+	switch mode {
+	case CaptureTail:
+		w.tail = &tailWriter{size: limit}
+	case CaptureHeadAndTail:
+		w.headSize = limit - limit/2
+		w.tail = &tailWriter{size: limit / 2}
+	default:
+		w.headSize = limit
+	}
+
+	return w
+}
+
+func (w *CaptureWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.size += int64(len(p))
+
+	rest := p
+	if len(w.head) < w.headSize {
+		n := min(w.headSize-len(w.head), len(rest))
+		w.head = append(w.head, rest[:n]...)
+		rest = rest[n:]
+	}
+
+	// only the bytes which are not part of the head belong to the tail
+	if len(rest) > 0 && w.tail != nil && w.tail.size > 0 {
+		_, _ = w.tail.Write(rest)
+	}
+
+	return len(p), nil
+}
+
+// Capture returns the captured part of the written bytes.
+func (w *CaptureWriter) Capture() Capture {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	data := string(w.head)
+	if w.tail != nil {
+		data += string(w.tail.Bytes())
+	}
+
+	return Capture{
+		Data:      data,
+		Truncated: w.size > int64(len(data)),
+		Size:      w.size,
+	}
+}
+
+// String returns the captured bytes (see Capture).
+func (w *CaptureWriter) String() string {
+	return w.Capture().Data
+}
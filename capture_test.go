@@ -0,0 +1,76 @@
+package cmdchain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCaptureWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int
+		mode   CaptureMode
+		writes []string
+		expect Capture
+	}{
+		{"head", 4, CaptureHead, []string{"hello", " world"}, Capture{Data: "hell", Truncated: true, Size: 11}},
+		{"head fits", 20, CaptureHead, []string{"hello", " world"}, Capture{Data: "hello world", Size: 11}},
+		{"tail", 4, CaptureTail, []string{"hello", " world"}, Capture{Data: "orld", Truncated: true, Size: 11}},
+		{"tail fits", 11, CaptureTail, []string{"hello", " world"}, Capture{Data: "hello world", Size: 11}},
+		{"head and tail", 5, CaptureHeadAndTail, []string{"hel", "lo wo", "rld"}, Capture{Data: "helld", Truncated: true, Size: 11}},
+		{"head and tail fits", 11, CaptureHeadAndTail, []string{"hello", " world"}, Capture{Data: "hello world", Size: 11}},
+		{"head and tail short", 20, CaptureHeadAndTail, []string{"hello", " world"}, Capture{Data: "hello world", Size: 11}},
+		{"nothing", 0, CaptureTail, []string{"hello"}, Capture{Data: "", Truncated: true, Size: 5}},
+		{"negative", -1, CaptureHeadAndTail, []string{"hello"}, Capture{Data: "", Truncated: true, Size: 5}},
+		{"empty", 4, CaptureHead, nil, Capture{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toTest := NewCaptureWriter(tt.limit, tt.mode)
+
+			for _, write := range tt.writes {
+				n, err := toTest.Write([]byte(write))
+				require.NoError(t, err)
+				require.Equal(t, len(write), n)
+			}
+
+			assert.Equal(t, tt.expect, toTest.Capture())
+			assert.Equal(t, tt.expect.Data, toTest.String())
+		})
+	}
+}
+
+func TestRunAndGetLimited(t *testing.T) {
+	sOut, sErr, err := Builder().
+		Join(testHelper, "-o", "hello world", "-e", "error").
+		Finalize().RunAndGetLimited(5, 10, CaptureTail)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Capture{Data: "orld\n", Truncated: true, Size: 12}, sOut)
+	assert.Equal(t, Capture{Data: "error\n", Size: 6}, sErr)
+}
+
+func TestRunAndGetLimited_failing(t *testing.T) {
+	sOut, sErr, err := Builder().
+		Join(testHelper, "-o", "hello world", "-x", "1").
+		Finalize().RunAndGetLimited(6, 6, CaptureHead)
+
+	assert.ErrorIs(t, err, ErrRun)
+	assert.Equal(t, Capture{Data: "hello ", Truncated: true, Size: 12}, sOut)
+	assert.Equal(t, Capture{}, sErr)
+}
+
+func TestCaptureWriter_asFork(t *testing.T) {
+	fork := NewCaptureWriter(8, CaptureHeadAndTail)
+
+	sOut, _, err := Builder().
+		Join("seq", "1000").WithOutputForks(fork).
+		Join("wc", "-l").
+		Finalize().RunAndGet()
+
+	require.NoError(t, err)
+	assert.Equal(t, "1000\n", sOut)
+	assert.Equal(t, Capture{Data: "1\n2\n000\n", Truncated: true, Size: 3893}, fork.Capture())
+}
@@ -9,7 +9,10 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -453,6 +456,235 @@ func TestWithContext(t *testing.T) {
 	assert.Equal(t, "OUT\n", output.String(), "It seams that the process was not interrupted.")
 }
 
+func TestWithContext_signalsCommand(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := Builder().
+		JoinWithContext(ctx, testHelper, "-to", "10s", "-ti", "10ms").
+		Finalize().WithCancelSignal(os.Interrupt, 5*time.Second).Run()
+
+	assert.Error(t, err)
+
+	var cmdErr *CommandError
+	assert.ErrorAs(t, err.(MultipleErrors).Errors()[0], &cmdErr)
+	// the test helper exits with 125 after receiving any signal (it would be -1 if it was killed)
+	assert.Equal(t, 125, cmdErr.ExitCode)
+}
+
+type slowWriter struct {
+	bytes.Buffer
+}
+
+func (s *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(20 * time.Millisecond)
+	return s.Buffer.Write(p)
+}
+
+func TestWithContext_notCanceled_slowOutput(t *testing.T) {
+	output := &slowWriter{}
+
+	// the grace period must not limit the time to read the output of a command which has exited normally
+	err := Builder().
+		JoinWithContext(context.Background(), "seq", "1", "100000").
+		Finalize().WithOutput(output).WithCancelSignal(syscall.SIGTERM, 200*time.Millisecond).Run()
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(output.String(), "\n100000\n"), "the output should be complete")
+}
+
+func TestWithContext_killAfterGracePeriod(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := Builder().
+		JoinWithContext(ctx, "sh", "-c", `trap "" TERM; exec sleep 5`).
+		Finalize().WithCancelSignal(syscall.SIGTERM, 200*time.Millisecond).Run()
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second, "the command should be killed after the grace period")
+}
+
+func TestFinalizedWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := Builder().
+		Join(testHelper, "-to", "10s", "-ti", "10ms").
+		Join("grep", "OUT").
+		Finalize().WithContext(ctx).Run()
+
+	assert.Less(t, time.Since(start), 5*time.Second, "It seams that the chain was not canceled.")
+	assert.Error(t, err)
+
+	var canceledErr *CanceledError
+	assert.ErrorAs(t, err.(MultipleErrors).Errors()[0], &canceledErr)
+	assert.ErrorIs(t, canceledErr, context.DeadlineExceeded)
+	// the test helper exits with 125 after receiving any signal
+	assert.Contains(t, canceledErr.Error(), "exit status 125")
+}
+
+func TestFinalizedWithContext_killAfterGracePeriod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	running, err := Builder().
+		Join("sh", "-c", `trap "" TERM; sleep 10`).
+		Finalize().
+		WithContext(ctx).
+		WithCancelSignal(syscall.SIGTERM, 100*time.Millisecond).
+		Start()
+	assert.NoError(t, err)
+
+	// give the shell the chance to ignore the signal
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-running.Done():
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "chain should be killed after grace period")
+	}
+
+	err = running.Wait()
+	assert.Error(t, err)
+	assert.ErrorIs(t, err.(MultipleErrors).Errors()[0], context.Canceled)
+	assert.Contains(t, err.Error(), "signal: killed")
+}
+
+func TestFinalizedWithContext_shellCommand(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	sOut, _, err := Builder().
+		JoinShellCmd(fmt.Sprintf("%s -to 10s -ti 10ms | ( grep OUT; echo group ); echo never", testHelper)).
+		Finalize().WithContext(ctx).RunAndGet()
+
+	assert.Error(t, err)
+	assert.NotContains(t, sOut, "group")
+	assert.NotContains(t, sOut, "never")
+
+	statements := err.(MultipleErrors).Errors()
+	assert.Len(t, statements, 2)
+	assert.ErrorIs(t, statements[1].(MultipleErrors).Errors()[0], context.DeadlineExceeded)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "nested errors should be unwrapped")
+	assert.ErrorIs(t, err, ErrRun)
+}
+
+func TestFinalizedWithContext_alreadyCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Builder().
+		Join(testHelper, "-o", "TEST").
+		Finalize().WithContext(ctx).Run()
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.IsType(t, &CanceledError{}, err)
+	assert.ErrorIs(t, err, ErrRun)
+	assert.NotErrorIs(t, err, ErrStart)
+}
+
+func TestWithTimeout(t *testing.T) {
+	start := time.Now()
+	err := Builder().
+		Join(testHelper, "-to", "10s", "-ti", "10ms").WithTimeout(100*time.Millisecond).
+		Join("grep", "OUT").
+		Finalize().Run()
+
+	assert.Less(t, time.Since(start), 5*time.Second, "It seams that the command was not terminated.")
+	assert.Error(t, err)
+
+	var timeoutErr *TimeoutError
+	assert.ErrorAs(t, err.(MultipleErrors).Errors()[0], &timeoutErr)
+	assert.Equal(t, 0, timeoutErr.Index)
+	assert.Equal(t, testHelper+` "-to" "10s" "-ti" "10ms"`, timeoutErr.Command)
+	assert.Equal(t, 100*time.Millisecond, timeoutErr.Timeout)
+	assert.Contains(t, timeoutErr.Error(), "timed out after 100ms: exit status 125")
+	assert.NoError(t, err.(MultipleErrors).Errors()[1])
+}
+
+func TestWithTimeout_notExpired(t *testing.T) {
+	toTest := Builder().
+		Join(testHelper, "-o", "TEST").WithTimeout(5 * time.Second)
+
+	runAndCompare(t, toTest, "TEST\n")
+}
+
+func TestWithTimeout_shellCommand(t *testing.T) {
+	err := Builder().
+		JoinShellCmd(fmt.Sprintf("%s -to 10s -ti 10ms | grep OUT", testHelper)).WithTimeout(100 * time.Millisecond).
+		Finalize().Run()
+
+	assert.Error(t, err)
+	assert.IsType(t, &TimeoutError{}, err.(MultipleErrors).Errors()[0])
+
+	// the timeout is applied to all commands - but grep could also exit because its input is closed
+	if err.(MultipleErrors).Errors()[1] != nil {
+		assert.IsType(t, &TimeoutError{}, err.(MultipleErrors).Errors()[1])
+	}
+}
+
+func TestFinalizedWithTimeout(t *testing.T) {
+	start := time.Now()
+	err := Builder().
+		Join(testHelper, "-to", "10s", "-ti", "10ms").
+		Join("grep", "OUT").
+		Finalize().WithTimeout(100 * time.Millisecond).Run()
+
+	assert.Less(t, time.Since(start), 5*time.Second, "It seams that the chain was not terminated.")
+	assert.Error(t, err)
+
+	for i, cmdErr := range err.(MultipleErrors).Errors() {
+		var timeoutErr *TimeoutError
+		assert.ErrorAs(t, cmdErr, &timeoutErr)
+		assert.Equal(t, i, timeoutErr.Index)
+		assert.Equal(t, 100*time.Millisecond, timeoutErr.Timeout)
+	}
+}
+
+func TestFinalizedWithTimeout_notStartedPipeline(t *testing.T) {
+	err := Builder().
+		JoinShellCmd(fmt.Sprintf("%s -to 10s -ti 10ms; echo never", testHelper)).
+		Finalize().WithTimeout(100 * time.Millisecond).Run()
+
+	statements := err.(MultipleErrors).Errors()
+	assert.Len(t, statements, 2)
+
+	var timeoutErr *TimeoutError
+	assert.ErrorAs(t, statements[1].(MultipleErrors).Errors()[0], &timeoutErr)
+	assert.Equal(t, 1, timeoutErr.Index)
+	assert.Equal(t, `/usr/bin/echo "never"`, timeoutErr.Command)
+	assert.NoError(t, timeoutErr.Err)
+}
+
+func TestFinalizedWithTimeout_nestedChains(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+	}{
+		{"command substitution", "echo $(sleep 3)"},
+		{"process substitution", "cat <(sleep 3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			err := Builder().
+				JoinShellCmd(tt.command).
+				Finalize().WithTimeout(300 * time.Millisecond).Run()
+
+			assert.Less(t, time.Since(start), 2*time.Second, "the nested chain should be canceled")
+
+			var timeoutErr *TimeoutError
+			if assert.ErrorAs(t, err, &timeoutErr) {
+				assert.Equal(t, 300*time.Millisecond, timeoutErr.Timeout)
+			}
+		})
+	}
+}
+
 func TestSimple_ErrorForked(t *testing.T) {
 	output := &bytes.Buffer{}
 
@@ -575,6 +807,9 @@ func TestInvalidStreamLink(t *testing.T) {
 	assert.Error(t, err)
 	mError := err.(MultipleErrors)
 	assert.Equal(t, "invalid stream configuration", mError.Errors()[0].Error())
+	assert.ErrorIs(t, err, ErrBuild)
+	assert.NotErrorIs(t, err, ErrRun)
+	assert.NotErrorIs(t, err, ErrStream)
 }
 
 func TestBrokenStream(t *testing.T) {
@@ -593,6 +828,10 @@ func TestBrokenStream(t *testing.T) {
 	assert.Error(t, err)
 	mError := err.(MultipleErrors)
 	assert.Contains(t, mError.Errors()[1].Error(), "file already closed")
+	assert.ErrorIs(t, err, ErrStream)
+	assert.ErrorIs(t, err, os.ErrClosed)
+	assert.NotErrorIs(t, err, ErrRun)
+	assert.NotErrorIs(t, err, ErrBuild)
 }
 
 func TestInvalidCommand(t *testing.T) {
@@ -603,6 +842,20 @@ func TestInvalidCommand(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to start command")
+	assert.ErrorIs(t, err, ErrStart)
+	assert.ErrorIs(t, err, exec.ErrNotFound)
+	assert.NotErrorIs(t, err, ErrRun)
+	assert.NotErrorIs(t, err, ErrBuild)
+}
+
+func TestInvalidCommand_insideGroup(t *testing.T) {
+	err := Builder().
+		JoinShellCmd("(invalidApplication)").
+		Finalize().Run()
+
+	assert.ErrorIs(t, err, ErrStart)
+	assert.ErrorIs(t, err, exec.ErrNotFound)
+	assert.Equal(t, 1, strings.Count(err.Error(), "failed to start command"), err.Error())
 }
 
 func TestBrokenStreamAndRunError(t *testing.T) {
@@ -622,6 +875,12 @@ func TestBrokenStreamAndRunError(t *testing.T) {
 	assert.Equal(t, 2, len(mError.Errors()))
 	assert.Contains(t, mError.Errors()[0].Error(), "one or more command has returned an error")
 	assert.Contains(t, mError.Errors()[1].Error(), "one or more command stream copies failed")
+	assert.ErrorIs(t, err, ErrRun)
+	assert.ErrorIs(t, err, ErrStream)
+	assert.NotErrorIs(t, err, ErrBuild)
+
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
 }
 
 func TestWithErrorChecker_IgnoreExitCode(t *testing.T) {
@@ -651,6 +910,57 @@ func TestWithErrorChecker_IgnoreExitCode_globalAndSpecific(t *testing.T) {
 	assert.NoError(t, err, "it seams that the specific error checker was not called")
 }
 
+func TestWithErrorChecker_IgnoreStderrMatching(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	err := Builder().
+		Join(testHelper, "-e", "known problem", "-x", "1").
+		Join(testHelper, "-e", "unknown problem", "-x", "1").
+		Finalize().
+		WithError(output).
+		WithStderrTail(100).
+		WithGlobalErrorChecker(IgnoreStderrMatching(regexp.MustCompile(`^known`))).
+		Run()
+
+	assert.Error(t, err)
+	assert.NoError(t, err.(MultipleErrors).Errors()[0])
+	assert.Error(t, err.(MultipleErrors).Errors()[1])
+	assert.Equal(t, "unknown problem\n", output.String())
+}
+
+func TestWithErrorChecker_IgnoreStdoutMatching(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	err := Builder().
+		Join(testHelper, "-o", "no matches", "-x", "1").
+		Join("grep", "-v", "matches").WithErrorChecker(IgnoreStdoutMatching(regexp.MustCompile(`^$`))).
+		Finalize().
+		WithOutput(output).
+		WithStdoutTail(100).
+		WithGlobalErrorChecker(ByCommandName(map[string]ErrorChecker{
+			filepath.Base(testHelper): IgnoreStdoutMatching(regexp.MustCompile(`no matches`)),
+		})).
+		Run()
+
+	assert.Error(t, err, "stdout which is piped into the next command is not captured")
+	assert.Error(t, err.(MultipleErrors).Errors()[0])
+	assert.NoError(t, err.(MultipleErrors).Errors()[1])
+	assert.Empty(t, output.String())
+}
+
+func TestWithErrorChecker_combined(t *testing.T) {
+	err := Builder().
+		Join(testHelper, "-x", "1").
+		Join(testHelper, "-x", "2").
+		Join(testHelper, "-x", "3").
+		Finalize().WithGlobalErrorChecker(And(IgnoreExitCode(1, 2), Not(IgnoreExitCode(2)))).Run()
+
+	mError := err.(MultipleErrors)
+	assert.NoError(t, mError.Errors()[0])
+	assert.Error(t, mError.Errors()[1])
+	assert.Error(t, mError.Errors()[2])
+}
+
 func TestHeadWillInterruptPreviousCommand(t *testing.T) {
 	output := &bytes.Buffer{}
 
@@ -746,6 +1056,180 @@ func TestHeadWillInterruptPreviousCommand_withCombined(t *testing.T) {
 	assert.Equal(t, "ERR", strings.Trim(output.String(), "\n"))
 }
 
+func TestWithResultPolicy(t *testing.T) {
+	tests := []struct {
+		policy       ResultPolicy
+		expectErrors []string
+	}{
+		{ResultAll, []string{"exit status 1", "exit status 2", ""}},
+		{ResultLastCommand, nil},
+		{ResultPipefail, []string{"", "exit status 2", ""}},
+		{ResultFirstFailure, []string{"exit status 1", "", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d", tt.policy), func(t *testing.T) {
+			err := Builder().
+				Join(testHelper, "-x", "1").
+				Join(testHelper, "-x", "2").
+				Join(testHelper, "-x", "0").
+				Finalize().WithResultPolicy(tt.policy).Run()
+
+			if tt.expectErrors == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.Error(t, err)
+			for i, cmdErr := range err.(MultipleErrors).Errors() {
+				if tt.expectErrors[i] == "" {
+					assert.NoError(t, cmdErr)
+				} else {
+					assert.EqualError(t, cmdErr, tt.expectErrors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWithResultPolicy_shellCommand(t *testing.T) {
+	sOut, _, err := Builder().
+		JoinShellCmd("false | true && echo last || echo failed").
+		Finalize().WithResultPolicy(ResultLastCommand).RunAndGet()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "last\n", sOut)
+
+	sOut, _, err = Builder().
+		JoinShellCmd("false | true && echo last || echo failed").
+		Finalize().WithResultPolicy(ResultPipefail).RunAndGet()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "failed\n", sOut)
+}
+
+func TestBrokenPipeIsIgnored(t *testing.T) {
+	toTest := Builder().
+		Join("yes").
+		Join("head", "-1")
+
+	runAndCompare(t, toTest, "y\n")
+}
+
+func TestBrokenPipeIsIgnored_inputClosedEarly(t *testing.T) {
+	running, err := Builder().
+		Join("yes").
+		Join("head", "-1").
+		Finalize().WithOutput(&bytes.Buffer{}).Start()
+	assert.NoError(t, err)
+	assert.NoError(t, running.Wait())
+
+	assert.True(t, running.States()[1].InputClosedEarly)
+	assert.Equal(t, syscall.SIGPIPE, terminatingSignal(running.States()[0].Err))
+}
+
+func TestBrokenPipeIsNotIgnored_inputNotClosedEarly(t *testing.T) {
+	// the first command is terminated by SIGPIPE, but not because the second command has closed its input
+	running, err := Builder().
+		Join("sh", "-c", "echo a; kill -PIPE $$").
+		Join("cat").
+		Finalize().WithOutput(&bytes.Buffer{}).Start()
+	assert.NoError(t, err)
+
+	err = running.Wait()
+	assert.Error(t, err)
+	assert.False(t, running.States()[1].InputClosedEarly)
+
+	var cmdErr *CommandError
+	if assert.ErrorAs(t, err, &cmdErr) {
+		assert.Equal(t, 0, cmdErr.Index)
+		assert.Equal(t, syscall.SIGPIPE, cmdErr.Signal)
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	err := Builder().
+		Join(testHelper, "-o", "OUT").
+		Join(testHelper, "-e", "ERROR", "-x", "13").
+		Finalize().Run()
+
+	var cmdErr *CommandError
+	if assert.ErrorAs(t, err, &cmdErr) {
+		assert.Equal(t, 1, cmdErr.Index)
+		assert.Equal(t, fmt.Sprintf(`%s "-e" "ERROR" "-x" "13"`, testHelper), cmdErr.Command)
+		assert.Equal(t, 13, cmdErr.ExitCode)
+		assert.Nil(t, cmdErr.Signal)
+		assert.Nil(t, cmdErr.Stderr, "stderr tail is not enabled")
+		assert.IsType(t, &exec.ExitError{}, cmdErr.Err)
+	}
+	assert.NoError(t, err.(MultipleErrors).Errors()[0])
+}
+
+func TestCommandError_stderrTail(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	err := Builder().
+		Join(testHelper, "-e", "FIRST-ERROR", "-x", "1").
+		Join(testHelper, "-e", "SECOND-ERROR", "-x", "2").
+		Finalize().WithError(output).WithStderrTail(6).Run()
+
+	var cmdErr *CommandError
+	if assert.ErrorAs(t, err, &cmdErr) {
+		assert.Equal(t, 0, cmdErr.Index)
+		assert.Equal(t, "ERROR\n", string(cmdErr.Stderr))
+	}
+	if assert.ErrorAs(t, err.(MultipleErrors).Errors()[1], &cmdErr) {
+		assert.Equal(t, 1, cmdErr.Index)
+		assert.Equal(t, "ERROR\n", string(cmdErr.Stderr))
+	}
+	assert.Contains(t, output.String(), "SECOND-ERROR\n", "the stderr must be written into the configured stream too")
+}
+
+func TestCommandError_stderrTailForwarded(t *testing.T) {
+	err := Builder().
+		Join(testHelper, "-e", "ERROR", "-x", "1").ForwardError().DiscardStdOut().
+		Join("grep", "MISSING").
+		Finalize().WithStderrTail(10).Run()
+
+	mErr := err.(MultipleErrors)
+	var cmdErr *CommandError
+	if assert.ErrorAs(t, mErr.Errors()[0], &cmdErr) {
+		assert.Nil(t, cmdErr.Stderr, "forwarded stderr should not be captured")
+	}
+	if assert.ErrorAs(t, mErr.Errors()[1], &cmdErr) {
+		assert.Empty(t, cmdErr.Stderr)
+	}
+}
+
+func TestCommandError_signal(t *testing.T) {
+	running, err := Builder().
+		Join("sleep", "10").
+		Finalize().Start()
+	assert.NoError(t, err)
+
+	assert.NoError(t, running.Kill())
+
+	var cmdErr *CommandError
+	if assert.ErrorAs(t, running.Wait(), &cmdErr) {
+		assert.Equal(t, syscall.SIGKILL, cmdErr.Signal)
+		assert.Equal(t, -1, cmdErr.ExitCode)
+	}
+}
+
+func TestCommandError_errorCheckerGetsOriginalError(t *testing.T) {
+	var checked error
+
+	err := Builder().
+		Join(testHelper, "-x", "1").WithErrorChecker(func(_ int, _ *exec.Cmd, err error) bool {
+		checked = err
+		return true
+	}).
+		Finalize().Run()
+
+	assert.IsType(t, &exec.ExitError{}, checked)
+	assert.IsType(t, &CommandError{}, err.(MultipleErrors).Errors()[0])
+}
+
 func TestShellCommand(t *testing.T) {
 	toTest := Builder().JoinShellCmd("echo 'Hello, World!' | wc -l")
 
@@ -827,6 +1311,621 @@ func TestShellCommand_touchOnly(t *testing.T) {
 	assert.Equal(t, "", strings.TrimSpace(string(content)))
 }
 
+func TestShellCommand_inputRedirection(t *testing.T) {
+	tmpDir := t.TempDir()
+	err := os.WriteFile(path.Join(tmpDir, "in"), []byte("first line\nsecond line\n"), 0644)
+	assert.NoError(t, err)
+
+	toTest := Builder().
+		JoinShellCmd(fmt.Sprintf("grep second < %s/in", tmpDir))
+
+	runAndCompare(t, toTest, "second line\n")
+}
+
+func TestShellCommand_inputRedirection_replacesInput(t *testing.T) {
+	tmpDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(path.Join(tmpDir, "first"), []byte("FIRST\n"), 0644))
+	assert.NoError(t, os.WriteFile(path.Join(tmpDir, "second"), []byte("SECOND\n"), 0644))
+
+	tests := []struct {
+		name      string
+		toTest    FinalizedBuilder
+		expectOut string
+	}{
+		{
+			name:      "piped input",
+			toTest:    Builder().JoinShellCmd(fmt.Sprintf("echo PIPE | cat < %s/first", tmpDir)).Finalize(),
+			expectOut: "FIRST\n",
+		},
+		{
+			name:      "multiple redirections",
+			toTest:    Builder().JoinShellCmd(fmt.Sprintf("cat < %[1]s/first < %[1]s/second", tmpDir)).Finalize(),
+			expectOut: "SECOND\n",
+		},
+		{
+			name:      "chain input",
+			toTest:    Builder().WithInput(strings.NewReader("INPUT\n")).JoinShellCmd(fmt.Sprintf("cat < %s/first", tmpDir)).Finalize(),
+			expectOut: "FIRST\n",
+		},
+		{
+			name:      "here-string",
+			toTest:    Builder().Join("echo", "PIPE").JoinShellCmd("cat <<< HERE").Finalize(),
+			expectOut: "HERE\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+
+			err := tt.toTest.WithOutput(output).Run()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectOut, output.String())
+		})
+	}
+}
+
+func TestShellCommand_inputRedirection_missingFile(t *testing.T) {
+	err := Builder().
+		JoinShellCmd(fmt.Sprintf("cat < %s/in", t.TempDir())).
+		Finalize().Run()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no such file or directory")
+}
+
+func TestShellCommand_commandSubstitution(t *testing.T) {
+	tmpDir := t.TempDir()
+	err := os.WriteFile(path.Join(tmpDir, "pattern.txt"), []byte("second\n"), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(path.Join(tmpDir, "data.csv"), []byte("first,1\nsecond,2\n"), 0644)
+	assert.NoError(t, err)
+
+	toTest := Builder().
+		JoinShellCmd(fmt.Sprintf("grep $(cat %[1]s/pattern.txt) %[1]s/data.csv", tmpDir))
+
+	runAndCompare(t, toTest, "second,2\n")
+}
+
+func TestShellCommand_commandSubstitution_executedBeforeCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		command    string
+		expectOut  string
+		expectFile bool
+	}{
+		{"after previous statements", "mkdir -p d/x; touch d/x/f; echo $(ls d/x)", "f\n", true},
+		{"not executed if the command is skipped", "true || echo $(touch f)", "", false},
+		{"working directory", "mkdir -p d/y; cd d/y; echo $(%[1]s -pwd)", "%[1]s/d/y\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := path.Join(tmpDir, strings.ReplaceAll(tt.name, " ", "_"))
+			assert.NoError(t, os.Mkdir(dir, 0755))
+
+			command, expectOut := tt.command, tt.expectOut
+			if strings.Contains(command, "%[") {
+				command = fmt.Sprintf(command, testHelper)
+			}
+			if strings.Contains(expectOut, "%[") {
+				expectOut = fmt.Sprintf(expectOut, dir)
+			}
+
+			sOut, _, err := Builder().
+				JoinShellCmd(command).
+				WithWorkingDirectory(dir).
+				Finalize().RunAndGet()
+
+			assert.NoError(t, err)
+			assert.Equal(t, expectOut, sOut)
+			assert.Equal(t, tt.expectFile, fileExists(path.Join(dir, "d", "x", "f")) || fileExists(path.Join(dir, "f")))
+		})
+	}
+}
+
+func TestShellCommand_commandSubstitution_inheritsCommandConfiguration(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	toTest := Builder().
+		JoinShellCmd(fmt.Sprintf("echo $(%[1]s -pwd) $(%[1]s -pe | grep CUSTOM_VAR)", testHelper)).
+		WithWorkingDirectory(tmpDir).
+		WithAdditionalEnvironment("CUSTOM_VAR", "value")
+
+	runAndCompare(t, toTest, tmpDir+" CUSTOM_VAR=value\n")
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+func TestShellCommand_commandSubstitution_failing(t *testing.T) {
+	err := Builder().
+		JoinShellCmd(fmt.Sprintf("echo $(cat %s/missing)", t.TempDir())).
+		Finalize().Run()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "[1:6 - 1:")
+	assert.Contains(t, err.Error(), "error running command substitution")
+}
+
+func TestShellCommand_group(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		command    string
+		expectOut  string
+		expectFile string
+	}{
+		{"subshell", "( echo a; echo b ) | wc -l", "2\n", ""},
+		{"brace group", "{ echo a; echo b; } | wc -l", "2\n", ""},
+		{"pipe inside", "( echo hello | tr a-z A-Z ) | grep HELLO", "HELLO\n", ""},
+		{"input", "echo input | ( cat | tr a-z A-Z )", "INPUT\n", ""},
+		{"last", "{ echo a; echo b; }", "a\nb\n", ""},
+		{"nested", "( echo a; { echo b; ( echo c ); } ) | wc -l", "3\n", ""},
+		{"conditional", "( %[1]s -x 1 && echo a || echo b ) | cat", "b\n", ""},
+		{"redirection", "{ echo a; echo b; } > %[2]s/out", "a\nb\n", "a\nb\n"},
+		{"duplicate stderr", "( %[1]s -e ERROR ) 2>&1 | grep ERROR", "ERROR\n", ""},
+		{"variables of subshell", "X=1; ( X=2; echo $X ); echo $X", "2\n1\n", ""},
+		{"variables of brace group", "X=1; { X=2; echo $X; }; echo $X", "2\n2\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command := tt.command
+			if strings.Contains(command, "%[") {
+				command = fmt.Sprintf(command, testHelper, tmpDir)
+			}
+
+			sOut, _, err := Builder().
+				JoinShellCmd(command).
+				Finalize().RunAndGet()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectOut, sOut)
+
+			if tt.expectFile != "" {
+				content, err := os.ReadFile(path.Join(tmpDir, "out"))
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectFile, string(content))
+			}
+		})
+	}
+}
+
+func TestShellCommand_group_failing(t *testing.T) {
+	err := Builder().
+		JoinShellCmd(fmt.Sprintf("( %s -x 1 ) | cat", testHelper)).
+		Finalize().Run()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exit status 1")
+}
+
+func TestShellCommand_processSubstitution(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		command    string
+		expectOut  string
+		expectFile string
+	}{
+		{"input", "cat <(echo a) <(echo b)", "a\nb\n", ""},
+		{"input with pipe", "paste <(printf '1\\n2\\n' | sort -r) <(printf 'a\\nb\\n')", "2\ta\n1\tb\n", ""},
+		{"output", "%[1]s -o OUT | tee >(cat > %[2]s/out)", "OUT\n", "OUT\n"},
+		{"skipped", "false && cat <(echo a) || echo b", "b\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command := tt.command
+			if strings.Contains(command, "%[") {
+				command = fmt.Sprintf(command, testHelper, tmpDir)
+			}
+
+			sOut, _, err := Builder().
+				JoinShellCmd(command).
+				Finalize().RunAndGet()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectOut, sOut)
+
+			if tt.expectFile != "" {
+				content, err := os.ReadFile(path.Join(tmpDir, "out"))
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectFile, string(content))
+			}
+		})
+	}
+}
+
+func TestShellCommand_processSubstitution_failing(t *testing.T) {
+	err := Builder().
+		JoinShellCmd(fmt.Sprintf("cat <(cat %s/missing)", t.TempDir())).
+		Finalize().Run()
+
+	assert.Error(t, err)
+	assert.IsType(t, MultipleErrors{}, err)
+	assert.Contains(t, err.Error(), "process substitution failed")
+}
+
+func TestShellCommand_builtins(t *testing.T) {
+	tmpDir := t.TempDir()
+	assert.NoError(t, os.Mkdir(path.Join(tmpDir, "sub"), 0755))
+	assert.NoError(t, os.WriteFile(path.Join(tmpDir, "sub", "file.txt"), nil, 0644))
+
+	tests := []struct {
+		name      string
+		command   string
+		expectOut string
+	}{
+		{"cd", "cd %[2]s/sub && %[1]s -pwd", "%[1]s/sub\n"},
+		{"cd relative", "cd %[2]s; cd sub; %[1]s -pwd", "%[1]s/sub\n"},
+		{"cd home", "HOME=%[2]s; cd; %[1]s -pwd", "%[1]s\n"},
+		{"cd in subshell", "cd %[2]s; ( cd sub; %[1]s -pwd ); %[1]s -pwd", "%[1]s/sub\n%[1]s\n"},
+		{"cd in group", "cd %[2]s; { cd sub; }; %[1]s -pwd", "%[1]s/sub\n"},
+		{"cd is not executed", "cd %[2]s; true || cd sub; %[1]s -pwd", "%[1]s\n"},
+		{"cd in pipeline", "cd %[2]s; cd sub | true; %[1]s -pwd", "%[1]s\n"},
+		{"cd before process substitution", "cd %[2]s/sub; cat <(ls)", "file.txt\n"},
+		{"export", "export CUSTOM_VAR=value; %[1]s -pe | grep CUSTOM_VAR", "CUSTOM_VAR=value\n"},
+		{"export is not executed", "true || export CUSTOM_VAR=value; %[1]s -pe | grep -c CUSTOM_VAR || true", "0\n"},
+		{"exit", "echo a; exit; echo b", "a\n"},
+		{"exit in group", "{ echo a; exit; }; echo b", "a\n"},
+		{"exit in subshell", "( echo a; exit ); echo b", "a\nb\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, expectOut := tt.command, tt.expectOut
+			if strings.Contains(command, "%[") {
+				command = fmt.Sprintf(command, testHelper, tmpDir)
+			}
+			if strings.Contains(expectOut, "%[") {
+				expectOut = fmt.Sprintf(expectOut, tmpDir)
+			}
+
+			sOut, _, err := Builder().
+				JoinShellCmd(command).
+				Finalize().RunAndGet()
+
+			assert.NoError(t, err)
+			assert.Equal(t, expectOut, sOut)
+		})
+	}
+}
+
+func TestShellCommand_optInBuiltins(t *testing.T) {
+	toTest := Builder().
+		WithBuiltins(map[string]Builtin{"echo": EchoBuiltin, "true": TrueBuiltin, "false": FalseBuiltin}).
+		JoinShellCmd("echo -n hello; echo ' world'; false || true && { echo a; echo b; } | wc -l")
+
+	runAndCompare(t, toTest, "hello world\n2\n")
+}
+
+func TestShellCommand_builtins_failing(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		expectOut   string
+		expectError string
+	}{
+		{"exit", "echo a; exit 3; echo b", "a\n", "exit status 3"},
+		{"exit in group", "{ echo a; exit 3; }; echo b", "a\n", "exit status 3"},
+		{"exit invalid", "exit abc", "", "exit: 'abc': numeric argument required"},
+		{"cd", "cd /missing/dir && echo a", "", "cd: stat /missing/dir: no such file or directory"},
+		{"cd too many arguments", "cd a b", "", "cd: too many arguments"},
+		{"export invalid", `export "1A=2"`, "", "export: '1A=2': not a valid identifier"},
+		{"false", "false", "", "exit status 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sOut, _, err := Builder().
+				WithBuiltins(map[string]Builtin{"false": FalseBuiltin}).
+				JoinShellCmd(tt.command).
+				Finalize().RunAndGet()
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectError)
+			assert.Equal(t, tt.expectOut, sOut)
+		})
+	}
+}
+
+func TestShellCommand_customBuiltin(t *testing.T) {
+	greet := func(_ context.Context, _ *ShellState, command *exec.Cmd) error {
+		_, err := fmt.Fprintf(command.Stdout, "hello %s\n", strings.Join(command.Args[1:], " "))
+		return err
+	}
+
+	toTest := Builder().
+		WithBuiltins(map[string]Builtin{"greet": greet}).
+		JoinShellCmd("greet world | tr a-z A-Z")
+
+	runAndCompare(t, toTest, "HELLO WORLD\n")
+}
+
+func TestShellCommand_customBuiltinState(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	chdir := func(_ context.Context, state *ShellState, command *exec.Cmd) error {
+		value, _ := state.LookupVariable("TARGET")
+		state.WorkingDirectory = value
+		state.Environment = append(state.Environment, "CUSTOM_VAR="+command.Args[1])
+		return nil
+	}
+
+	toTest := Builder().
+		WithVariables(map[string]string{"TARGET": tmpDir}).
+		WithBuiltins(map[string]Builtin{"chdir": chdir}).
+		JoinShellCmd(fmt.Sprintf("chdir value; %[1]s -pwd; %[1]s -pe | grep CUSTOM_VAR", testHelper))
+
+	runAndCompare(t, toTest, tmpDir+"\nCUSTOM_VAR=value\n")
+}
+
+func TestShellCommand_hereDocument(t *testing.T) {
+	toTest := Builder().
+		JoinShellCmd(`grep second <<EOF | wc -l
+first line
+second line
+EOF`)
+
+	runAndCompare(t, toTest, "1\n")
+}
+
+func TestShellCommand_hereString(t *testing.T) {
+	toTest := Builder().
+		JoinShellCmd(`cat <<< 'Hello, World!'`)
+
+	runAndCompare(t, toTest, "Hello, World!\n")
+}
+
+func TestShellCommand_duplicateStream(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		command    string
+		expectOut  string
+		expectErr  string
+		expectFile string
+	}{
+		{"stderr to stdout", "%[1]s -e ERROR -o OUT 2>&1", "ERROR\nOUT\n", "", ""},
+		{"stderr to stdout in pipe", "%[1]s -e ERROR -o OUT 2>&1 | grep ERROR", "ERROR\n", "", ""},
+		{"stdout to stderr", "%[1]s -e ERROR -o OUT >&2", "", "ERROR\nOUT\n", ""},
+		{"stdout to stderr in pipe", "%[1]s -e ERROR -o OUT 1>&2 |& grep OUT", "OUT\n", "", ""},
+		{"file and stderr to stdout", "%[1]s -e ERROR -o OUT > %[2]s/out 2>&1", "ERROR\nOUT\n", "", "ERROR\nOUT\n"},
+		{"stderr to stdout and file", "%[1]s -e ERROR 2>&1 > %[2]s/out", "ERROR\n", "", ""},
+		{"stderr to file and stdout to stderr", "%[1]s -e ERROR -o OUT 2> %[2]s/out >&2", "", "ERROR\nOUT\n", "ERROR\nOUT\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sOut, sErr, err := Builder().
+				JoinShellCmd(fmt.Sprintf(tt.command, testHelper, tmpDir)).
+				Finalize().RunAndGet()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectOut, sOut)
+			assert.Equal(t, tt.expectErr, sErr)
+
+			if strings.Contains(tt.command, "%[2]s") {
+				content, err := os.ReadFile(path.Join(tmpDir, "out"))
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectFile, string(content))
+			}
+		})
+	}
+}
+
+func TestShellCommand_conditional(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		expectOut string
+		expectErr bool
+	}{
+		{"and", "%[1]s -o first && %[1]s -o second", "first\nsecond\n", false},
+		{"and with failure", "%[1]s -o first -x 1 && %[1]s -o second", "first\n", true},
+		{"or", "%[1]s -o first || %[1]s -o second", "first\n", false},
+		{"or with failure", "%[1]s -o first -x 1 || %[1]s -o second", "first\nsecond\n", false},
+		{"or with failures", "%[1]s -x 1 || %[1]s -o second -x 2", "second\n", true},
+		{"and or", "%[1]s -x 1 && %[1]s -o second || %[1]s -o third", "third\n", false},
+		{"skipped pipeline", "%[1]s -x 1 && %[1]s -o second |& grep second | wc -l || %[1]s -o third | grep third", "third\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+
+			err := Builder().
+				JoinShellCmd(fmt.Sprintf(tt.command, testHelper)).
+				Finalize().WithOutput(output).Run()
+
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectOut, output.String())
+		})
+	}
+}
+
+func TestShellCommand_conditionalWithRedirection(t *testing.T) {
+	tmpDir := t.TempDir()
+	outFile := path.Join(tmpDir, "out")
+	assert.NoError(t, os.WriteFile(outFile, []byte("content"), 0644))
+
+	err := Builder().
+		JoinShellCmd(fmt.Sprintf("%[1]s -x 1 && %[1]s -o output > %[2]s", testHelper, outFile)).
+		Finalize().Run()
+
+	assert.Error(t, err)
+
+	content, err := os.ReadFile(outFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "content", string(content), "the file of a skipped pipeline should not be touched")
+}
+
+func TestShellCommand_conditionalWithInjection(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	toTest := Builder().Join(testHelper, "-x", "1").(*chain)
+	toTest.beginSequence(sequenceOnSuccess)
+
+	// the injected content does not fit into the pipe's buffer
+	err := toTest.
+		Join("cat").WithInjections(strings.NewReader(strings.Repeat("a", 1<<20)), strings.NewReader("b")).
+		Finalize().WithOutput(output).Run()
+
+	assert.Error(t, err)
+	assert.Len(t, err.(MultipleErrors).Errors(), 1, "only the errors of the last executed pipeline should be returned")
+	assert.Equal(t, "", output.String())
+}
+
+func TestShellCommand_multipleStatements(t *testing.T) {
+	toTest := Builder().
+		JoinShellCmd(fmt.Sprintf("%[1]s -o first; %[1]s -o second && %[1]s -o third | grep third; %[1]s -o fourth", testHelper))
+
+	runAndCompare(t, toTest, "first\nsecond\nthird\nfourth\n")
+}
+
+func TestShellCommand_multipleStatements_errors(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	err := Builder().
+		JoinShellCmd(fmt.Sprintf("%[1]s -o first -x 1; %[1]s -o second | %[1]s -x 2 || %[1]s -o third; %[1]s -o fourth | grep nothing", testHelper)).
+		Finalize().WithOutput(output).Run()
+
+	assert.Error(t, err)
+	assert.Equal(t, "first\nthird\n", output.String())
+
+	mError := err.(MultipleErrors)
+	assert.Contains(t, mError.Error(), "one or more statements has returned an error")
+	assert.True(t, mError.Is(ErrRun))
+	assert.NotErrorIs(t, err, ErrStream)
+	assert.Len(t, mError.Errors(), 3)
+
+	assert.Error(t, mError.Errors()[0])
+	assert.Len(t, mError.Errors()[0].(MultipleErrors).Errors(), 1)
+	assert.NoError(t, mError.Errors()[1])
+	assert.Error(t, mError.Errors()[2])
+	assert.Len(t, mError.Errors()[2].(MultipleErrors).Errors(), 2)
+	assert.NoError(t, mError.Errors()[2].(MultipleErrors).Errors()[0])
+	assert.Error(t, mError.Errors()[2].(MultipleErrors).Errors()[1])
+}
+
+func TestJoinFunc(t *testing.T) {
+	upper := func(_ context.Context, stdin io.Reader, stdout, _ io.Writer) error {
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(bytes.ToUpper(content))
+		return err
+	}
+
+	tests := []struct {
+		name      string
+		toTest    interface{ Finalize() FinalizedBuilder }
+		expectOut string
+	}{
+		{"first",
+			Builder().WithInput(strings.NewReader("hello world\n")).JoinFunc(upper).Join("wc", "-w"),
+			"2\n",
+		},
+		{"between",
+			Builder().Join("echo", "hello world").JoinFunc(upper).Join("grep", "HELLO"),
+			"HELLO WORLD\n",
+		},
+		{"last",
+			Builder().Join("echo", "hello world").JoinFunc(upper),
+			"HELLO WORLD\n",
+		},
+		{"multiple",
+			Builder().Join("echo", "hello world").JoinFunc(upper).JoinFunc(upper),
+			"HELLO WORLD\n",
+		},
+		{"with injections",
+			Builder().Join("echo", "hello").JoinFunc(upper).WithInjections(strings.NewReader("world\n")).Join("sort"),
+			"HELLO\nWORLD\n",
+		},
+		{"shell command",
+			Builder().JoinShellCmd("echo hello world").JoinFunc(upper).JoinShellCmd("tr ' ' _"),
+			"HELLO_WORLD\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runAndCompare(t, tt.toTest, tt.expectOut)
+		})
+	}
+}
+
+func TestJoinFunc_forks(t *testing.T) {
+	outFork := &bytes.Buffer{}
+	errFork := &bytes.Buffer{}
+
+	toTest := Builder().
+		Join("echo", "hello world").
+		JoinFunc(func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+			_, err := io.Copy(io.MultiWriter(stdout, stderr), stdin)
+			return err
+		}).WithOutputForks(outFork).WithErrorForks(errFork).
+		Join("wc", "-c")
+
+	runAndCompare(t, toTest, "12\n")
+	assert.Equal(t, "hello world\n", outFork.String())
+	assert.Equal(t, "hello world\n", errFork.String())
+}
+
+func TestJoinFunc_failing(t *testing.T) {
+	failure := fmt.Errorf("failure")
+
+	toTest := Builder().
+		Join("echo", "hello world").
+		JoinFunc(func(context.Context, io.Reader, io.Writer, io.Writer) error {
+			return failure
+		}).
+		Finalize()
+
+	err := toTest.Run()
+	assert.ErrorIs(t, err, failure)
+	assert.ErrorIs(t, err, ErrRun)
+	assert.Equal(t, []error{nil, failure}, err.(MultipleErrors).Errors())
+
+	// such as the errors of commands, the function's error can be ignored
+	err = toTest.WithGlobalErrorChecker(func(index int, command *exec.Cmd, err error) bool {
+		return err != failure
+	}).Run()
+	assert.NoError(t, err)
+}
+
+func TestJoinFunc_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	err := Builder().
+		Join("echo", "hello world").
+		JoinFunc(func(ctx context.Context, _ io.Reader, _, _ io.Writer) error {
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		}).
+		Finalize().WithContext(ctx).Run()
+
+	var canceledErr *CanceledError
+	assert.ErrorAs(t, err, &canceledErr)
+}
+
 func runAndCompare(t *testing.T, toTest interface{ Finalize() FinalizedBuilder }, expected string) {
 	output := &bytes.Buffer{}
 
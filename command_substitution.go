@@ -0,0 +1,77 @@
+package cmdchain
+
+import (
+	"fmt"
+	"mvdan.cc/sh/v3/syntax"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// substitutionMarker encloses the placeholders of command substitutions. A real argument or environment variable of a
+// command can never contain it.
+const substitutionMarker = "\x00"
+
+// commandSubstitution executes a nested chain and substitutes its output into the arguments and the environment of
+// a command (e.g. `grep $(cat pattern.txt) data.csv`). Until the command is started, a placeholder is used instead of
+// the output. The nested chain will be executed right before the command is started.
+type commandSubstitution struct {
+	chain *chain
+	node  *syntax.CmdSubst
+	index int
+}
+
+func (c *commandSubstitution) placeholder() string {
+	return fmt.Sprintf("%scmd-subst-%d%s", substitutionMarker, c.index, substitutionMarker)
+}
+
+// execute executes the nested chain. It inherits the working directory and the environment of the given command.
+func (c *commandSubstitution) execute(parent *chain, command *exec.Cmd) (string, error) {
+	c.chain.inherit(command)
+
+	output := strings.Builder{}
+	finalized := c.chain.Finalize().WithOutput(&output)
+	if parent.runCtx != nil {
+		// the nested chain will be canceled together with its parent
+		finalized = finalized.WithContext(parent.runCtx).WithCancelSignal(parent.cancelSignal, parent.cancelGracePeriod)
+	}
+
+	err := parent.running.runNested(finalized)
+	if err != nil {
+		return "", errorWithPos(c.node, "error running command substitution", err)
+	}
+
+	return strings.TrimRight(output.String(), "\n"), nil
+}
+
+// substituteCommands returns a preparer which executes the given command substitutions and replaces their
+// placeholders inside the command's arguments and environment.
+func substituteCommands(parent *chain, substitutions []*commandSubstitution) func(*exec.Cmd) error {
+	return func(command *exec.Cmd) error {
+		// the nested chains must not inherit the placeholders (e.g. of the command's assignments)
+		origin := &exec.Cmd{
+			Dir: command.Dir,
+			Env: slices.DeleteFunc(slices.Clone(command.Env), func(entry string) bool {
+				return strings.Contains(entry, substitutionMarker)
+			}),
+		}
+
+		replacements := make([]string, 0, len(substitutions)*2)
+		for _, substitution := range substitutions {
+			output, err := substitution.execute(parent, origin)
+			if err != nil {
+				return err
+			}
+			replacements = append(replacements, substitution.placeholder(), output)
+		}
+
+		replacer := strings.NewReplacer(replacements...)
+		for i := range command.Args {
+			command.Args[i] = replacer.Replace(command.Args[i])
+		}
+		for i := range command.Env {
+			command.Env[i] = replacer.Replace(command.Env[i])
+		}
+		return nil
+	}
+}
@@ -1,15 +1,24 @@
 package cmdchain
 
-import "os/exec"
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"syscall"
+)
 
 // ErrorChecker is a function which will receive the command's error. His purposes is to check if the given error can
 // be ignored. If the function return true the given error is a "real" error and will NOT be ignored!
 type ErrorChecker func(index int, command *exec.Cmd, err error) bool
 
-// IgnoreExitCode will return an ErrorChecker. This will ignore all exec.ExitError which have any of the given exit codes.
+// IgnoreExitCode will return an ErrorChecker. This will ignore all exec.ExitError (and BuiltinExitError) which have
+// any of the given exit codes.
 func IgnoreExitCode(allowedCodes ...int) ErrorChecker {
 	return func(_ int, _ *exec.Cmd, err error) bool {
-		if exitErr, ok := err.(*exec.ExitError); ok {
+		if exitErr, ok := err.(exitCoder); ok && isExitError(err) {
 			exitCode := exitErr.ExitCode()
 
 			for _, allowedCode := range allowedCodes {
@@ -24,12 +33,143 @@ func IgnoreExitCode(allowedCodes ...int) ErrorChecker {
 	}
 }
 
-// IgnoreExitErrors will return an ErrorChecker. This will ignore all exec.ExitError.
+// IgnoreExitErrors will return an ErrorChecker. This will ignore all exec.ExitError (and BuiltinExitError).
 func IgnoreExitErrors() ErrorChecker {
 	return func(_ int, _ *exec.Cmd, err error) bool {
-		_, isExitError := err.(*exec.ExitError)
+		return !isExitError(err)
+	}
+}
+
+type exitCoder interface {
+	ExitCode() int
+}
+
+func isExitError(err error) bool {
+	switch err.(type) {
+	case *exec.ExitError, *BuiltinExitError:
+		return true
+	default:
+		return false
+	}
+}
+
+// terminatingSignal returns the signal which has terminated the command of the given error. If the command was not
+// terminated by a signal, nil will be returned.
+func terminatingSignal(err error) os.Signal {
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return nil
+	}
+
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return nil
+	}
+	return status.Signal()
+}
+
+// isBrokenPipe checks if the given error is caused by the signal SIGPIPE. The command has tried to write into a pipe
+// which was closed by the reading side. Commands which are executed inside the current process (see
+// ChainBuilder.JoinFunc) will get an error instead of the signal.
+func isBrokenPipe(err error) bool {
+	return terminatingSignal(err) == syscall.SIGPIPE || errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrClosedPipe)
+}
+
+// IgnoreSignals will return an ErrorChecker. This will ignore all exec.ExitError of commands which were terminated
+// by any of the given signals (e.g. syscall.SIGPIPE).
+func IgnoreSignals(signals ...os.Signal) ErrorChecker {
+	return func(_ int, _ *exec.Cmd, err error) bool {
+		sig := terminatingSignal(err)
+		if sig == nil {
+			return true
+		}
+
+		for _, allowedSignal := range signals {
+			if allowedSignal == sig {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// IgnoreStdoutMatching will return an ErrorChecker. This will ignore all exec.ExitError (and BuiltinExitError) of
+// commands whose captured stdout matches the given pattern. The stdout is only captured if it is enabled (see
+// FinalizedBuilder.WithStdoutTail). Otherwise, no error will be ignored.
+//
+// Attention: the stdout of a command which is piped into the next command is never captured! So the errors of all
+// commands except the last one of a pipeline (e.g. "cmd1" of "cmd1 | cmd2") will never be ignored by this checker.
+func IgnoreStdoutMatching(pattern *regexp.Regexp) ErrorChecker {
+	return func(_ int, command *exec.Cmd, err error) bool {
+		return !isExitError(err) || !outputMatches(command.Stdout, pattern)
+	}
+}
+
+// IgnoreStderrMatching will return an ErrorChecker. This will ignore all exec.ExitError (and BuiltinExitError) of
+// commands whose captured stderr matches the given pattern. The stderr is only captured if it is enabled (see
+// FinalizedBuilder.WithStderrTail). Otherwise, no error will be ignored.
+//
+// Attention: the stderr of a command which is piped into the next command (see CommandBuilder.ForwardError) is never
+// captured! So the errors of such commands will never be ignored by this checker.
+func IgnoreStderrMatching(pattern *regexp.Regexp) ErrorChecker {
+	return func(_ int, command *exec.Cmd, err error) bool {
+		return !isExitError(err) || !outputMatches(command.Stderr, pattern)
+	}
+}
+
+func outputMatches(stream io.Writer, pattern *regexp.Regexp) bool {
+	output, captured := capturedOutput(stream)
+	return captured && pattern.Match(output)
+}
+
+// ByCommandName will return an ErrorChecker. This will delegate the check to the ErrorChecker of the command's name
+// (e.g. "grep" for "/usr/bin/grep"). The errors of all other commands will not be ignored.
+func ByCommandName(checkers map[string]ErrorChecker) ErrorChecker {
+	return func(index int, command *exec.Cmd, err error) bool {
+		checker, found := checkers[filepath.Base(command.Path)]
+		if !found && len(command.Args) > 0 {
+			checker, found = checkers[command.Args[0]]
+		}
+		if !found {
+			return true
+		}
+
+		return checker(index, command, err)
+	}
+}
+
+// And will return an ErrorChecker. The error will only be ignored if all given ErrorChecker would ignore it. For
+// example And(IgnoreExitCode(1), ByCommandName(map[string]ErrorChecker{"grep": IgnoreAll()})) will only ignore the
+// exit code 1 of grep.
+func And(checkers ...ErrorChecker) ErrorChecker {
+	return func(index int, command *exec.Cmd, err error) bool {
+		for _, checker := range checkers {
+			if checker(index, command, err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Or will return an ErrorChecker. The error will be ignored if any of the given ErrorChecker would ignore it.
+func Or(checkers ...ErrorChecker) ErrorChecker {
+	return func(index int, command *exec.Cmd, err error) bool {
+		for _, checker := range checkers {
+			if !checker(index, command, err) {
+				return false
+			}
+		}
+		return true
+	}
+}
 
-		return !isExitError
+// Not will return an ErrorChecker. This will ignore all exec.ExitError (and BuiltinExitError) which would NOT be
+// ignored by the given ErrorChecker. For example Not(IgnoreExitCode(2)) will ignore all exit codes except 2. All
+// other errors (e.g. TimeoutError) are "real" errors in any case.
+func Not(checker ErrorChecker) ErrorChecker {
+	return func(index int, command *exec.Cmd, err error) bool {
+		return !isExitError(err) || !checker(index, command, err)
 	}
 }
 
@@ -1,9 +1,12 @@
 package cmdchain
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"os/exec"
+	"regexp"
+	"syscall"
 	"testing"
 )
 
@@ -12,12 +15,15 @@ func TestIgnoreExitCode(t *testing.T) {
 
 	assert.False(t, IgnoreExitCode(13)(0, nil, err))
 	assert.True(t, IgnoreExitCode(1)(0, nil, err))
+	assert.False(t, IgnoreExitCode(13)(0, nil, &BuiltinExitError{Code: 13}))
+	assert.True(t, IgnoreExitCode(1)(0, nil, &BuiltinExitError{Code: 13}))
 }
 
 func TestIgnoreExitErrors(t *testing.T) {
 	err := exec.Command(testHelper, "-x", "13").Run()
 
 	assert.False(t, IgnoreExitErrors()(0, nil, err))
+	assert.False(t, IgnoreExitErrors()(0, nil, &BuiltinExitError{Code: 13}))
 	assert.True(t, IgnoreExitErrors()(0, nil, fmt.Errorf("someOtherError")))
 }
 
@@ -34,3 +40,102 @@ func TestIgnoreNothing(t *testing.T) {
 	assert.True(t, IgnoreNothing()(0, nil, err))
 	assert.True(t, IgnoreNothing()(0, nil, fmt.Errorf("someOtherError")))
 }
+
+func TestIgnoreSignals(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	assert.NoError(t, cmd.Start())
+	assert.NoError(t, cmd.Process.Signal(syscall.SIGTERM))
+	err := cmd.Wait()
+
+	assert.False(t, IgnoreSignals(syscall.SIGTERM)(0, nil, err))
+	assert.False(t, IgnoreSignals(syscall.SIGPIPE, syscall.SIGTERM)(0, nil, err))
+	assert.True(t, IgnoreSignals(syscall.SIGPIPE)(0, nil, err))
+	assert.True(t, IgnoreSignals(syscall.SIGTERM)(0, nil, exec.Command(testHelper, "-x", "13").Run()))
+	assert.True(t, IgnoreSignals(syscall.SIGTERM)(0, nil, fmt.Errorf("someOtherError")))
+}
+
+func TestIgnoreStdoutMatching(t *testing.T) {
+	err := exec.Command(testHelper, "-x", "13").Run()
+	pattern := regexp.MustCompile(`^OUT`)
+
+	captured := &tailWriter{size: 10}
+	_, _ = captured.Write([]byte("OUTPUT"))
+
+	assert.False(t, IgnoreStdoutMatching(pattern)(0, &exec.Cmd{Stdout: captured}, err))
+	assert.False(t, IgnoreStdoutMatching(pattern)(0, &exec.Cmd{Stdout: captured}, &BuiltinExitError{Code: 13}))
+	assert.True(t, IgnoreStdoutMatching(pattern)(0, &exec.Cmd{Stdout: captured}, fmt.Errorf("someOtherError")))
+	assert.True(t, IgnoreStdoutMatching(pattern)(0, &exec.Cmd{Stderr: captured}, err))
+	assert.True(t, IgnoreStdoutMatching(regexp.MustCompile(`ERR`))(0, &exec.Cmd{Stdout: captured}, err))
+	assert.True(t, IgnoreStdoutMatching(pattern)(0, &exec.Cmd{Stdout: &bytes.Buffer{}}, err), "not captured")
+}
+
+func TestIgnoreStderrMatching(t *testing.T) {
+	err := exec.Command(testHelper, "-x", "13").Run()
+	pattern := regexp.MustCompile(`^ERR`)
+
+	captured := &tailWriter{size: 10}
+	_, _ = captured.Write([]byte("ERROR"))
+
+	assert.False(t, IgnoreStderrMatching(pattern)(0, &exec.Cmd{Stderr: captured}, err))
+	assert.False(t, IgnoreStderrMatching(pattern)(0, &exec.Cmd{Stderr: captured}, &BuiltinExitError{Code: 13}))
+	assert.True(t, IgnoreStderrMatching(pattern)(0, &exec.Cmd{Stderr: captured}, fmt.Errorf("someOtherError")))
+	assert.True(t, IgnoreStderrMatching(pattern)(0, &exec.Cmd{Stdout: captured}, err))
+	assert.True(t, IgnoreStderrMatching(regexp.MustCompile(`OUT`))(0, &exec.Cmd{Stderr: captured}, err))
+	assert.True(t, IgnoreStderrMatching(pattern)(0, &exec.Cmd{Stderr: &bytes.Buffer{}}, err), "not captured")
+}
+
+func TestByCommandName(t *testing.T) {
+	toTest := ByCommandName(map[string]ErrorChecker{
+		"grep": IgnoreExitCode(1),
+		"echo": IgnoreAll(),
+	})
+	err := &BuiltinExitError{Code: 1}
+
+	assert.False(t, toTest(0, exec.Command("grep", "test"), err))
+	assert.True(t, toTest(0, exec.Command("grep", "test"), &BuiltinExitError{Code: 2}))
+	assert.False(t, toTest(0, &exec.Cmd{Path: "echo", Args: []string{"echo"}}, err))
+	assert.True(t, toTest(0, exec.Command(testHelper), err))
+}
+
+func TestCombinators(t *testing.T) {
+	ignore := IgnoreAll()
+	keep := IgnoreNothing()
+	exitErr := &BuiltinExitError{Code: 2}
+	otherErr := fmt.Errorf("someOtherError")
+
+	tests := []struct {
+		name   string
+		toTest ErrorChecker
+		err    error
+		expect bool
+	}{
+		{"and - all true", And(keep, keep), otherErr, true},
+		{"and - one false", And(keep, ignore), otherErr, true},
+		{"and - all false", And(ignore, ignore), otherErr, false},
+		{"and - empty", And(), otherErr, false},
+		{"or - all true", Or(keep, keep), otherErr, true},
+		{"or - one true", Or(ignore, keep), otherErr, false},
+		{"or - all false", Or(ignore, ignore), otherErr, false},
+		{"or - empty", Or(), otherErr, true},
+		{"not - true", Not(keep), exitErr, false},
+		{"not - false", Not(ignore), exitErr, true},
+		{"not - no exit error", Not(keep), otherErr, true},
+		{"not - other exit code", Not(IgnoreExitCode(2)), &BuiltinExitError{Code: 1}, false},
+		{"not - same exit code", Not(IgnoreExitCode(2)), exitErr, true},
+		{"nested", Not(Or(And(keep, ignore), ignore)), exitErr, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.toTest(0, nil, tt.err))
+		})
+	}
+}
+
+func TestCombinators_byCommandName(t *testing.T) {
+	toTest := And(IgnoreExitCode(1), ByCommandName(map[string]ErrorChecker{"grep": IgnoreAll()}))
+
+	assert.False(t, toTest(0, exec.Command("grep", "test"), &BuiltinExitError{Code: 1}))
+	assert.True(t, toTest(0, exec.Command("grep", "test"), &BuiltinExitError{Code: 2}))
+	assert.True(t, toTest(0, exec.Command(testHelper), &BuiltinExitError{Code: 1}))
+}
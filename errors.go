@@ -1,8 +1,29 @@
 package cmdchain
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"time"
+)
+
+var (
+	// ErrBuild is the category of the errors which occurred while building the chain (see FinalizedBuilder.Run).
+	// It can be checked by errors.Is.
+	ErrBuild = errors.New("chain build failed")
+
+	// ErrStart is the category of the errors which occurred while starting a command (see FinalizedBuilder.Run).
+	// It can be checked by errors.Is.
+	ErrStart = errors.New("command start failed")
+
+	// ErrRun is the category of the errors which were returned by the executed commands or statements (see
+	// FinalizedBuilder.Run). A CanceledError belongs to this category too. It can be checked by errors.Is.
+	ErrRun = errors.New("command run failed")
+
+	// ErrStream is the category of the errors which occurred while copying the streams of the commands (see
+	// FinalizedBuilder.Run). It can be checked by errors.Is.
+	ErrStream = errors.New("command stream copy failed")
 )
 
 // MultipleErrors fusions multiple errors into one error. All underlying errors can be accessed.
@@ -12,6 +33,9 @@ type MultipleErrors struct {
 	errorMessage string
 	errors       []error
 	hasError     bool
+
+	// category is the sentinel error of the kind of errors (e.g. ErrRun)
+	category error
 }
 
 // Errors returns the underlying errors.
@@ -40,11 +64,31 @@ func (e MultipleErrors) Error() string {
 	return sb.String()
 }
 
+// Unwrap returns the underlying errors. So errors.Is and errors.As will also check the errors of all commands
+// (including nested MultipleErrors).
+func (e MultipleErrors) Unwrap() []error {
+	return e.errors
+}
+
+// Is checks if the given target is the category of these errors (ErrBuild, ErrRun or ErrStream). Errors of different
+// categories can be nested (e.g. if run and stream errors occurred), so errors.Is will find all of them.
+func (e MultipleErrors) Is(target error) bool {
+	return e.category != nil && e.category == target
+}
+
+// orNil returns nil if none of the underlying errors is set. Otherwise the MultipleErrors itself will be returned.
+func (e MultipleErrors) orNil() error {
+	if !e.hasError {
+		return nil
+	}
+	return e
+}
+
 func (e *MultipleErrors) addError(err error) {
 	e.errors = append(e.errors, err)
 	if err != nil {
 		if mError, ok := err.(MultipleErrors); ok {
-			e.hasError = mError.hasError
+			e.hasError = e.hasError || mError.hasError
 		} else {
 			e.hasError = true
 		}
@@ -55,7 +99,7 @@ func (e *MultipleErrors) setError(i int, err error) {
 	e.errors[i] = err
 	if err != nil {
 		if mError, ok := err.(MultipleErrors); ok {
-			e.hasError = mError.hasError
+			e.hasError = e.hasError || mError.hasError
 		} else {
 			e.hasError = true
 		}
@@ -65,17 +109,145 @@ func (e *MultipleErrors) setError(i int, err error) {
 func runErrors() MultipleErrors {
 	return MultipleErrors{
 		errorMessage: "one or more command has returned an error",
+		category:     ErrRun,
+	}
+}
+
+func statementErrors() MultipleErrors {
+	return MultipleErrors{
+		errorMessage: "one or more statements has returned an error",
+		category:     ErrRun,
 	}
 }
 
 func buildErrors() MultipleErrors {
 	return MultipleErrors{
 		errorMessage: "one or more chain build errors occurred",
+		category:     ErrBuild,
 	}
 }
 
 func streamErrors() MultipleErrors {
 	return MultipleErrors{
 		errorMessage: "one or more command stream copies failed",
+		category:     ErrStream,
+	}
+}
+
+// startError is the error of a command which could not be started.
+type startError struct {
+	err error
+}
+
+func (e *startError) Error() string {
+	return fmt.Sprintf("failed to start command: %s", e.err)
+}
+
+// Unwrap returns the original error of the command's start.
+func (e *startError) Unwrap() error {
+	return e.err
+}
+
+// Is checks if the given target is the category of start errors (ErrStart).
+func (e *startError) Is(target error) bool {
+	return target == ErrStart
+}
+
+// CanceledError is the error of a command which was stopped (or not started) because the context of the chain was
+// done (see FinalizedBuilder.WithContext).
+type CanceledError struct {
+	// Err is the original error of the command. It is nil if the command was not started or exited successfully
+	// after it was signaled.
+	Err error
+
+	// Cause is the cause of the context's cancellation (see context.Cause).
+	Cause error
+}
+
+func (e *CanceledError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("canceled: %s", e.Cause)
+	}
+	return fmt.Sprintf("canceled: %s: %s", e.Cause, e.Err)
+}
+
+// Unwrap returns the original error of the command and the cause of the cancellation. So errors.Is(err,
+// context.Canceled) will work as expected.
+func (e *CanceledError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Cause}
 	}
+	return []error{e.Err, e.Cause}
+}
+
+// Is checks if the given target is the category of canceled commands (ErrRun).
+func (e *CanceledError) Is(target error) bool {
+	return target == ErrRun
+}
+
+// TimeoutError is the error of a command which was terminated because its timeout (see CommandBuilder.WithTimeout)
+// or the timeout of the chain (see FinalizedBuilder.WithTimeout) has expired.
+type TimeoutError struct {
+	// Index is the index of the command inside the chain.
+	Index int
+
+	// Command is the string representation of the command.
+	Command string
+
+	// Timeout is the expired timeout.
+	Timeout time.Duration
+
+	// Err is the original error of the command. It is nil if the command was not started or exited successfully
+	// after it was terminated.
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	message := fmt.Sprintf("command %d (%s) timed out after %s", e.Index, e.Command, e.Timeout)
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+// Unwrap returns the original error of the command.
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// CommandError is the error of a command which has exited with a non-zero exit code (or was terminated by a signal).
+// It wraps the original error of the command (such as *exec.ExitError or *BuiltinExitError). Its message is the one
+// of the original error. Callers which have asserted the errors of a MultipleErrors to *exec.ExitError must use
+// errors.As instead:
+//
+//	var exitErr *exec.ExitError
+//	if errors.As(mErr.Errors()[0], &exitErr) { ... }
+type CommandError struct {
+	// Index is the index of the command inside the chain.
+	Index int
+
+	// Command is the string representation of the command.
+	Command string
+
+	// ExitCode is the exit code of the command. It is -1 if the command was terminated by a signal.
+	ExitCode int
+
+	// Signal is the signal which has terminated the command. It is nil if the command was not terminated by a signal.
+	Signal os.Signal
+
+	// Stderr contains the last bytes which were written into the command's stderr. It is only captured if it is
+	// enabled (see FinalizedBuilder.WithStderrTail).
+	Stderr []byte
+
+	// Err is the original error of the command.
+	Err error
+}
+
+func (e *CommandError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the original error of the command.
+func (e *CommandError) Unwrap() error {
+	return e.Err
 }
@@ -0,0 +1,21 @@
+package cmdchain
+
+import "strings"
+
+// hereDocument is the content of a here-document (<<) or here-string (<<<) which should be used
+// as command's input.
+type hereDocument struct {
+	*strings.Reader
+	kind string
+}
+
+func newHereDocument(content, kind string) *hereDocument {
+	return &hereDocument{
+		Reader: strings.NewReader(content),
+		kind:   kind,
+	}
+}
+
+func (h *hereDocument) String() string {
+	return h.kind
+}
@@ -5,13 +5,25 @@ type hook interface {
 	AfterRun()
 }
 
+// commandHook is a hook of a command. Its BeforeRun will only be executed if the pipeline of the command is started
+// (e.g. a skipped pipeline of `a && b > file` must not truncate the file).
+type commandHook struct {
+	hook
+
+	cmdIndex int
+}
+
+// addHook adds the given hook for the previously joined command.
 func (c *chain) addHook(h hook) {
-	c.hooks = append(c.hooks, h)
+	c.hooks = append(c.hooks, commandHook{hook: h, cmdIndex: len(c.cmdDescriptors) - 1})
 }
 
-func (c *chain) executeBeforeRunHooks() {
+// executeBeforeRunHooks executes the hooks of the commands of the given pipeline.
+func (c *chain) executeBeforeRunHooks(p pipeline) {
 	for _, h := range c.hooks {
-		h.BeforeRun()
+		if h.cmdIndex >= p.from && h.cmdIndex < p.to {
+			h.BeforeRun()
+		}
 	}
 }
 
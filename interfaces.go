@@ -1,9 +1,13 @@
 package cmdchain
 
 import (
+	"bufio"
 	"context"
 	"io"
+	"os"
 	"os/exec"
+	"regexp"
+	"time"
 )
 
 // ChainBuilder contains methods for joining new commands to the current cain or finalize them.
@@ -25,10 +29,46 @@ type ChainBuilder interface {
 	JoinCmd(cmd *exec.Cmd) CommandBuilder
 
 	// JoinWithContext is like Join but includes a context to the created command. The provided context is used
-	// to kill the process (by calling os.Process.Kill) if the context becomes done before the command completes
-	// on its own.
+	// to terminate the process if the context becomes done before the command completes on its own. Such as the
+	// chain would be canceled, the cancel signal (see FinalizedBuilder.WithCancelSignal) will be sent at first. If
+	// the process is still running after the grace period, it will be killed (by calling os.Process.Kill).
 	JoinWithContext(ctx context.Context, name string, args ...string) CommandBuilder
 
+	// JoinFunc joins the given function as command to the chain. The function will be executed inside the current
+	// process instead of starting a command. Beside that, it takes part in the chain like any other command: its
+	// streams are linked with the previous and the next command and can be configured by the CommandBuilder (e.g.
+	// forks and injections). The returned error will be handled like the error of a command (see
+	// FinalizedBuilder.Run and ErrorChecker). If the chain is canceled, the function's context will be canceled.
+	// The function is named "func" (e.g. in the string representation of the chain or for ByCommandName).
+	JoinFunc(fn CommandFunc) CommandBuilder
+
+	// JoinLineMapper joins a command (see JoinFunc) which writes each line of its input mapped by the given mapper.
+	// Such as all line oriented commands, the lines are processed one after another (a single line must not be
+	// longer than 1 MiB) and each written line will be terminated by a newline. The command is named "map".
+	JoinLineMapper(mapper func(line string) string) CommandBuilder
+
+	// JoinLineFilter joins a command (see JoinLineMapper) which writes only the lines of its input which are accepted
+	// by the given filter. The command is named "filter".
+	JoinLineFilter(filter func(line string) bool) CommandBuilder
+
+	// JoinRegexFilter joins a command (see JoinLineMapper) which writes only the lines of its input which match the
+	// given pattern (such as grep). The command is named "grep".
+	JoinRegexFilter(pattern *regexp.Regexp) CommandBuilder
+
+	// JoinHead joins a command (see JoinLineMapper) which writes the first n lines of its input (such as head). After
+	// that, the command will exit without reading the rest of its input. So the previous command will not be able to
+	// write its remaining output. Such as `... | head`, this is not handled as error of the previous command. The
+	// command is named "head".
+	JoinHead(n int) CommandBuilder
+
+	// JoinTail joins a command (see JoinLineMapper) which writes the last n lines of its input (such as tail). Only
+	// these lines are held in memory. The command is named "tail".
+	JoinTail(n int) CommandBuilder
+
+	// JoinUniq joins a command (see JoinLineMapper) which writes the lines of its input without adjacent duplicates
+	// (such as uniq). The command is named "uniq".
+	JoinUniq() CommandBuilder
+
 	// JoinShellCmd will take a shell command line, parse it into single commands and join them to this chain.
 	// So this is not a single command, which will be interpreted by any shell!
 	// If there is a command, which joined before, their stdout/stderr will redirected to the first
@@ -39,12 +79,52 @@ type ChainBuilder interface {
 	// 	- Piping all output (stdout and stderr) to the next command's stdin (|&)
 	// 	- Redirection of stdout (>) and stderr (2>) to files
 	// 	- Redirection of stdout (>>) and stderr (2>>) to files (appending)
+	// 	- Redirection of stdin from files (<), here-documents (<< and <<-) and here-strings (<<<). Such as in a
+	// 	  shell, the input replaces the command's stdin (and the output of a previous command in the pipe). If
+	// 	  there are multiple input redirections, the last one wins.
+	// 	- Duplication of stderr to stdout (2>&1) and of stdout to stderr (>&2 or 1>&2). The duplicated stream will be
+	// 	  written where the other stream is written at this moment (e.g. `command > file 2>&1`).
 	// 	- Environment variables (e.g. `VAR=value command`)
+	// 	- Variable expansion: $VAR, ${VAR}, ${VAR:-default}, ${VAR:=default}, ${VAR:+alternate}, ${VAR:?message},
+	// 	  ${#VAR} and the removal of suffixes (${VAR%suffix}, ${VAR%%suffix}) and prefixes (${VAR#prefix},
+	// 	  ${VAR##prefix}). The variables are looked up in the shell variables (assignments without command,
+	// 	  e.g. `VAR=value; command $VAR`, and the preceding assignments of the same command, e.g.
+	// 	  `A=1 B=$A command`), the variables configured by FirstCommandBuilder.WithVariables and finally in the
+	// 	  environment of the current process. The expanded values will NOT be split into
+	// 	  multiple arguments.
+	// 	- Subshells (e.g. `( command1; command2 ) | command3`) and brace groups (e.g. `{ command1; command2; } | command3`).
+	// 	  A group acts as a single command of the chain: its input is passed to the first command of the group and
+	// 	  the outputs of all commands of the group are written into the group's output. Redirections are applied to
+	// 	  the group as a whole. Variables which are assigned inside a subshell are not visible outside.
+	// 	- Process substitution in arguments (e.g. `diff <(sort a) <(sort b)` or `tee >(grep error > errors.log)`). The
+	// 	  inner command line will be executed as its own chain right before the command is started. It is connected
+	// 	  with the command through a pipe which is passed as /dev/fd/N. Errors of the inner chain are reported as
+	// 	  stream errors of the command.
+	// 	- Builtins (see FirstCommandBuilder.WithBuiltins). By default, the following builtins are available:
+	// 	  cd (changes the working directory of the following commands), export (passes variables to the environment
+	// 	  of the following commands) and exit (stops the execution of the following statements). Such as in the
+	// 	  shell, they only affect the following statements if they are executed (e.g. not `false && cd dir`) and if
+	// 	  they are the only command of their pipeline. Variables which are declared by export are visible for the
+	// 	  variable expansion of the following statements, because the expansion happens while the command line is
+	// 	  parsed.
+	// 	- Pathname and tilde expansion of arguments (opt-in, see FirstCommandBuilder.WithGlobExpansion)
+	// 	- Command substitution in arguments and assignments of commands (e.g. `grep $(cat pattern.txt) data.csv`).
+	// 	  The inner command line will be executed as its own chain right before the command is started. It inherits
+	// 	  the working directory and the environment of the command. Its stdout (without trailing newlines) will be
+	// 	  substituted, but not expanded (e.g. by the pathname expansion). If the inner chain fails, the command will
+	// 	  not be started and the error will be reported as run error of this chain. A command substitution is not
+	// 	  supported in assignments without command (e.g. `A=$(date)`), because the variables are expanded while the
+	// 	  command line is parsed. For the same reason, variables which are declared by a command substitution (e.g.
+	// 	  `export A=$(date)`) are not visible for the variable expansion of the following statements.
+	// 	- Conditional execution (e.g. `command1 && command2` or `command1 || command2`)
+	// 	- Multiple statements (e.g. `command1; command2`)
 	//
 	// Unsupported features:
 	// 	- Background execution (&)
-	// 	- Conditional execution (e.g. `command1 && command2` or `command1 || command2`)
 	//
+	// Conditional execution and multiple statements will split the chain into multiple pipelines. Each pipeline
+	// will be executed after its predecessor is done. Whether a conditional pipeline is executed depends on the
+	// result of the previous pipeline (after applying the configured ErrorChecker).
 	// Example:
 	// 	JoinShellCmd("echo Hello, World! | grep Hello | wc -c")
 	// will create a chain with three commands:
@@ -70,11 +150,37 @@ type FirstCommandBuilder interface {
 	// configured, this streams will read in parallel (not sequential!). So be aware of concurrency issues.
 	// If this behavior is not wanted, me the io.MultiReader is a better choice.
 	WithInput(sources ...io.Reader) ChainBuilder
+
+	// WithVariables configures variables which are used to expand the variables of shell commands (see
+	// ChainBuilder.JoinShellCmd). These variables take precedence over the environment variables of the current
+	// process. They will NOT be passed to the environment of the commands. The variables assigned by the shell
+	// command itself take precedence over them: the assignments without command (e.g. `A=1; command $A`) are
+	// visible for the following statements. The assignments of a command (e.g. `A=1 B=$A command`) are only
+	// visible for the following assignments of the same command, not for its arguments (such as in a shell).
+	WithVariables(variables map[string]string) FirstCommandBuilder
+
+	// WithGlobExpansion enables the pathname expansion (*, ?, [...] and **) and the tilde expansion (~ and ~user)
+	// of the arguments of shell commands (see ChainBuilder.JoinShellCmd). The patterns are resolved relative to
+	// the working directory of the command (see CommandBuilder.WithWorkingDirectory). The given policy decides
+	// how patterns which do not match any file are handled. By default, the expansion is disabled.
+	WithGlobExpansion(policy GlobPolicy) FirstCommandBuilder
+
+	// WithBuiltins registers builtins for shell commands (see ChainBuilder.JoinShellCmd). A builtin is implemented
+	// in Go and will be executed inside the current process instead of starting a command with the same name. The
+	// given builtins take precedence over the default builtins (cd, export and exit). A nil builtin disables the
+	// default builtin with the same name. Further builtins are available for registration: EchoBuiltin, TrueBuiltin
+	// and FalseBuiltin.
+	WithBuiltins(builtins map[string]Builtin) FirstCommandBuilder
 }
 
 // CommandApplier is a function which will get the command's index and the command's reference
 type CommandApplier func(index int, command *exec.Cmd)
 
+// CommandFunc is a command which is implemented in Go (see ChainBuilder.JoinFunc). It reads its input from stdin and
+// writes its output into stdout and stderr. The streams are never nil. The function should return as soon as the
+// given context is done.
+type CommandFunc func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error
+
 // CommandBuilder contains methods for configuring the previous joined command.
 type CommandBuilder interface {
 	ChainBuilder
@@ -179,8 +285,26 @@ type CommandBuilder interface {
 	// command) to use the given error checker. In some cases the command(s) will return a non-zero exit code, which will
 	// normally cause an error at the FinalizedBuilder.Run(). To avoid that you can use a ErrorChecker to ignore these
 	// kind of errors. There exists a set of functions which create a such ErrorChecker: IgnoreExitCode, IgnoreExitErrors,
-	// IgnoreAll, IgnoreNothing
+	// IgnoreSignals, IgnoreStdoutMatching, IgnoreStderrMatching, ByCommandName, IgnoreAll, IgnoreNothing. They can be
+	// combined by And, Or and Not.
 	WithErrorChecker(ErrorChecker) CommandBuilder
+
+	// WithTimeout will configure the previously joined command (or ALL commands out of the previously joined shell
+	// command) to be terminated if it is running longer than the given timeout. The command will be terminated such
+	// as the chain would be canceled (see FinalizedBuilder.WithCancelSignal). In that case the command's error will
+	// be a TimeoutError.
+	WithTimeout(timeout time.Duration) CommandBuilder
+
+	// WithRetry will configure the previously joined command (or ALL commands out of the previously joined shell
+	// command) to be started again if it fails (see RetryPolicy). The timeout of the command (see WithTimeout) applies
+	// to all attempts. A command which was signaled (e.g. because the chain was canceled) will not be retried. Only
+	// commands which are executed as their own process and whose input does not come from a previous command (e.g.
+	// the first command or a command whose input comes only from WithInput or WithInjections) can be retried. For all
+	// other commands (e.g. builtins or the second command of a shell pipeline) a build error will be returned by Run.
+	// The input of such a command will be buffered, so it can be replayed for each attempt. The output of all
+	// attempts will be written into the command's streams. The count of attempts can be found in the RunReport (see
+	// FinalizedBuilder.RunWithReport).
+	WithRetry(policy RetryPolicy) CommandBuilder
 }
 
 // FinalizedBuilder contains methods for configuration the the finalized chain. At this step the chain can be running.
@@ -189,7 +313,8 @@ type FinalizedBuilder interface {
 	// WithOutput configures the stdout stream(s) for the last command in the chain. If there is more than one target
 	// given io.MultiWriter will be used as command's stdout. So in that case if there was one of the given targets
 	// closed before the chain normally ends, the chain will be exited. This is because of the behavior of the
-	// io.MultiWriter.
+	// io.MultiWriter. If the chain consists of multiple pipelines (see JoinShellCmd) the last command of each
+	// pipeline will be configured.
 	WithOutput(targets ...io.Writer) FinalizedBuilder
 
 	// WithAdditionalOutput is similar to WithOutput except that the given targets will be added to the
@@ -199,18 +324,55 @@ type FinalizedBuilder interface {
 	// WithError configures the stderr stream(s) for the last command in the chain. If there is more than one target
 	// given io.MultiWriter will be used as command's stdout. So in that case if there was one of the given targets
 	// closed before the chain normally ends, the chain will be exited. This is because of the behavior of the
-	// io.MultiWriter.
+	// io.MultiWriter. If the chain consists of multiple pipelines (see JoinShellCmd) the last command of each
+	// pipeline will be configured.
 	WithError(targets ...io.Writer) FinalizedBuilder
 
 	// WithAdditionalError is similar to WithError except that the given targets will be added to the
 	// command and not be overwritten.
 	WithAdditionalError(targets ...io.Writer) FinalizedBuilder
 
+	// WithContext binds the complete chain to the given context. If the context is done before the chain is done, the
+	// cancel signal (see WithCancelSignal) will be sent to all running commands. If they are still running after the
+	// grace period, they will be killed. Pipelines which are not started yet will not be started anymore. The errors
+	// of all affected commands will be a CanceledError. In contrast to CommandBuilder.JoinWithContext (which only
+	// affects a single command) this applies to all commands of the chain (including the commands of JoinShellCmd).
+	WithContext(ctx context.Context) FinalizedBuilder
+
+	// WithCancelSignal configures the signal which will be sent to all running commands if the chain's context (see
+	// WithContext) is done. After the given grace period all commands which are still running will be killed. By
+	// default, SIGTERM will be sent and the commands will be killed after 10 seconds.
+	WithCancelSignal(sig os.Signal, gracePeriod time.Duration) FinalizedBuilder
+
+	// WithTimeout will configure the complete chain to be canceled (see WithContext) if it is running longer than the
+	// given timeout. In that case the errors of all affected commands will be a TimeoutError.
+	WithTimeout(timeout time.Duration) FinalizedBuilder
+
+	// WithResultPolicy configures which errors of the commands of a pipeline will be taken into account (see
+	// ResultPolicy). The errors which are not taken into account will be nil in the returned MultipleErrors of Run.
+	// Independent of the policy, the exits of commands which are caused by the signal SIGPIPE are ignored if they
+	// are not the last command of the pipeline and the downstream command has exited before reading all their output
+	// (see CommandState.InputClosedEarly).
+	WithResultPolicy(policy ResultPolicy) FinalizedBuilder
+
+	// WithStdoutTail enables the capturing of the last bytes (at most maxBytes) which were written into the stdout of
+	// each command. The captured bytes can be checked by an ErrorChecker (see IgnoreStdoutMatching). Attention:
+	// streams which are piped into the next command are not captured! So only the stdout of the last command of
+	// each pipeline (and of commands with discarded stdout, see CommandBuilder.DiscardStdOut) can be checked.
+	WithStdoutTail(maxBytes int) FinalizedBuilder
+
+	// WithStderrTail enables the capturing of the last bytes (at most maxBytes) which were written into the stderr of
+	// each command. The captured bytes are part of the CommandError of a failed command and can be checked by an
+	// ErrorChecker (see IgnoreStderrMatching). Streams which are piped into the next command (see
+	// CommandBuilder.ForwardError) are not captured.
+	WithStderrTail(maxBytes int) FinalizedBuilder
+
 	// WithGlobalErrorChecker will configure the complete chain to use the given error checker. If there is an error
 	// checker configured for a special command, this error checker will be skipped for these one. In some cases
 	// the commands will return a non-zero exit code, which will normally cause an error at the Run().
 	// To avoid that you can use a ErrorChecker to ignore these kind of errors. There exists a set of functions which
-	// create a such ErrorChecker: IgnoreExitCode, IgnoreExitErrors, IgnoreAll, IgnoreNothing
+	// create a such ErrorChecker: IgnoreExitCode, IgnoreExitErrors, IgnoreSignals, IgnoreStdoutMatching,
+	// IgnoreStderrMatching, ByCommandName, IgnoreAll, IgnoreNothing. They can be combined by And, Or and Not.
 	WithGlobalErrorChecker(ErrorChecker) FinalizedBuilder
 
 	// Run will execute the command chain. It will start all underlying commands and wait after completion of all of
@@ -218,13 +380,96 @@ type FinalizedBuilder interface {
 	// case an MultipleErrors will be returned. If any command starting failed, the run will the error (single) of
 	// starting. All previously started commands should be exited in that case. Following commands will not be started.
 	// If any error occurs while commands are running, a MultipleErrors will return within all errors per
-	// command.
+	// command. The error of a command which has exited unsuccessfully is a CommandError (which wraps the original error,
+	// such as *exec.ExitError - so use errors.As instead of a type assertion). If the chain consists of
+	// multiple conditional pipelines (see JoinShellCmd), the returned MultipleErrors contains the errors of the
+	// pipeline which has been executed at last. If the chain consists of multiple statements, the returned
+	// MultipleErrors contains one entry per statement. Each of them is nil or a MultipleErrors within all errors per
+	// command of this statement. All nested errors can be checked by errors.Is and errors.As. The kind of the failure
+	// can be checked by errors.Is too (see ErrBuild, ErrStart, ErrRun and ErrStream).
+	//
+	// The chain can be run multiple times (e.g. for repeating jobs). Each further run will rebuild the commands, pipes
+	// and streams from the previously given configuration, so the runs are independent of each other. But the given
+	// readers and writers will be reused: a reader which was consumed by a previous run will not deliver any data. The
+	// files of shell redirections will be reopened for each run. The commands which are given to JoinCmd will be
+	// started by the first run, each further run starts copies of them. The copy of a command which was created by
+	// exec.CommandContext can not be bound to its context - so each further run will fail with an ErrBuild (use
+	// JoinWithContext instead).
 	Run() error
 
+	// Start will start the command chain without waiting for its completion. The returned RunningChain can be used
+	// to observe and control the running chain. If the building of the chain was failed or any command of the first
+	// pipeline could not be started, an error will be returned (such as Run). The errors which occur later (including
+	// the start errors of following pipelines) will be returned by RunningChain.Wait. Such as Run, the chain can be
+	// started multiple times (even concurrently). Each start results in its own RunningChain. Because the report of
+	// the RunningChain can be requested, the streams of all commands will be counted (see RunReport). So each pipe
+	// between two commands is copied by the chain - use Run if the report is not needed.
+	Start() (RunningChain, error)
+
+	// RunWithReport works like Run in addition the function will return a report which contains the details of all
+	// commands (see RunReport). The streams of all commands will be counted, so each pipe between two commands is
+	// copied by the chain.
+	RunWithReport() (RunReport, error)
+
 	// RunAndGet works like Run in addition the function will return the stdout and stderr of the command chain. Be
 	// careful with this convenience function because the stdout and stderr will be stored in memory!
 	RunAndGet() (string, string, error)
 
+	// RunAndGetLimited works like RunAndGet but only a limited number of bytes of the stdout (maxOut) and the stderr
+	// (maxErr) will be stored in memory. Which part of the streams will be kept depends on the given mode (see
+	// CaptureMode). If a stream is longer than its limit, the returned Capture is marked as truncated. To capture the
+	// output of other commands of the chain, see CaptureWriter.
+	RunAndGetLimited(maxOut, maxErr int, mode CaptureMode) (Capture, Capture, error)
+
+	// StartReader will start the command chain (see Start) and returns a reader of its stdout. This stream will be
+	// written additionally to the configured output streams (see WithOutput). The reader must be read continuously
+	// until its end, otherwise the last command will be blocked while writing its output. After the chain is done,
+	// the reader's last Read will return io.EOF or the error of the chain (see Run). The chain's error will also be
+	// returned by Close. If the reader is closed before the chain is done, the chain will be terminated such as it
+	// would be canceled (see WithContext). In that case, the errors which are caused by the termination are not
+	// returned by Close - but the errors of commands which have failed before are still returned.
+	StartReader() io.ReadCloser
+
+	// RunLines will start the command chain (see StartReader) and returns a channel which receives the lines of its
+	// stdout (without line endings). After the chain is done, the lines channel will be closed and the error of the
+	// chain (if any) will be sent into the error channel. The error channel will be closed after that. If the given
+	// context is done, the chain will be terminated (see StartReader) and the context's error will be sent instead.
+	RunLines(ctx context.Context) (<-chan string, <-chan error)
+
+	// RunRecords works like RunLines but the stdout will be split into records by the given split function (e.g.
+	// bufio.ScanLines, bufio.ScanWords or ScanNull). A single record must not be longer than 1 MiB. To consume
+	// the output of other commands of the chain, see RecordWriter.
+	RunRecords(ctx context.Context, split bufio.SplitFunc) (<-chan string, <-chan error)
+
 	// String returns a string representation of the command chain.
 	String() string
 }
+
+// RunningChain is a handle of a started command chain (see FinalizedBuilder.Start).
+type RunningChain interface {
+
+	// Wait waits until the chain is done. The returned error is the same as FinalizedBuilder.Run would be returned.
+	// It can be called multiple times.
+	Wait() error
+
+	// Done returns a channel which will be closed after the chain is done.
+	Done() <-chan struct{}
+
+	// Pids returns the process ids of all commands of the chain (same order as the commands are joined). The process
+	// id of commands which are not started yet (or are not executed as their own process) is 0.
+	Pids() []int
+
+	// States returns the current state of all commands of the chain (same order as the commands are joined).
+	States() []CommandState
+
+	// Report returns the details of all commands of the chain (see RunReport). The report is complete after the
+	// chain is done.
+	Report() RunReport
+
+	// Signal sends the given signal to all running commands of the chain. Builtins of shell commands (see
+	// ChainBuilder.JoinShellCmd) can not be signaled. Instead, their context will be canceled.
+	Signal(sig os.Signal) error
+
+	// Kill kills all running commands of the chain.
+	Kill() error
+}
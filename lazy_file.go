@@ -4,7 +4,7 @@ import (
 	"os"
 )
 
-// lazyFile is a wrapper around os.File that lazily opens the file when the first write operation is performed.
+// lazyFile is a wrapper around os.File that lazily opens the file when the first read or write operation is performed.
 type lazyFile struct {
 	name string
 	flag int
@@ -32,6 +32,16 @@ func (l *lazyFile) Write(p []byte) (n int, err error) {
 	return l.file.Write(p)
 }
 
+func (l *lazyFile) Read(p []byte) (n int, err error) {
+	l.BeforeRun()
+
+	if l.fileErr != nil {
+		return 0, l.fileErr
+	}
+
+	return l.file.Read(p)
+}
+
 func (l *lazyFile) BeforeRun() {
 	if l.file == nil {
 		l.file, l.fileErr = os.OpenFile(l.name, l.flag, l.perm)
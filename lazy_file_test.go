@@ -42,3 +42,25 @@ func TestLazyFile(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "second write", string(content))
 }
+
+func TestLazyFile_read(t *testing.T) {
+	toTest := newLazyFile(path.Join(t.TempDir(), "lazy_file_test"), os.O_RDONLY, 0)
+
+	_, err := toTest.Read(make([]byte, 1))
+	assert.Error(t, err, "file should not exist")
+	toTest.AfterRun()
+
+	require.NoError(t, os.WriteFile(toTest.name, []byte("content"), 0644))
+
+	toTest.BeforeRun()
+	content, err := io.ReadAll(toTest)
+	assert.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+	toTest.AfterRun()
+
+	// second read should reopen the file
+	content, err = io.ReadAll(toTest)
+	assert.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+	toTest.AfterRun()
+}
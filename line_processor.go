@@ -0,0 +1,182 @@
+package cmdchain
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// maxLineLength is the maximum length of a single line which can be processed by the line processors (e.g.
+// ChainBuilder.JoinLineMapper). Longer lines will cause an error (bufio.ErrTooLong).
+const maxLineLength = 1024 * 1024
+
+// lineProcessor processes the lines of a line oriented command (e.g. ChainBuilder.JoinLineMapper).
+type lineProcessor interface {
+	// process processes the given line. It returns false if no further lines should be processed.
+	process(line string, stdout io.Writer) (bool, error)
+
+	// finish is called after the last line was processed.
+	finish(stdout io.Writer) error
+}
+
+// joinLineProcessor joins a function which passes each line of its stdin to a processor. Each run gets its own
+// processor (created by the given function). The lines are read one after another, so only the current line is
+// held in memory.
+func (c *chain) joinLineProcessor(newProcessor func() lineProcessor, name string, args ...string) CommandBuilder {
+	return c.joinFunc(func(ctx context.Context, stdin io.Reader, stdout, _ io.Writer) error {
+		processor := newProcessor()
+
+		scanner := bufio.NewScanner(stdin)
+		scanner.Buffer(nil, maxLineLength)
+
+		for scanner.Scan() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			next, err := processor.process(scanner.Text(), stdout)
+			if err != nil {
+				return err
+			}
+			if !next {
+				// the rest of the input will be discarded (the upstream command will get a broken pipe)
+				return nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+
+		return processor.finish(stdout)
+	}, name, args...)
+}
+
+func writeLine(stdout io.Writer, line string) error {
+	_, err := io.WriteString(stdout, line+"\n")
+	return err
+}
+
+func (c *chain) JoinLineMapper(mapper func(line string) string) CommandBuilder {
+	return c.joinLineProcessor(func() lineProcessor { return lineMapper(mapper) }, "map")
+}
+
+func (c *chain) JoinLineFilter(filter func(line string) bool) CommandBuilder {
+	return c.joinLineProcessor(func() lineProcessor { return lineFilter(filter) }, "filter")
+}
+
+func (c *chain) JoinRegexFilter(pattern *regexp.Regexp) CommandBuilder {
+	return c.joinLineProcessor(func() lineProcessor { return lineFilter(pattern.MatchString) }, "grep", pattern.String())
+}
+
+func (c *chain) JoinHead(n int) CommandBuilder {
+	return c.joinLineProcessor(func() lineProcessor { return &lineHead{n: n} }, "head", strconv.Itoa(n))
+}
+
+func (c *chain) JoinTail(n int) CommandBuilder {
+	return c.joinLineProcessor(func() lineProcessor { return &lineTail{n: n} }, "tail", strconv.Itoa(n))
+}
+
+func (c *chain) JoinUniq() CommandBuilder {
+	return c.joinLineProcessor(func() lineProcessor { return &lineUniq{} }, "uniq")
+}
+
+// lineMapper writes the mapped lines.
+type lineMapper func(line string) string
+
+func (m lineMapper) process(line string, stdout io.Writer) (bool, error) {
+	return true, writeLine(stdout, m(line))
+}
+
+func (m lineMapper) finish(io.Writer) error {
+	return nil
+}
+
+// lineFilter writes only the lines which are accepted by the filter.
+type lineFilter func(line string) bool
+
+func (f lineFilter) process(line string, stdout io.Writer) (bool, error) {
+	if !f(line) {
+		return true, nil
+	}
+	return true, writeLine(stdout, line)
+}
+
+func (f lineFilter) finish(io.Writer) error {
+	return nil
+}
+
+// lineHead writes the first n lines.
+type lineHead struct {
+	n     int
+	count int
+}
+
+func (h *lineHead) process(line string, stdout io.Writer) (bool, error) {
+	if h.count >= h.n {
+		return false, nil
+	}
+	h.count++
+
+	// there is no need to wait for the next line if the last one is written
+	return h.count < h.n, writeLine(stdout, line)
+}
+
+func (h *lineHead) finish(io.Writer) error {
+	return nil
+}
+
+// lineTail writes the last n lines. Only these lines are held in memory (as ring buffer).
+type lineTail struct {
+	n     int
+	lines []string
+	next  int
+}
+
+func (t *lineTail) process(line string, _ io.Writer) (bool, error) {
+	if t.n <= 0 {
+		return true, nil
+	}
+
+	if len(t.lines) < t.n {
+		t.lines = append(t.lines, line)
+	} else {
+		t.lines[t.next] = line
+	}
+	t.next = (t.next + 1) % t.n
+
+	return true, nil
+}
+
+func (t *lineTail) finish(stdout io.Writer) error {
+	// if the buffer is not full, the next position is always the beginning
+	if len(t.lines) < t.n {
+		t.next = 0
+	}
+
+	for i := range t.lines {
+		if err := writeLine(stdout, t.lines[(t.next+i)%len(t.lines)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineUniq writes the lines without adjacent duplicates (such as uniq).
+type lineUniq struct {
+	previous *string
+}
+
+func (u *lineUniq) process(line string, stdout io.Writer) (bool, error) {
+	if u.previous != nil && *u.previous == line {
+		return true, nil
+	}
+	u.previous = &line
+
+	return true, writeLine(stdout, line)
+}
+
+func (u *lineUniq) finish(io.Writer) error {
+	return nil
+}
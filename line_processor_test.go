@@ -0,0 +1,105 @@
+package cmdchain
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestJoinLineProcessor(t *testing.T) {
+	input := "a\nb\nb\nc\nb\n"
+
+	tests := []struct {
+		name      string
+		toTest    func(ChainBuilder) CommandBuilder
+		expectOut string
+	}{
+		{"mapper", func(c ChainBuilder) CommandBuilder { return c.JoinLineMapper(strings.ToUpper) }, "A\nB\nB\nC\nB\n"},
+		{"filter", func(c ChainBuilder) CommandBuilder {
+			return c.JoinLineFilter(func(line string) bool { return line != "b" })
+		}, "a\nc\n"},
+		{"regex filter", func(c ChainBuilder) CommandBuilder { return c.JoinRegexFilter(regexp.MustCompile("^[ac]$")) }, "a\nc\n"},
+		{"head", func(c ChainBuilder) CommandBuilder { return c.JoinHead(2) }, "a\nb\n"},
+		{"head more than available", func(c ChainBuilder) CommandBuilder { return c.JoinHead(10) }, input},
+		{"head nothing", func(c ChainBuilder) CommandBuilder { return c.JoinHead(0) }, ""},
+		{"tail", func(c ChainBuilder) CommandBuilder { return c.JoinTail(2) }, "c\nb\n"},
+		{"tail more than available", func(c ChainBuilder) CommandBuilder { return c.JoinTail(10) }, input},
+		{"tail nothing", func(c ChainBuilder) CommandBuilder { return c.JoinTail(0) }, ""},
+		{"uniq", func(c ChainBuilder) CommandBuilder { return c.JoinUniq() }, "a\nb\nc\nb\n"},
+		{"combined", func(c ChainBuilder) CommandBuilder {
+			return c.JoinUniq().JoinLineMapper(strings.ToUpper).JoinTail(3).JoinHead(2)
+		}, "B\nC\n"},
+		{"between commands", func(c ChainBuilder) CommandBuilder {
+			return c.JoinRegexFilter(regexp.MustCompile("b")).Join("wc", "-l")
+		}, "3\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runAndCompare(t, tt.toTest(Builder().Join("printf", input)), tt.expectOut)
+		})
+	}
+}
+
+func TestJoinLineProcessor_withoutTrailingNewline(t *testing.T) {
+	toTest := Builder().
+		Join("printf", "a\r\nb").
+		JoinLineMapper(strings.ToUpper)
+
+	runAndCompare(t, toTest, "A\nB\n")
+}
+
+func TestJoinHead_stopsEarly(t *testing.T) {
+	tests := []struct {
+		name   string
+		toTest CommandBuilder
+	}{
+		{"command", Builder().Join("yes")},
+		{"function", Builder().JoinFunc(func(ctx context.Context, _ io.Reader, stdout, _ io.Writer) error {
+			for ctx.Err() == nil {
+				if _, err := io.WriteString(stdout, "y\n"); err != nil {
+					return err
+				}
+			}
+			return ctx.Err()
+		})},
+		{"line processor", Builder().Join("yes").JoinLineMapper(strings.ToUpper).JoinLineFilter(func(string) bool {
+			return true
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sOut, _, err := tt.toTest.JoinHead(3).Finalize().RunAndGet()
+
+			assert.NoError(t, err)
+			assert.Equal(t, 3, strings.Count(sOut, "\n"))
+		})
+	}
+}
+
+func TestJoinLineProcessor_lineTooLong(t *testing.T) {
+	err := Builder().
+		WithInput(strings.NewReader(strings.Repeat("a", maxLineLength+1))).
+		JoinUniq().
+		Finalize().Run()
+
+	assert.ErrorIs(t, err, bufio.ErrTooLong)
+}
+
+func TestJoinLineProcessor_multipleRuns(t *testing.T) {
+	toTest := Builder().
+		Join("printf", "a\nb\nc\n").
+		JoinHead(2).
+		Finalize()
+
+	for i := 0; i < 2; i++ {
+		sOut, _, err := toTest.RunAndGet()
+		assert.NoError(t, err)
+		assert.Equal(t, "a\nb\n", sOut)
+	}
+}
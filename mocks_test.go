@@ -10,10 +10,14 @@
 package cmdchain
 
 import (
+	bufio "bufio"
 	context "context"
 	io "io"
+	os "os"
 	exec "os/exec"
 	reflect "reflect"
+	regexp "regexp"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -161,6 +165,196 @@ func (c *MockChainBuilderJoinCmdCall) DoAndReturn(f func(*exec.Cmd) CommandBuild
 	return c
 }
 
+// JoinFunc mocks base method.
+func (m *MockChainBuilder) JoinFunc(fn CommandFunc) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinFunc", fn)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinFunc indicates an expected call of JoinFunc.
+func (mr *MockChainBuilderMockRecorder) JoinFunc(fn any) *MockChainBuilderJoinFuncCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinFunc", reflect.TypeOf((*MockChainBuilder)(nil).JoinFunc), fn)
+	return &MockChainBuilderJoinFuncCall{Call: call}
+}
+
+// MockChainBuilderJoinFuncCall wrap *gomock.Call
+type MockChainBuilderJoinFuncCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockChainBuilderJoinFuncCall) Return(arg0 CommandBuilder) *MockChainBuilderJoinFuncCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockChainBuilderJoinFuncCall) Do(f func(CommandFunc) CommandBuilder) *MockChainBuilderJoinFuncCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockChainBuilderJoinFuncCall) DoAndReturn(f func(CommandFunc) CommandBuilder) *MockChainBuilderJoinFuncCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinHead mocks base method.
+func (m *MockChainBuilder) JoinHead(n int) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinHead", n)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinHead indicates an expected call of JoinHead.
+func (mr *MockChainBuilderMockRecorder) JoinHead(n any) *MockChainBuilderJoinHeadCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinHead", reflect.TypeOf((*MockChainBuilder)(nil).JoinHead), n)
+	return &MockChainBuilderJoinHeadCall{Call: call}
+}
+
+// MockChainBuilderJoinHeadCall wrap *gomock.Call
+type MockChainBuilderJoinHeadCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockChainBuilderJoinHeadCall) Return(arg0 CommandBuilder) *MockChainBuilderJoinHeadCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockChainBuilderJoinHeadCall) Do(f func(int) CommandBuilder) *MockChainBuilderJoinHeadCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockChainBuilderJoinHeadCall) DoAndReturn(f func(int) CommandBuilder) *MockChainBuilderJoinHeadCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinLineFilter mocks base method.
+func (m *MockChainBuilder) JoinLineFilter(filter func(string) bool) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinLineFilter", filter)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinLineFilter indicates an expected call of JoinLineFilter.
+func (mr *MockChainBuilderMockRecorder) JoinLineFilter(filter any) *MockChainBuilderJoinLineFilterCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinLineFilter", reflect.TypeOf((*MockChainBuilder)(nil).JoinLineFilter), filter)
+	return &MockChainBuilderJoinLineFilterCall{Call: call}
+}
+
+// MockChainBuilderJoinLineFilterCall wrap *gomock.Call
+type MockChainBuilderJoinLineFilterCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockChainBuilderJoinLineFilterCall) Return(arg0 CommandBuilder) *MockChainBuilderJoinLineFilterCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockChainBuilderJoinLineFilterCall) Do(f func(func(string) bool) CommandBuilder) *MockChainBuilderJoinLineFilterCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockChainBuilderJoinLineFilterCall) DoAndReturn(f func(func(string) bool) CommandBuilder) *MockChainBuilderJoinLineFilterCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinLineMapper mocks base method.
+func (m *MockChainBuilder) JoinLineMapper(mapper func(string) string) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinLineMapper", mapper)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinLineMapper indicates an expected call of JoinLineMapper.
+func (mr *MockChainBuilderMockRecorder) JoinLineMapper(mapper any) *MockChainBuilderJoinLineMapperCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinLineMapper", reflect.TypeOf((*MockChainBuilder)(nil).JoinLineMapper), mapper)
+	return &MockChainBuilderJoinLineMapperCall{Call: call}
+}
+
+// MockChainBuilderJoinLineMapperCall wrap *gomock.Call
+type MockChainBuilderJoinLineMapperCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockChainBuilderJoinLineMapperCall) Return(arg0 CommandBuilder) *MockChainBuilderJoinLineMapperCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockChainBuilderJoinLineMapperCall) Do(f func(func(string) string) CommandBuilder) *MockChainBuilderJoinLineMapperCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockChainBuilderJoinLineMapperCall) DoAndReturn(f func(func(string) string) CommandBuilder) *MockChainBuilderJoinLineMapperCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinRegexFilter mocks base method.
+func (m *MockChainBuilder) JoinRegexFilter(pattern *regexp.Regexp) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinRegexFilter", pattern)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinRegexFilter indicates an expected call of JoinRegexFilter.
+func (mr *MockChainBuilderMockRecorder) JoinRegexFilter(pattern any) *MockChainBuilderJoinRegexFilterCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinRegexFilter", reflect.TypeOf((*MockChainBuilder)(nil).JoinRegexFilter), pattern)
+	return &MockChainBuilderJoinRegexFilterCall{Call: call}
+}
+
+// MockChainBuilderJoinRegexFilterCall wrap *gomock.Call
+type MockChainBuilderJoinRegexFilterCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockChainBuilderJoinRegexFilterCall) Return(arg0 CommandBuilder) *MockChainBuilderJoinRegexFilterCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockChainBuilderJoinRegexFilterCall) Do(f func(*regexp.Regexp) CommandBuilder) *MockChainBuilderJoinRegexFilterCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockChainBuilderJoinRegexFilterCall) DoAndReturn(f func(*regexp.Regexp) CommandBuilder) *MockChainBuilderJoinRegexFilterCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // JoinShellCmd mocks base method.
 func (m *MockChainBuilder) JoinShellCmd(command string) CommandBuilder {
 	m.ctrl.T.Helper()
@@ -237,6 +431,82 @@ func (c *MockChainBuilderJoinShellCmdWithContextCall) DoAndReturn(f func(context
 	return c
 }
 
+// JoinTail mocks base method.
+func (m *MockChainBuilder) JoinTail(n int) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinTail", n)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinTail indicates an expected call of JoinTail.
+func (mr *MockChainBuilderMockRecorder) JoinTail(n any) *MockChainBuilderJoinTailCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinTail", reflect.TypeOf((*MockChainBuilder)(nil).JoinTail), n)
+	return &MockChainBuilderJoinTailCall{Call: call}
+}
+
+// MockChainBuilderJoinTailCall wrap *gomock.Call
+type MockChainBuilderJoinTailCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockChainBuilderJoinTailCall) Return(arg0 CommandBuilder) *MockChainBuilderJoinTailCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockChainBuilderJoinTailCall) Do(f func(int) CommandBuilder) *MockChainBuilderJoinTailCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockChainBuilderJoinTailCall) DoAndReturn(f func(int) CommandBuilder) *MockChainBuilderJoinTailCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinUniq mocks base method.
+func (m *MockChainBuilder) JoinUniq() CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinUniq")
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinUniq indicates an expected call of JoinUniq.
+func (mr *MockChainBuilderMockRecorder) JoinUniq() *MockChainBuilderJoinUniqCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinUniq", reflect.TypeOf((*MockChainBuilder)(nil).JoinUniq))
+	return &MockChainBuilderJoinUniqCall{Call: call}
+}
+
+// MockChainBuilderJoinUniqCall wrap *gomock.Call
+type MockChainBuilderJoinUniqCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockChainBuilderJoinUniqCall) Return(arg0 CommandBuilder) *MockChainBuilderJoinUniqCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockChainBuilderJoinUniqCall) Do(f func() CommandBuilder) *MockChainBuilderJoinUniqCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockChainBuilderJoinUniqCall) DoAndReturn(f func() CommandBuilder) *MockChainBuilderJoinUniqCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // JoinWithContext mocks base method.
 func (m *MockChainBuilder) JoinWithContext(ctx context.Context, name string, args ...string) CommandBuilder {
 	m.ctrl.T.Helper()
@@ -423,1491 +693,2965 @@ func (c *MockFirstCommandBuilderJoinCmdCall) DoAndReturn(f func(*exec.Cmd) Comma
 	return c
 }
 
-// JoinShellCmd mocks base method.
-func (m *MockFirstCommandBuilder) JoinShellCmd(command string) CommandBuilder {
+// JoinFunc mocks base method.
+func (m *MockFirstCommandBuilder) JoinFunc(fn CommandFunc) CommandBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "JoinShellCmd", command)
+	ret := m.ctrl.Call(m, "JoinFunc", fn)
 	ret0, _ := ret[0].(CommandBuilder)
 	return ret0
 }
 
-// JoinShellCmd indicates an expected call of JoinShellCmd.
-func (mr *MockFirstCommandBuilderMockRecorder) JoinShellCmd(command any) *MockFirstCommandBuilderJoinShellCmdCall {
+// JoinFunc indicates an expected call of JoinFunc.
+func (mr *MockFirstCommandBuilderMockRecorder) JoinFunc(fn any) *MockFirstCommandBuilderJoinFuncCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinShellCmd", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinShellCmd), command)
-	return &MockFirstCommandBuilderJoinShellCmdCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinFunc", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinFunc), fn)
+	return &MockFirstCommandBuilderJoinFuncCall{Call: call}
 }
 
-// MockFirstCommandBuilderJoinShellCmdCall wrap *gomock.Call
-type MockFirstCommandBuilderJoinShellCmdCall struct {
+// MockFirstCommandBuilderJoinFuncCall wrap *gomock.Call
+type MockFirstCommandBuilderJoinFuncCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFirstCommandBuilderJoinShellCmdCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinShellCmdCall {
+func (c *MockFirstCommandBuilderJoinFuncCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinFuncCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFirstCommandBuilderJoinShellCmdCall) Do(f func(string) CommandBuilder) *MockFirstCommandBuilderJoinShellCmdCall {
+func (c *MockFirstCommandBuilderJoinFuncCall) Do(f func(CommandFunc) CommandBuilder) *MockFirstCommandBuilderJoinFuncCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFirstCommandBuilderJoinShellCmdCall) DoAndReturn(f func(string) CommandBuilder) *MockFirstCommandBuilderJoinShellCmdCall {
+func (c *MockFirstCommandBuilderJoinFuncCall) DoAndReturn(f func(CommandFunc) CommandBuilder) *MockFirstCommandBuilderJoinFuncCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// JoinShellCmdWithContext mocks base method.
-func (m *MockFirstCommandBuilder) JoinShellCmdWithContext(ctx context.Context, command string) CommandBuilder {
+// JoinHead mocks base method.
+func (m *MockFirstCommandBuilder) JoinHead(n int) CommandBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "JoinShellCmdWithContext", ctx, command)
+	ret := m.ctrl.Call(m, "JoinHead", n)
 	ret0, _ := ret[0].(CommandBuilder)
 	return ret0
 }
 
-// JoinShellCmdWithContext indicates an expected call of JoinShellCmdWithContext.
-func (mr *MockFirstCommandBuilderMockRecorder) JoinShellCmdWithContext(ctx, command any) *MockFirstCommandBuilderJoinShellCmdWithContextCall {
+// JoinHead indicates an expected call of JoinHead.
+func (mr *MockFirstCommandBuilderMockRecorder) JoinHead(n any) *MockFirstCommandBuilderJoinHeadCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinShellCmdWithContext", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinShellCmdWithContext), ctx, command)
-	return &MockFirstCommandBuilderJoinShellCmdWithContextCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinHead", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinHead), n)
+	return &MockFirstCommandBuilderJoinHeadCall{Call: call}
 }
 
-// MockFirstCommandBuilderJoinShellCmdWithContextCall wrap *gomock.Call
-type MockFirstCommandBuilderJoinShellCmdWithContextCall struct {
+// MockFirstCommandBuilderJoinHeadCall wrap *gomock.Call
+type MockFirstCommandBuilderJoinHeadCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFirstCommandBuilderJoinShellCmdWithContextCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinShellCmdWithContextCall {
+func (c *MockFirstCommandBuilderJoinHeadCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinHeadCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFirstCommandBuilderJoinShellCmdWithContextCall) Do(f func(context.Context, string) CommandBuilder) *MockFirstCommandBuilderJoinShellCmdWithContextCall {
+func (c *MockFirstCommandBuilderJoinHeadCall) Do(f func(int) CommandBuilder) *MockFirstCommandBuilderJoinHeadCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFirstCommandBuilderJoinShellCmdWithContextCall) DoAndReturn(f func(context.Context, string) CommandBuilder) *MockFirstCommandBuilderJoinShellCmdWithContextCall {
+func (c *MockFirstCommandBuilderJoinHeadCall) DoAndReturn(f func(int) CommandBuilder) *MockFirstCommandBuilderJoinHeadCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// JoinWithContext mocks base method.
-func (m *MockFirstCommandBuilder) JoinWithContext(ctx context.Context, name string, args ...string) CommandBuilder {
+// JoinLineFilter mocks base method.
+func (m *MockFirstCommandBuilder) JoinLineFilter(filter func(string) bool) CommandBuilder {
 	m.ctrl.T.Helper()
-	varargs := []any{ctx, name}
-	for _, a := range args {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "JoinWithContext", varargs...)
+	ret := m.ctrl.Call(m, "JoinLineFilter", filter)
 	ret0, _ := ret[0].(CommandBuilder)
 	return ret0
 }
 
-// JoinWithContext indicates an expected call of JoinWithContext.
-func (mr *MockFirstCommandBuilderMockRecorder) JoinWithContext(ctx, name any, args ...any) *MockFirstCommandBuilderJoinWithContextCall {
+// JoinLineFilter indicates an expected call of JoinLineFilter.
+func (mr *MockFirstCommandBuilderMockRecorder) JoinLineFilter(filter any) *MockFirstCommandBuilderJoinLineFilterCall {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]any{ctx, name}, args...)
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinWithContext", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinWithContext), varargs...)
-	return &MockFirstCommandBuilderJoinWithContextCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinLineFilter", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinLineFilter), filter)
+	return &MockFirstCommandBuilderJoinLineFilterCall{Call: call}
 }
 
-// MockFirstCommandBuilderJoinWithContextCall wrap *gomock.Call
-type MockFirstCommandBuilderJoinWithContextCall struct {
+// MockFirstCommandBuilderJoinLineFilterCall wrap *gomock.Call
+type MockFirstCommandBuilderJoinLineFilterCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFirstCommandBuilderJoinWithContextCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinWithContextCall {
+func (c *MockFirstCommandBuilderJoinLineFilterCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinLineFilterCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFirstCommandBuilderJoinWithContextCall) Do(f func(context.Context, string, ...string) CommandBuilder) *MockFirstCommandBuilderJoinWithContextCall {
+func (c *MockFirstCommandBuilderJoinLineFilterCall) Do(f func(func(string) bool) CommandBuilder) *MockFirstCommandBuilderJoinLineFilterCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFirstCommandBuilderJoinWithContextCall) DoAndReturn(f func(context.Context, string, ...string) CommandBuilder) *MockFirstCommandBuilderJoinWithContextCall {
+func (c *MockFirstCommandBuilderJoinLineFilterCall) DoAndReturn(f func(func(string) bool) CommandBuilder) *MockFirstCommandBuilderJoinLineFilterCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithInput mocks base method.
-func (m *MockFirstCommandBuilder) WithInput(sources ...io.Reader) ChainBuilder {
+// JoinLineMapper mocks base method.
+func (m *MockFirstCommandBuilder) JoinLineMapper(mapper func(string) string) CommandBuilder {
 	m.ctrl.T.Helper()
-	varargs := []any{}
-	for _, a := range sources {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "WithInput", varargs...)
-	ret0, _ := ret[0].(ChainBuilder)
+	ret := m.ctrl.Call(m, "JoinLineMapper", mapper)
+	ret0, _ := ret[0].(CommandBuilder)
 	return ret0
 }
 
-// WithInput indicates an expected call of WithInput.
-func (mr *MockFirstCommandBuilderMockRecorder) WithInput(sources ...any) *MockFirstCommandBuilderWithInputCall {
+// JoinLineMapper indicates an expected call of JoinLineMapper.
+func (mr *MockFirstCommandBuilderMockRecorder) JoinLineMapper(mapper any) *MockFirstCommandBuilderJoinLineMapperCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithInput", reflect.TypeOf((*MockFirstCommandBuilder)(nil).WithInput), sources...)
-	return &MockFirstCommandBuilderWithInputCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinLineMapper", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinLineMapper), mapper)
+	return &MockFirstCommandBuilderJoinLineMapperCall{Call: call}
 }
 
-// MockFirstCommandBuilderWithInputCall wrap *gomock.Call
-type MockFirstCommandBuilderWithInputCall struct {
+// MockFirstCommandBuilderJoinLineMapperCall wrap *gomock.Call
+type MockFirstCommandBuilderJoinLineMapperCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFirstCommandBuilderWithInputCall) Return(arg0 ChainBuilder) *MockFirstCommandBuilderWithInputCall {
+func (c *MockFirstCommandBuilderJoinLineMapperCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinLineMapperCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFirstCommandBuilderWithInputCall) Do(f func(...io.Reader) ChainBuilder) *MockFirstCommandBuilderWithInputCall {
+func (c *MockFirstCommandBuilderJoinLineMapperCall) Do(f func(func(string) string) CommandBuilder) *MockFirstCommandBuilderJoinLineMapperCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFirstCommandBuilderWithInputCall) DoAndReturn(f func(...io.Reader) ChainBuilder) *MockFirstCommandBuilderWithInputCall {
+func (c *MockFirstCommandBuilderJoinLineMapperCall) DoAndReturn(f func(func(string) string) CommandBuilder) *MockFirstCommandBuilderJoinLineMapperCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// MockCommandBuilder is a mock of CommandBuilder interface.
-type MockCommandBuilder struct {
-	ctrl     *gomock.Controller
-	recorder *MockCommandBuilderMockRecorder
-	isgomock struct{}
+// JoinRegexFilter mocks base method.
+func (m *MockFirstCommandBuilder) JoinRegexFilter(pattern *regexp.Regexp) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinRegexFilter", pattern)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
 }
 
-// MockCommandBuilderMockRecorder is the mock recorder for MockCommandBuilder.
-type MockCommandBuilderMockRecorder struct {
-	mock *MockCommandBuilder
+// JoinRegexFilter indicates an expected call of JoinRegexFilter.
+func (mr *MockFirstCommandBuilderMockRecorder) JoinRegexFilter(pattern any) *MockFirstCommandBuilderJoinRegexFilterCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinRegexFilter", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinRegexFilter), pattern)
+	return &MockFirstCommandBuilderJoinRegexFilterCall{Call: call}
 }
 
-// NewMockCommandBuilder creates a new mock instance.
-func NewMockCommandBuilder(ctrl *gomock.Controller) *MockCommandBuilder {
-	mock := &MockCommandBuilder{ctrl: ctrl}
-	mock.recorder = &MockCommandBuilderMockRecorder{mock}
-	return mock
+// MockFirstCommandBuilderJoinRegexFilterCall wrap *gomock.Call
+type MockFirstCommandBuilderJoinRegexFilterCall struct {
+	*gomock.Call
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockCommandBuilder) EXPECT() *MockCommandBuilderMockRecorder {
-	return m.recorder
+// Return rewrite *gomock.Call.Return
+func (c *MockFirstCommandBuilderJoinRegexFilterCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinRegexFilterCall {
+	c.Call = c.Call.Return(arg0)
+	return c
 }
 
-// Apply mocks base method.
-func (m *MockCommandBuilder) Apply(arg0 CommandApplier) CommandBuilder {
+// Do rewrite *gomock.Call.Do
+func (c *MockFirstCommandBuilderJoinRegexFilterCall) Do(f func(*regexp.Regexp) CommandBuilder) *MockFirstCommandBuilderJoinRegexFilterCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFirstCommandBuilderJoinRegexFilterCall) DoAndReturn(f func(*regexp.Regexp) CommandBuilder) *MockFirstCommandBuilderJoinRegexFilterCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinShellCmd mocks base method.
+func (m *MockFirstCommandBuilder) JoinShellCmd(command string) CommandBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Apply", arg0)
+	ret := m.ctrl.Call(m, "JoinShellCmd", command)
 	ret0, _ := ret[0].(CommandBuilder)
 	return ret0
 }
 
-// Apply indicates an expected call of Apply.
-func (mr *MockCommandBuilderMockRecorder) Apply(arg0 any) *MockCommandBuilderApplyCall {
+// JoinShellCmd indicates an expected call of JoinShellCmd.
+func (mr *MockFirstCommandBuilderMockRecorder) JoinShellCmd(command any) *MockFirstCommandBuilderJoinShellCmdCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockCommandBuilder)(nil).Apply), arg0)
-	return &MockCommandBuilderApplyCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinShellCmd", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinShellCmd), command)
+	return &MockFirstCommandBuilderJoinShellCmdCall{Call: call}
 }
 
-// MockCommandBuilderApplyCall wrap *gomock.Call
-type MockCommandBuilderApplyCall struct {
+// MockFirstCommandBuilderJoinShellCmdCall wrap *gomock.Call
+type MockFirstCommandBuilderJoinShellCmdCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderApplyCall) Return(arg0 CommandBuilder) *MockCommandBuilderApplyCall {
+func (c *MockFirstCommandBuilderJoinShellCmdCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinShellCmdCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderApplyCall) Do(f func(CommandApplier) CommandBuilder) *MockCommandBuilderApplyCall {
+func (c *MockFirstCommandBuilderJoinShellCmdCall) Do(f func(string) CommandBuilder) *MockFirstCommandBuilderJoinShellCmdCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderApplyCall) DoAndReturn(f func(CommandApplier) CommandBuilder) *MockCommandBuilderApplyCall {
+func (c *MockFirstCommandBuilderJoinShellCmdCall) DoAndReturn(f func(string) CommandBuilder) *MockFirstCommandBuilderJoinShellCmdCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ApplyBeforeStart mocks base method.
-func (m *MockCommandBuilder) ApplyBeforeStart(arg0 CommandApplier) CommandBuilder {
+// JoinShellCmdWithContext mocks base method.
+func (m *MockFirstCommandBuilder) JoinShellCmdWithContext(ctx context.Context, command string) CommandBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ApplyBeforeStart", arg0)
+	ret := m.ctrl.Call(m, "JoinShellCmdWithContext", ctx, command)
 	ret0, _ := ret[0].(CommandBuilder)
 	return ret0
 }
 
-// ApplyBeforeStart indicates an expected call of ApplyBeforeStart.
-func (mr *MockCommandBuilderMockRecorder) ApplyBeforeStart(arg0 any) *MockCommandBuilderApplyBeforeStartCall {
+// JoinShellCmdWithContext indicates an expected call of JoinShellCmdWithContext.
+func (mr *MockFirstCommandBuilderMockRecorder) JoinShellCmdWithContext(ctx, command any) *MockFirstCommandBuilderJoinShellCmdWithContextCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyBeforeStart", reflect.TypeOf((*MockCommandBuilder)(nil).ApplyBeforeStart), arg0)
-	return &MockCommandBuilderApplyBeforeStartCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinShellCmdWithContext", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinShellCmdWithContext), ctx, command)
+	return &MockFirstCommandBuilderJoinShellCmdWithContextCall{Call: call}
 }
 
-// MockCommandBuilderApplyBeforeStartCall wrap *gomock.Call
-type MockCommandBuilderApplyBeforeStartCall struct {
+// MockFirstCommandBuilderJoinShellCmdWithContextCall wrap *gomock.Call
+type MockFirstCommandBuilderJoinShellCmdWithContextCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderApplyBeforeStartCall) Return(arg0 CommandBuilder) *MockCommandBuilderApplyBeforeStartCall {
+func (c *MockFirstCommandBuilderJoinShellCmdWithContextCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinShellCmdWithContextCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderApplyBeforeStartCall) Do(f func(CommandApplier) CommandBuilder) *MockCommandBuilderApplyBeforeStartCall {
+func (c *MockFirstCommandBuilderJoinShellCmdWithContextCall) Do(f func(context.Context, string) CommandBuilder) *MockFirstCommandBuilderJoinShellCmdWithContextCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderApplyBeforeStartCall) DoAndReturn(f func(CommandApplier) CommandBuilder) *MockCommandBuilderApplyBeforeStartCall {
+func (c *MockFirstCommandBuilderJoinShellCmdWithContextCall) DoAndReturn(f func(context.Context, string) CommandBuilder) *MockFirstCommandBuilderJoinShellCmdWithContextCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// DiscardStdOut mocks base method.
-func (m *MockCommandBuilder) DiscardStdOut() CommandBuilder {
+// JoinTail mocks base method.
+func (m *MockFirstCommandBuilder) JoinTail(n int) CommandBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DiscardStdOut")
+	ret := m.ctrl.Call(m, "JoinTail", n)
 	ret0, _ := ret[0].(CommandBuilder)
 	return ret0
 }
 
-// DiscardStdOut indicates an expected call of DiscardStdOut.
-func (mr *MockCommandBuilderMockRecorder) DiscardStdOut() *MockCommandBuilderDiscardStdOutCall {
+// JoinTail indicates an expected call of JoinTail.
+func (mr *MockFirstCommandBuilderMockRecorder) JoinTail(n any) *MockFirstCommandBuilderJoinTailCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscardStdOut", reflect.TypeOf((*MockCommandBuilder)(nil).DiscardStdOut))
-	return &MockCommandBuilderDiscardStdOutCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinTail", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinTail), n)
+	return &MockFirstCommandBuilderJoinTailCall{Call: call}
 }
 
-// MockCommandBuilderDiscardStdOutCall wrap *gomock.Call
-type MockCommandBuilderDiscardStdOutCall struct {
+// MockFirstCommandBuilderJoinTailCall wrap *gomock.Call
+type MockFirstCommandBuilderJoinTailCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderDiscardStdOutCall) Return(arg0 CommandBuilder) *MockCommandBuilderDiscardStdOutCall {
+func (c *MockFirstCommandBuilderJoinTailCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinTailCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderDiscardStdOutCall) Do(f func() CommandBuilder) *MockCommandBuilderDiscardStdOutCall {
+func (c *MockFirstCommandBuilderJoinTailCall) Do(f func(int) CommandBuilder) *MockFirstCommandBuilderJoinTailCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderDiscardStdOutCall) DoAndReturn(f func() CommandBuilder) *MockCommandBuilderDiscardStdOutCall {
+func (c *MockFirstCommandBuilderJoinTailCall) DoAndReturn(f func(int) CommandBuilder) *MockFirstCommandBuilderJoinTailCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// Finalize mocks base method.
-func (m *MockCommandBuilder) Finalize() FinalizedBuilder {
+// JoinUniq mocks base method.
+func (m *MockFirstCommandBuilder) JoinUniq() CommandBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Finalize")
-	ret0, _ := ret[0].(FinalizedBuilder)
+	ret := m.ctrl.Call(m, "JoinUniq")
+	ret0, _ := ret[0].(CommandBuilder)
 	return ret0
 }
 
-// Finalize indicates an expected call of Finalize.
-func (mr *MockCommandBuilderMockRecorder) Finalize() *MockCommandBuilderFinalizeCall {
+// JoinUniq indicates an expected call of JoinUniq.
+func (mr *MockFirstCommandBuilderMockRecorder) JoinUniq() *MockFirstCommandBuilderJoinUniqCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finalize", reflect.TypeOf((*MockCommandBuilder)(nil).Finalize))
-	return &MockCommandBuilderFinalizeCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinUniq", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinUniq))
+	return &MockFirstCommandBuilderJoinUniqCall{Call: call}
 }
 
-// MockCommandBuilderFinalizeCall wrap *gomock.Call
-type MockCommandBuilderFinalizeCall struct {
+// MockFirstCommandBuilderJoinUniqCall wrap *gomock.Call
+type MockFirstCommandBuilderJoinUniqCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderFinalizeCall) Return(arg0 FinalizedBuilder) *MockCommandBuilderFinalizeCall {
+func (c *MockFirstCommandBuilderJoinUniqCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinUniqCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderFinalizeCall) Do(f func() FinalizedBuilder) *MockCommandBuilderFinalizeCall {
+func (c *MockFirstCommandBuilderJoinUniqCall) Do(f func() CommandBuilder) *MockFirstCommandBuilderJoinUniqCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderFinalizeCall) DoAndReturn(f func() FinalizedBuilder) *MockCommandBuilderFinalizeCall {
+func (c *MockFirstCommandBuilderJoinUniqCall) DoAndReturn(f func() CommandBuilder) *MockFirstCommandBuilderJoinUniqCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// ForwardError mocks base method.
-func (m *MockCommandBuilder) ForwardError() CommandBuilder {
+// JoinWithContext mocks base method.
+func (m *MockFirstCommandBuilder) JoinWithContext(ctx context.Context, name string, args ...string) CommandBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ForwardError")
+	varargs := []any{ctx, name}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "JoinWithContext", varargs...)
 	ret0, _ := ret[0].(CommandBuilder)
 	return ret0
 }
 
-// ForwardError indicates an expected call of ForwardError.
-func (mr *MockCommandBuilderMockRecorder) ForwardError() *MockCommandBuilderForwardErrorCall {
+// JoinWithContext indicates an expected call of JoinWithContext.
+func (mr *MockFirstCommandBuilderMockRecorder) JoinWithContext(ctx, name any, args ...any) *MockFirstCommandBuilderJoinWithContextCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForwardError", reflect.TypeOf((*MockCommandBuilder)(nil).ForwardError))
-	return &MockCommandBuilderForwardErrorCall{Call: call}
+	varargs := append([]any{ctx, name}, args...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinWithContext", reflect.TypeOf((*MockFirstCommandBuilder)(nil).JoinWithContext), varargs...)
+	return &MockFirstCommandBuilderJoinWithContextCall{Call: call}
 }
 
-// MockCommandBuilderForwardErrorCall wrap *gomock.Call
-type MockCommandBuilderForwardErrorCall struct {
+// MockFirstCommandBuilderJoinWithContextCall wrap *gomock.Call
+type MockFirstCommandBuilderJoinWithContextCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderForwardErrorCall) Return(arg0 CommandBuilder) *MockCommandBuilderForwardErrorCall {
+func (c *MockFirstCommandBuilderJoinWithContextCall) Return(arg0 CommandBuilder) *MockFirstCommandBuilderJoinWithContextCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderForwardErrorCall) Do(f func() CommandBuilder) *MockCommandBuilderForwardErrorCall {
+func (c *MockFirstCommandBuilderJoinWithContextCall) Do(f func(context.Context, string, ...string) CommandBuilder) *MockFirstCommandBuilderJoinWithContextCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderForwardErrorCall) DoAndReturn(f func() CommandBuilder) *MockCommandBuilderForwardErrorCall {
+func (c *MockFirstCommandBuilderJoinWithContextCall) DoAndReturn(f func(context.Context, string, ...string) CommandBuilder) *MockFirstCommandBuilderJoinWithContextCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// Join mocks base method.
-func (m *MockCommandBuilder) Join(name string, args ...string) CommandBuilder {
+// WithBuiltins mocks base method.
+func (m *MockFirstCommandBuilder) WithBuiltins(builtins map[string]Builtin) FirstCommandBuilder {
 	m.ctrl.T.Helper()
-	varargs := []any{name}
-	for _, a := range args {
+	ret := m.ctrl.Call(m, "WithBuiltins", builtins)
+	ret0, _ := ret[0].(FirstCommandBuilder)
+	return ret0
+}
+
+// WithBuiltins indicates an expected call of WithBuiltins.
+func (mr *MockFirstCommandBuilderMockRecorder) WithBuiltins(builtins any) *MockFirstCommandBuilderWithBuiltinsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithBuiltins", reflect.TypeOf((*MockFirstCommandBuilder)(nil).WithBuiltins), builtins)
+	return &MockFirstCommandBuilderWithBuiltinsCall{Call: call}
+}
+
+// MockFirstCommandBuilderWithBuiltinsCall wrap *gomock.Call
+type MockFirstCommandBuilderWithBuiltinsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFirstCommandBuilderWithBuiltinsCall) Return(arg0 FirstCommandBuilder) *MockFirstCommandBuilderWithBuiltinsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFirstCommandBuilderWithBuiltinsCall) Do(f func(map[string]Builtin) FirstCommandBuilder) *MockFirstCommandBuilderWithBuiltinsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFirstCommandBuilderWithBuiltinsCall) DoAndReturn(f func(map[string]Builtin) FirstCommandBuilder) *MockFirstCommandBuilderWithBuiltinsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithGlobExpansion mocks base method.
+func (m *MockFirstCommandBuilder) WithGlobExpansion(policy GlobPolicy) FirstCommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithGlobExpansion", policy)
+	ret0, _ := ret[0].(FirstCommandBuilder)
+	return ret0
+}
+
+// WithGlobExpansion indicates an expected call of WithGlobExpansion.
+func (mr *MockFirstCommandBuilderMockRecorder) WithGlobExpansion(policy any) *MockFirstCommandBuilderWithGlobExpansionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithGlobExpansion", reflect.TypeOf((*MockFirstCommandBuilder)(nil).WithGlobExpansion), policy)
+	return &MockFirstCommandBuilderWithGlobExpansionCall{Call: call}
+}
+
+// MockFirstCommandBuilderWithGlobExpansionCall wrap *gomock.Call
+type MockFirstCommandBuilderWithGlobExpansionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFirstCommandBuilderWithGlobExpansionCall) Return(arg0 FirstCommandBuilder) *MockFirstCommandBuilderWithGlobExpansionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFirstCommandBuilderWithGlobExpansionCall) Do(f func(GlobPolicy) FirstCommandBuilder) *MockFirstCommandBuilderWithGlobExpansionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFirstCommandBuilderWithGlobExpansionCall) DoAndReturn(f func(GlobPolicy) FirstCommandBuilder) *MockFirstCommandBuilderWithGlobExpansionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithInput mocks base method.
+func (m *MockFirstCommandBuilder) WithInput(sources ...io.Reader) ChainBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range sources {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "Join", varargs...)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "WithInput", varargs...)
+	ret0, _ := ret[0].(ChainBuilder)
 	return ret0
 }
 
-// Join indicates an expected call of Join.
-func (mr *MockCommandBuilderMockRecorder) Join(name any, args ...any) *MockCommandBuilderJoinCall {
+// WithInput indicates an expected call of WithInput.
+func (mr *MockFirstCommandBuilderMockRecorder) WithInput(sources ...any) *MockFirstCommandBuilderWithInputCall {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]any{name}, args...)
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Join", reflect.TypeOf((*MockCommandBuilder)(nil).Join), varargs...)
-	return &MockCommandBuilderJoinCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithInput", reflect.TypeOf((*MockFirstCommandBuilder)(nil).WithInput), sources...)
+	return &MockFirstCommandBuilderWithInputCall{Call: call}
 }
 
-// MockCommandBuilderJoinCall wrap *gomock.Call
-type MockCommandBuilderJoinCall struct {
+// MockFirstCommandBuilderWithInputCall wrap *gomock.Call
+type MockFirstCommandBuilderWithInputCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderJoinCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinCall {
+func (c *MockFirstCommandBuilderWithInputCall) Return(arg0 ChainBuilder) *MockFirstCommandBuilderWithInputCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderJoinCall) Do(f func(string, ...string) CommandBuilder) *MockCommandBuilderJoinCall {
+func (c *MockFirstCommandBuilderWithInputCall) Do(f func(...io.Reader) ChainBuilder) *MockFirstCommandBuilderWithInputCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderJoinCall) DoAndReturn(f func(string, ...string) CommandBuilder) *MockCommandBuilderJoinCall {
+func (c *MockFirstCommandBuilderWithInputCall) DoAndReturn(f func(...io.Reader) ChainBuilder) *MockFirstCommandBuilderWithInputCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// JoinCmd mocks base method.
-func (m *MockCommandBuilder) JoinCmd(cmd *exec.Cmd) CommandBuilder {
+// WithVariables mocks base method.
+func (m *MockFirstCommandBuilder) WithVariables(variables map[string]string) FirstCommandBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "JoinCmd", cmd)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "WithVariables", variables)
+	ret0, _ := ret[0].(FirstCommandBuilder)
 	return ret0
 }
 
-// JoinCmd indicates an expected call of JoinCmd.
-func (mr *MockCommandBuilderMockRecorder) JoinCmd(cmd any) *MockCommandBuilderJoinCmdCall {
+// WithVariables indicates an expected call of WithVariables.
+func (mr *MockFirstCommandBuilderMockRecorder) WithVariables(variables any) *MockFirstCommandBuilderWithVariablesCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinCmd", reflect.TypeOf((*MockCommandBuilder)(nil).JoinCmd), cmd)
-	return &MockCommandBuilderJoinCmdCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithVariables", reflect.TypeOf((*MockFirstCommandBuilder)(nil).WithVariables), variables)
+	return &MockFirstCommandBuilderWithVariablesCall{Call: call}
 }
 
-// MockCommandBuilderJoinCmdCall wrap *gomock.Call
-type MockCommandBuilderJoinCmdCall struct {
+// MockFirstCommandBuilderWithVariablesCall wrap *gomock.Call
+type MockFirstCommandBuilderWithVariablesCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderJoinCmdCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinCmdCall {
+func (c *MockFirstCommandBuilderWithVariablesCall) Return(arg0 FirstCommandBuilder) *MockFirstCommandBuilderWithVariablesCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderJoinCmdCall) Do(f func(*exec.Cmd) CommandBuilder) *MockCommandBuilderJoinCmdCall {
+func (c *MockFirstCommandBuilderWithVariablesCall) Do(f func(map[string]string) FirstCommandBuilder) *MockFirstCommandBuilderWithVariablesCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderJoinCmdCall) DoAndReturn(f func(*exec.Cmd) CommandBuilder) *MockCommandBuilderJoinCmdCall {
+func (c *MockFirstCommandBuilderWithVariablesCall) DoAndReturn(f func(map[string]string) FirstCommandBuilder) *MockFirstCommandBuilderWithVariablesCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// JoinShellCmd mocks base method.
-func (m *MockCommandBuilder) JoinShellCmd(command string) CommandBuilder {
+// MockCommandBuilder is a mock of CommandBuilder interface.
+type MockCommandBuilder struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommandBuilderMockRecorder
+	isgomock struct{}
+}
+
+// MockCommandBuilderMockRecorder is the mock recorder for MockCommandBuilder.
+type MockCommandBuilderMockRecorder struct {
+	mock *MockCommandBuilder
+}
+
+// NewMockCommandBuilder creates a new mock instance.
+func NewMockCommandBuilder(ctrl *gomock.Controller) *MockCommandBuilder {
+	mock := &MockCommandBuilder{ctrl: ctrl}
+	mock.recorder = &MockCommandBuilderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommandBuilder) EXPECT() *MockCommandBuilderMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockCommandBuilder) Apply(arg0 CommandApplier) CommandBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "JoinShellCmd", command)
+	ret := m.ctrl.Call(m, "Apply", arg0)
 	ret0, _ := ret[0].(CommandBuilder)
 	return ret0
 }
 
-// JoinShellCmd indicates an expected call of JoinShellCmd.
-func (mr *MockCommandBuilderMockRecorder) JoinShellCmd(command any) *MockCommandBuilderJoinShellCmdCall {
+// Apply indicates an expected call of Apply.
+func (mr *MockCommandBuilderMockRecorder) Apply(arg0 any) *MockCommandBuilderApplyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockCommandBuilder)(nil).Apply), arg0)
+	return &MockCommandBuilderApplyCall{Call: call}
+}
+
+// MockCommandBuilderApplyCall wrap *gomock.Call
+type MockCommandBuilderApplyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderApplyCall) Return(arg0 CommandBuilder) *MockCommandBuilderApplyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderApplyCall) Do(f func(CommandApplier) CommandBuilder) *MockCommandBuilderApplyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderApplyCall) DoAndReturn(f func(CommandApplier) CommandBuilder) *MockCommandBuilderApplyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ApplyBeforeStart mocks base method.
+func (m *MockCommandBuilder) ApplyBeforeStart(arg0 CommandApplier) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyBeforeStart", arg0)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// ApplyBeforeStart indicates an expected call of ApplyBeforeStart.
+func (mr *MockCommandBuilderMockRecorder) ApplyBeforeStart(arg0 any) *MockCommandBuilderApplyBeforeStartCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyBeforeStart", reflect.TypeOf((*MockCommandBuilder)(nil).ApplyBeforeStart), arg0)
+	return &MockCommandBuilderApplyBeforeStartCall{Call: call}
+}
+
+// MockCommandBuilderApplyBeforeStartCall wrap *gomock.Call
+type MockCommandBuilderApplyBeforeStartCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderApplyBeforeStartCall) Return(arg0 CommandBuilder) *MockCommandBuilderApplyBeforeStartCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderApplyBeforeStartCall) Do(f func(CommandApplier) CommandBuilder) *MockCommandBuilderApplyBeforeStartCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderApplyBeforeStartCall) DoAndReturn(f func(CommandApplier) CommandBuilder) *MockCommandBuilderApplyBeforeStartCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DiscardStdOut mocks base method.
+func (m *MockCommandBuilder) DiscardStdOut() CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiscardStdOut")
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// DiscardStdOut indicates an expected call of DiscardStdOut.
+func (mr *MockCommandBuilderMockRecorder) DiscardStdOut() *MockCommandBuilderDiscardStdOutCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscardStdOut", reflect.TypeOf((*MockCommandBuilder)(nil).DiscardStdOut))
+	return &MockCommandBuilderDiscardStdOutCall{Call: call}
+}
+
+// MockCommandBuilderDiscardStdOutCall wrap *gomock.Call
+type MockCommandBuilderDiscardStdOutCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderDiscardStdOutCall) Return(arg0 CommandBuilder) *MockCommandBuilderDiscardStdOutCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderDiscardStdOutCall) Do(f func() CommandBuilder) *MockCommandBuilderDiscardStdOutCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderDiscardStdOutCall) DoAndReturn(f func() CommandBuilder) *MockCommandBuilderDiscardStdOutCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Finalize mocks base method.
+func (m *MockCommandBuilder) Finalize() FinalizedBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finalize")
+	ret0, _ := ret[0].(FinalizedBuilder)
+	return ret0
+}
+
+// Finalize indicates an expected call of Finalize.
+func (mr *MockCommandBuilderMockRecorder) Finalize() *MockCommandBuilderFinalizeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finalize", reflect.TypeOf((*MockCommandBuilder)(nil).Finalize))
+	return &MockCommandBuilderFinalizeCall{Call: call}
+}
+
+// MockCommandBuilderFinalizeCall wrap *gomock.Call
+type MockCommandBuilderFinalizeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderFinalizeCall) Return(arg0 FinalizedBuilder) *MockCommandBuilderFinalizeCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderFinalizeCall) Do(f func() FinalizedBuilder) *MockCommandBuilderFinalizeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderFinalizeCall) DoAndReturn(f func() FinalizedBuilder) *MockCommandBuilderFinalizeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ForwardError mocks base method.
+func (m *MockCommandBuilder) ForwardError() CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForwardError")
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// ForwardError indicates an expected call of ForwardError.
+func (mr *MockCommandBuilderMockRecorder) ForwardError() *MockCommandBuilderForwardErrorCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForwardError", reflect.TypeOf((*MockCommandBuilder)(nil).ForwardError))
+	return &MockCommandBuilderForwardErrorCall{Call: call}
+}
+
+// MockCommandBuilderForwardErrorCall wrap *gomock.Call
+type MockCommandBuilderForwardErrorCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderForwardErrorCall) Return(arg0 CommandBuilder) *MockCommandBuilderForwardErrorCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderForwardErrorCall) Do(f func() CommandBuilder) *MockCommandBuilderForwardErrorCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderForwardErrorCall) DoAndReturn(f func() CommandBuilder) *MockCommandBuilderForwardErrorCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Join mocks base method.
+func (m *MockCommandBuilder) Join(name string, args ...string) CommandBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{name}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Join", varargs...)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// Join indicates an expected call of Join.
+func (mr *MockCommandBuilderMockRecorder) Join(name any, args ...any) *MockCommandBuilderJoinCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{name}, args...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Join", reflect.TypeOf((*MockCommandBuilder)(nil).Join), varargs...)
+	return &MockCommandBuilderJoinCall{Call: call}
+}
+
+// MockCommandBuilderJoinCall wrap *gomock.Call
+type MockCommandBuilderJoinCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinCall) Do(f func(string, ...string) CommandBuilder) *MockCommandBuilderJoinCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinCall) DoAndReturn(f func(string, ...string) CommandBuilder) *MockCommandBuilderJoinCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinCmd mocks base method.
+func (m *MockCommandBuilder) JoinCmd(cmd *exec.Cmd) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinCmd", cmd)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinCmd indicates an expected call of JoinCmd.
+func (mr *MockCommandBuilderMockRecorder) JoinCmd(cmd any) *MockCommandBuilderJoinCmdCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinCmd", reflect.TypeOf((*MockCommandBuilder)(nil).JoinCmd), cmd)
+	return &MockCommandBuilderJoinCmdCall{Call: call}
+}
+
+// MockCommandBuilderJoinCmdCall wrap *gomock.Call
+type MockCommandBuilderJoinCmdCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinCmdCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinCmdCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinCmdCall) Do(f func(*exec.Cmd) CommandBuilder) *MockCommandBuilderJoinCmdCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinCmdCall) DoAndReturn(f func(*exec.Cmd) CommandBuilder) *MockCommandBuilderJoinCmdCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinFunc mocks base method.
+func (m *MockCommandBuilder) JoinFunc(fn CommandFunc) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinFunc", fn)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinFunc indicates an expected call of JoinFunc.
+func (mr *MockCommandBuilderMockRecorder) JoinFunc(fn any) *MockCommandBuilderJoinFuncCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinFunc", reflect.TypeOf((*MockCommandBuilder)(nil).JoinFunc), fn)
+	return &MockCommandBuilderJoinFuncCall{Call: call}
+}
+
+// MockCommandBuilderJoinFuncCall wrap *gomock.Call
+type MockCommandBuilderJoinFuncCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinFuncCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinFuncCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinFuncCall) Do(f func(CommandFunc) CommandBuilder) *MockCommandBuilderJoinFuncCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinFuncCall) DoAndReturn(f func(CommandFunc) CommandBuilder) *MockCommandBuilderJoinFuncCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinHead mocks base method.
+func (m *MockCommandBuilder) JoinHead(n int) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinHead", n)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinHead indicates an expected call of JoinHead.
+func (mr *MockCommandBuilderMockRecorder) JoinHead(n any) *MockCommandBuilderJoinHeadCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinHead", reflect.TypeOf((*MockCommandBuilder)(nil).JoinHead), n)
+	return &MockCommandBuilderJoinHeadCall{Call: call}
+}
+
+// MockCommandBuilderJoinHeadCall wrap *gomock.Call
+type MockCommandBuilderJoinHeadCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinHeadCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinHeadCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinHeadCall) Do(f func(int) CommandBuilder) *MockCommandBuilderJoinHeadCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinHeadCall) DoAndReturn(f func(int) CommandBuilder) *MockCommandBuilderJoinHeadCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinLineFilter mocks base method.
+func (m *MockCommandBuilder) JoinLineFilter(filter func(string) bool) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinLineFilter", filter)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinLineFilter indicates an expected call of JoinLineFilter.
+func (mr *MockCommandBuilderMockRecorder) JoinLineFilter(filter any) *MockCommandBuilderJoinLineFilterCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinLineFilter", reflect.TypeOf((*MockCommandBuilder)(nil).JoinLineFilter), filter)
+	return &MockCommandBuilderJoinLineFilterCall{Call: call}
+}
+
+// MockCommandBuilderJoinLineFilterCall wrap *gomock.Call
+type MockCommandBuilderJoinLineFilterCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinLineFilterCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinLineFilterCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinLineFilterCall) Do(f func(func(string) bool) CommandBuilder) *MockCommandBuilderJoinLineFilterCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinLineFilterCall) DoAndReturn(f func(func(string) bool) CommandBuilder) *MockCommandBuilderJoinLineFilterCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinLineMapper mocks base method.
+func (m *MockCommandBuilder) JoinLineMapper(mapper func(string) string) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinLineMapper", mapper)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinLineMapper indicates an expected call of JoinLineMapper.
+func (mr *MockCommandBuilderMockRecorder) JoinLineMapper(mapper any) *MockCommandBuilderJoinLineMapperCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinLineMapper", reflect.TypeOf((*MockCommandBuilder)(nil).JoinLineMapper), mapper)
+	return &MockCommandBuilderJoinLineMapperCall{Call: call}
+}
+
+// MockCommandBuilderJoinLineMapperCall wrap *gomock.Call
+type MockCommandBuilderJoinLineMapperCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinLineMapperCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinLineMapperCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinLineMapperCall) Do(f func(func(string) string) CommandBuilder) *MockCommandBuilderJoinLineMapperCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinLineMapperCall) DoAndReturn(f func(func(string) string) CommandBuilder) *MockCommandBuilderJoinLineMapperCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinRegexFilter mocks base method.
+func (m *MockCommandBuilder) JoinRegexFilter(pattern *regexp.Regexp) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinRegexFilter", pattern)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinRegexFilter indicates an expected call of JoinRegexFilter.
+func (mr *MockCommandBuilderMockRecorder) JoinRegexFilter(pattern any) *MockCommandBuilderJoinRegexFilterCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinRegexFilter", reflect.TypeOf((*MockCommandBuilder)(nil).JoinRegexFilter), pattern)
+	return &MockCommandBuilderJoinRegexFilterCall{Call: call}
+}
+
+// MockCommandBuilderJoinRegexFilterCall wrap *gomock.Call
+type MockCommandBuilderJoinRegexFilterCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinRegexFilterCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinRegexFilterCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinRegexFilterCall) Do(f func(*regexp.Regexp) CommandBuilder) *MockCommandBuilderJoinRegexFilterCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinRegexFilterCall) DoAndReturn(f func(*regexp.Regexp) CommandBuilder) *MockCommandBuilderJoinRegexFilterCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinShellCmd mocks base method.
+func (m *MockCommandBuilder) JoinShellCmd(command string) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinShellCmd", command)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinShellCmd indicates an expected call of JoinShellCmd.
+func (mr *MockCommandBuilderMockRecorder) JoinShellCmd(command any) *MockCommandBuilderJoinShellCmdCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinShellCmd", reflect.TypeOf((*MockCommandBuilder)(nil).JoinShellCmd), command)
+	return &MockCommandBuilderJoinShellCmdCall{Call: call}
+}
+
+// MockCommandBuilderJoinShellCmdCall wrap *gomock.Call
+type MockCommandBuilderJoinShellCmdCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinShellCmdCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinShellCmdCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinShellCmdCall) Do(f func(string) CommandBuilder) *MockCommandBuilderJoinShellCmdCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinShellCmdCall) DoAndReturn(f func(string) CommandBuilder) *MockCommandBuilderJoinShellCmdCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinShellCmdWithContext mocks base method.
+func (m *MockCommandBuilder) JoinShellCmdWithContext(ctx context.Context, command string) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinShellCmdWithContext", ctx, command)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinShellCmdWithContext indicates an expected call of JoinShellCmdWithContext.
+func (mr *MockCommandBuilderMockRecorder) JoinShellCmdWithContext(ctx, command any) *MockCommandBuilderJoinShellCmdWithContextCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinShellCmdWithContext", reflect.TypeOf((*MockCommandBuilder)(nil).JoinShellCmdWithContext), ctx, command)
+	return &MockCommandBuilderJoinShellCmdWithContextCall{Call: call}
+}
+
+// MockCommandBuilderJoinShellCmdWithContextCall wrap *gomock.Call
+type MockCommandBuilderJoinShellCmdWithContextCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinShellCmdWithContextCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinShellCmdWithContextCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinShellCmdWithContextCall) Do(f func(context.Context, string) CommandBuilder) *MockCommandBuilderJoinShellCmdWithContextCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinShellCmdWithContextCall) DoAndReturn(f func(context.Context, string) CommandBuilder) *MockCommandBuilderJoinShellCmdWithContextCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinTail mocks base method.
+func (m *MockCommandBuilder) JoinTail(n int) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinTail", n)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinTail indicates an expected call of JoinTail.
+func (mr *MockCommandBuilderMockRecorder) JoinTail(n any) *MockCommandBuilderJoinTailCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinTail", reflect.TypeOf((*MockCommandBuilder)(nil).JoinTail), n)
+	return &MockCommandBuilderJoinTailCall{Call: call}
+}
+
+// MockCommandBuilderJoinTailCall wrap *gomock.Call
+type MockCommandBuilderJoinTailCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinTailCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinTailCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinTailCall) Do(f func(int) CommandBuilder) *MockCommandBuilderJoinTailCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinTailCall) DoAndReturn(f func(int) CommandBuilder) *MockCommandBuilderJoinTailCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinUniq mocks base method.
+func (m *MockCommandBuilder) JoinUniq() CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinUniq")
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinUniq indicates an expected call of JoinUniq.
+func (mr *MockCommandBuilderMockRecorder) JoinUniq() *MockCommandBuilderJoinUniqCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinUniq", reflect.TypeOf((*MockCommandBuilder)(nil).JoinUniq))
+	return &MockCommandBuilderJoinUniqCall{Call: call}
+}
+
+// MockCommandBuilderJoinUniqCall wrap *gomock.Call
+type MockCommandBuilderJoinUniqCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinUniqCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinUniqCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinUniqCall) Do(f func() CommandBuilder) *MockCommandBuilderJoinUniqCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinUniqCall) DoAndReturn(f func() CommandBuilder) *MockCommandBuilderJoinUniqCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// JoinWithContext mocks base method.
+func (m *MockCommandBuilder) JoinWithContext(ctx context.Context, name string, args ...string) CommandBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, name}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "JoinWithContext", varargs...)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// JoinWithContext indicates an expected call of JoinWithContext.
+func (mr *MockCommandBuilderMockRecorder) JoinWithContext(ctx, name any, args ...any) *MockCommandBuilderJoinWithContextCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, name}, args...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinWithContext", reflect.TypeOf((*MockCommandBuilder)(nil).JoinWithContext), varargs...)
+	return &MockCommandBuilderJoinWithContextCall{Call: call}
+}
+
+// MockCommandBuilderJoinWithContextCall wrap *gomock.Call
+type MockCommandBuilderJoinWithContextCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderJoinWithContextCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinWithContextCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderJoinWithContextCall) Do(f func(context.Context, string, ...string) CommandBuilder) *MockCommandBuilderJoinWithContextCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderJoinWithContextCall) DoAndReturn(f func(context.Context, string, ...string) CommandBuilder) *MockCommandBuilderJoinWithContextCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithAdditionalEnvironment mocks base method.
+func (m *MockCommandBuilder) WithAdditionalEnvironment(envMap ...any) CommandBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range envMap {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithAdditionalEnvironment", varargs...)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithAdditionalEnvironment indicates an expected call of WithAdditionalEnvironment.
+func (mr *MockCommandBuilderMockRecorder) WithAdditionalEnvironment(envMap ...any) *MockCommandBuilderWithAdditionalEnvironmentCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalEnvironment", reflect.TypeOf((*MockCommandBuilder)(nil).WithAdditionalEnvironment), envMap...)
+	return &MockCommandBuilderWithAdditionalEnvironmentCall{Call: call}
+}
+
+// MockCommandBuilderWithAdditionalEnvironmentCall wrap *gomock.Call
+type MockCommandBuilderWithAdditionalEnvironmentCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithAdditionalEnvironmentCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithAdditionalEnvironmentCall) Do(f func(...any) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithAdditionalEnvironmentCall) DoAndReturn(f func(...any) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithAdditionalEnvironmentMap mocks base method.
+func (m *MockCommandBuilder) WithAdditionalEnvironmentMap(envMap map[any]any) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithAdditionalEnvironmentMap", envMap)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithAdditionalEnvironmentMap indicates an expected call of WithAdditionalEnvironmentMap.
+func (mr *MockCommandBuilderMockRecorder) WithAdditionalEnvironmentMap(envMap any) *MockCommandBuilderWithAdditionalEnvironmentMapCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalEnvironmentMap", reflect.TypeOf((*MockCommandBuilder)(nil).WithAdditionalEnvironmentMap), envMap)
+	return &MockCommandBuilderWithAdditionalEnvironmentMapCall{Call: call}
+}
+
+// MockCommandBuilderWithAdditionalEnvironmentMapCall wrap *gomock.Call
+type MockCommandBuilderWithAdditionalEnvironmentMapCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithAdditionalEnvironmentMapCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentMapCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithAdditionalEnvironmentMapCall) Do(f func(map[any]any) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentMapCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithAdditionalEnvironmentMapCall) DoAndReturn(f func(map[any]any) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentMapCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithAdditionalEnvironmentPairs mocks base method.
+func (m *MockCommandBuilder) WithAdditionalEnvironmentPairs(envMap ...string) CommandBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range envMap {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithAdditionalEnvironmentPairs", varargs...)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithAdditionalEnvironmentPairs indicates an expected call of WithAdditionalEnvironmentPairs.
+func (mr *MockCommandBuilderMockRecorder) WithAdditionalEnvironmentPairs(envMap ...any) *MockCommandBuilderWithAdditionalEnvironmentPairsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalEnvironmentPairs", reflect.TypeOf((*MockCommandBuilder)(nil).WithAdditionalEnvironmentPairs), envMap...)
+	return &MockCommandBuilderWithAdditionalEnvironmentPairsCall{Call: call}
+}
+
+// MockCommandBuilderWithAdditionalEnvironmentPairsCall wrap *gomock.Call
+type MockCommandBuilderWithAdditionalEnvironmentPairsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithAdditionalEnvironmentPairsCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentPairsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithAdditionalEnvironmentPairsCall) Do(f func(...string) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentPairsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithAdditionalEnvironmentPairsCall) DoAndReturn(f func(...string) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentPairsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithAdditionalErrorForks mocks base method.
+func (m *MockCommandBuilder) WithAdditionalErrorForks(targets ...io.Writer) CommandBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range targets {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithAdditionalErrorForks", varargs...)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithAdditionalErrorForks indicates an expected call of WithAdditionalErrorForks.
+func (mr *MockCommandBuilderMockRecorder) WithAdditionalErrorForks(targets ...any) *MockCommandBuilderWithAdditionalErrorForksCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalErrorForks", reflect.TypeOf((*MockCommandBuilder)(nil).WithAdditionalErrorForks), targets...)
+	return &MockCommandBuilderWithAdditionalErrorForksCall{Call: call}
+}
+
+// MockCommandBuilderWithAdditionalErrorForksCall wrap *gomock.Call
+type MockCommandBuilderWithAdditionalErrorForksCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithAdditionalErrorForksCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithAdditionalErrorForksCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithAdditionalErrorForksCall) Do(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithAdditionalErrorForksCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithAdditionalErrorForksCall) DoAndReturn(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithAdditionalErrorForksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithAdditionalOutputForks mocks base method.
+func (m *MockCommandBuilder) WithAdditionalOutputForks(targets ...io.Writer) CommandBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range targets {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithAdditionalOutputForks", varargs...)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithAdditionalOutputForks indicates an expected call of WithAdditionalOutputForks.
+func (mr *MockCommandBuilderMockRecorder) WithAdditionalOutputForks(targets ...any) *MockCommandBuilderWithAdditionalOutputForksCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalOutputForks", reflect.TypeOf((*MockCommandBuilder)(nil).WithAdditionalOutputForks), targets...)
+	return &MockCommandBuilderWithAdditionalOutputForksCall{Call: call}
+}
+
+// MockCommandBuilderWithAdditionalOutputForksCall wrap *gomock.Call
+type MockCommandBuilderWithAdditionalOutputForksCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithAdditionalOutputForksCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithAdditionalOutputForksCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithAdditionalOutputForksCall) Do(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithAdditionalOutputForksCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithAdditionalOutputForksCall) DoAndReturn(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithAdditionalOutputForksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithEmptyEnvironment mocks base method.
+func (m *MockCommandBuilder) WithEmptyEnvironment() CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithEmptyEnvironment")
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithEmptyEnvironment indicates an expected call of WithEmptyEnvironment.
+func (mr *MockCommandBuilderMockRecorder) WithEmptyEnvironment() *MockCommandBuilderWithEmptyEnvironmentCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithEmptyEnvironment", reflect.TypeOf((*MockCommandBuilder)(nil).WithEmptyEnvironment))
+	return &MockCommandBuilderWithEmptyEnvironmentCall{Call: call}
+}
+
+// MockCommandBuilderWithEmptyEnvironmentCall wrap *gomock.Call
+type MockCommandBuilderWithEmptyEnvironmentCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithEmptyEnvironmentCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithEmptyEnvironmentCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithEmptyEnvironmentCall) Do(f func() CommandBuilder) *MockCommandBuilderWithEmptyEnvironmentCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithEmptyEnvironmentCall) DoAndReturn(f func() CommandBuilder) *MockCommandBuilderWithEmptyEnvironmentCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithEnvironment mocks base method.
+func (m *MockCommandBuilder) WithEnvironment(envMap ...any) CommandBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range envMap {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithEnvironment", varargs...)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithEnvironment indicates an expected call of WithEnvironment.
+func (mr *MockCommandBuilderMockRecorder) WithEnvironment(envMap ...any) *MockCommandBuilderWithEnvironmentCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithEnvironment", reflect.TypeOf((*MockCommandBuilder)(nil).WithEnvironment), envMap...)
+	return &MockCommandBuilderWithEnvironmentCall{Call: call}
+}
+
+// MockCommandBuilderWithEnvironmentCall wrap *gomock.Call
+type MockCommandBuilderWithEnvironmentCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithEnvironmentCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithEnvironmentCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithEnvironmentCall) Do(f func(...any) CommandBuilder) *MockCommandBuilderWithEnvironmentCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithEnvironmentCall) DoAndReturn(f func(...any) CommandBuilder) *MockCommandBuilderWithEnvironmentCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithEnvironmentMap mocks base method.
+func (m *MockCommandBuilder) WithEnvironmentMap(envMap map[any]any) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithEnvironmentMap", envMap)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithEnvironmentMap indicates an expected call of WithEnvironmentMap.
+func (mr *MockCommandBuilderMockRecorder) WithEnvironmentMap(envMap any) *MockCommandBuilderWithEnvironmentMapCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithEnvironmentMap", reflect.TypeOf((*MockCommandBuilder)(nil).WithEnvironmentMap), envMap)
+	return &MockCommandBuilderWithEnvironmentMapCall{Call: call}
+}
+
+// MockCommandBuilderWithEnvironmentMapCall wrap *gomock.Call
+type MockCommandBuilderWithEnvironmentMapCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithEnvironmentMapCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithEnvironmentMapCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithEnvironmentMapCall) Do(f func(map[any]any) CommandBuilder) *MockCommandBuilderWithEnvironmentMapCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithEnvironmentMapCall) DoAndReturn(f func(map[any]any) CommandBuilder) *MockCommandBuilderWithEnvironmentMapCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithEnvironmentPairs mocks base method.
+func (m *MockCommandBuilder) WithEnvironmentPairs(envMap ...string) CommandBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range envMap {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithEnvironmentPairs", varargs...)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithEnvironmentPairs indicates an expected call of WithEnvironmentPairs.
+func (mr *MockCommandBuilderMockRecorder) WithEnvironmentPairs(envMap ...any) *MockCommandBuilderWithEnvironmentPairsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithEnvironmentPairs", reflect.TypeOf((*MockCommandBuilder)(nil).WithEnvironmentPairs), envMap...)
+	return &MockCommandBuilderWithEnvironmentPairsCall{Call: call}
+}
+
+// MockCommandBuilderWithEnvironmentPairsCall wrap *gomock.Call
+type MockCommandBuilderWithEnvironmentPairsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithEnvironmentPairsCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithEnvironmentPairsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithEnvironmentPairsCall) Do(f func(...string) CommandBuilder) *MockCommandBuilderWithEnvironmentPairsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithEnvironmentPairsCall) DoAndReturn(f func(...string) CommandBuilder) *MockCommandBuilderWithEnvironmentPairsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithErrorChecker mocks base method.
+func (m *MockCommandBuilder) WithErrorChecker(arg0 ErrorChecker) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithErrorChecker", arg0)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithErrorChecker indicates an expected call of WithErrorChecker.
+func (mr *MockCommandBuilderMockRecorder) WithErrorChecker(arg0 any) *MockCommandBuilderWithErrorCheckerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithErrorChecker", reflect.TypeOf((*MockCommandBuilder)(nil).WithErrorChecker), arg0)
+	return &MockCommandBuilderWithErrorCheckerCall{Call: call}
+}
+
+// MockCommandBuilderWithErrorCheckerCall wrap *gomock.Call
+type MockCommandBuilderWithErrorCheckerCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithErrorCheckerCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithErrorCheckerCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithErrorCheckerCall) Do(f func(ErrorChecker) CommandBuilder) *MockCommandBuilderWithErrorCheckerCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithErrorCheckerCall) DoAndReturn(f func(ErrorChecker) CommandBuilder) *MockCommandBuilderWithErrorCheckerCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithErrorForks mocks base method.
+func (m *MockCommandBuilder) WithErrorForks(targets ...io.Writer) CommandBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range targets {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithErrorForks", varargs...)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithErrorForks indicates an expected call of WithErrorForks.
+func (mr *MockCommandBuilderMockRecorder) WithErrorForks(targets ...any) *MockCommandBuilderWithErrorForksCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithErrorForks", reflect.TypeOf((*MockCommandBuilder)(nil).WithErrorForks), targets...)
+	return &MockCommandBuilderWithErrorForksCall{Call: call}
+}
+
+// MockCommandBuilderWithErrorForksCall wrap *gomock.Call
+type MockCommandBuilderWithErrorForksCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithErrorForksCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithErrorForksCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithErrorForksCall) Do(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithErrorForksCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithErrorForksCall) DoAndReturn(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithErrorForksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithInjections mocks base method.
+func (m *MockCommandBuilder) WithInjections(sources ...io.Reader) CommandBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range sources {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithInjections", varargs...)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithInjections indicates an expected call of WithInjections.
+func (mr *MockCommandBuilderMockRecorder) WithInjections(sources ...any) *MockCommandBuilderWithInjectionsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithInjections", reflect.TypeOf((*MockCommandBuilder)(nil).WithInjections), sources...)
+	return &MockCommandBuilderWithInjectionsCall{Call: call}
+}
+
+// MockCommandBuilderWithInjectionsCall wrap *gomock.Call
+type MockCommandBuilderWithInjectionsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithInjectionsCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithInjectionsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithInjectionsCall) Do(f func(...io.Reader) CommandBuilder) *MockCommandBuilderWithInjectionsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithInjectionsCall) DoAndReturn(f func(...io.Reader) CommandBuilder) *MockCommandBuilderWithInjectionsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithOutputForks mocks base method.
+func (m *MockCommandBuilder) WithOutputForks(targets ...io.Writer) CommandBuilder {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range targets {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithOutputForks", varargs...)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithOutputForks indicates an expected call of WithOutputForks.
+func (mr *MockCommandBuilderMockRecorder) WithOutputForks(targets ...any) *MockCommandBuilderWithOutputForksCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithOutputForks", reflect.TypeOf((*MockCommandBuilder)(nil).WithOutputForks), targets...)
+	return &MockCommandBuilderWithOutputForksCall{Call: call}
+}
+
+// MockCommandBuilderWithOutputForksCall wrap *gomock.Call
+type MockCommandBuilderWithOutputForksCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithOutputForksCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithOutputForksCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithOutputForksCall) Do(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithOutputForksCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithOutputForksCall) DoAndReturn(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithOutputForksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithRetry mocks base method.
+func (m *MockCommandBuilder) WithRetry(policy RetryPolicy) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithRetry", policy)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithRetry indicates an expected call of WithRetry.
+func (mr *MockCommandBuilderMockRecorder) WithRetry(policy any) *MockCommandBuilderWithRetryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithRetry", reflect.TypeOf((*MockCommandBuilder)(nil).WithRetry), policy)
+	return &MockCommandBuilderWithRetryCall{Call: call}
+}
+
+// MockCommandBuilderWithRetryCall wrap *gomock.Call
+type MockCommandBuilderWithRetryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithRetryCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithRetryCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithRetryCall) Do(f func(RetryPolicy) CommandBuilder) *MockCommandBuilderWithRetryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithRetryCall) DoAndReturn(f func(RetryPolicy) CommandBuilder) *MockCommandBuilderWithRetryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithTimeout mocks base method.
+func (m *MockCommandBuilder) WithTimeout(timeout time.Duration) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTimeout", timeout)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithTimeout indicates an expected call of WithTimeout.
+func (mr *MockCommandBuilderMockRecorder) WithTimeout(timeout any) *MockCommandBuilderWithTimeoutCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTimeout", reflect.TypeOf((*MockCommandBuilder)(nil).WithTimeout), timeout)
+	return &MockCommandBuilderWithTimeoutCall{Call: call}
+}
+
+// MockCommandBuilderWithTimeoutCall wrap *gomock.Call
+type MockCommandBuilderWithTimeoutCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithTimeoutCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithTimeoutCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithTimeoutCall) Do(f func(time.Duration) CommandBuilder) *MockCommandBuilderWithTimeoutCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithTimeoutCall) DoAndReturn(f func(time.Duration) CommandBuilder) *MockCommandBuilderWithTimeoutCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithWorkingDirectory mocks base method.
+func (m *MockCommandBuilder) WithWorkingDirectory(workingDir string) CommandBuilder {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithWorkingDirectory", workingDir)
+	ret0, _ := ret[0].(CommandBuilder)
+	return ret0
+}
+
+// WithWorkingDirectory indicates an expected call of WithWorkingDirectory.
+func (mr *MockCommandBuilderMockRecorder) WithWorkingDirectory(workingDir any) *MockCommandBuilderWithWorkingDirectoryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithWorkingDirectory", reflect.TypeOf((*MockCommandBuilder)(nil).WithWorkingDirectory), workingDir)
+	return &MockCommandBuilderWithWorkingDirectoryCall{Call: call}
+}
+
+// MockCommandBuilderWithWorkingDirectoryCall wrap *gomock.Call
+type MockCommandBuilderWithWorkingDirectoryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockCommandBuilderWithWorkingDirectoryCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithWorkingDirectoryCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockCommandBuilderWithWorkingDirectoryCall) Do(f func(string) CommandBuilder) *MockCommandBuilderWithWorkingDirectoryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockCommandBuilderWithWorkingDirectoryCall) DoAndReturn(f func(string) CommandBuilder) *MockCommandBuilderWithWorkingDirectoryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockFinalizedBuilder is a mock of FinalizedBuilder interface.
+type MockFinalizedBuilder struct {
+	ctrl     *gomock.Controller
+	recorder *MockFinalizedBuilderMockRecorder
+	isgomock struct{}
+}
+
+// MockFinalizedBuilderMockRecorder is the mock recorder for MockFinalizedBuilder.
+type MockFinalizedBuilderMockRecorder struct {
+	mock *MockFinalizedBuilder
+}
+
+// NewMockFinalizedBuilder creates a new mock instance.
+func NewMockFinalizedBuilder(ctrl *gomock.Controller) *MockFinalizedBuilder {
+	mock := &MockFinalizedBuilder{ctrl: ctrl}
+	mock.recorder = &MockFinalizedBuilderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFinalizedBuilder) EXPECT() *MockFinalizedBuilderMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockFinalizedBuilder) Run() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockFinalizedBuilderMockRecorder) Run() *MockFinalizedBuilderRunCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockFinalizedBuilder)(nil).Run))
+	return &MockFinalizedBuilderRunCall{Call: call}
+}
+
+// MockFinalizedBuilderRunCall wrap *gomock.Call
+type MockFinalizedBuilderRunCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFinalizedBuilderRunCall) Return(arg0 error) *MockFinalizedBuilderRunCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFinalizedBuilderRunCall) Do(f func() error) *MockFinalizedBuilderRunCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFinalizedBuilderRunCall) DoAndReturn(f func() error) *MockFinalizedBuilderRunCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RunAndGet mocks base method.
+func (m *MockFinalizedBuilder) RunAndGet() (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunAndGet")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RunAndGet indicates an expected call of RunAndGet.
+func (mr *MockFinalizedBuilderMockRecorder) RunAndGet() *MockFinalizedBuilderRunAndGetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunAndGet", reflect.TypeOf((*MockFinalizedBuilder)(nil).RunAndGet))
+	return &MockFinalizedBuilderRunAndGetCall{Call: call}
+}
+
+// MockFinalizedBuilderRunAndGetCall wrap *gomock.Call
+type MockFinalizedBuilderRunAndGetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFinalizedBuilderRunAndGetCall) Return(arg0, arg1 string, arg2 error) *MockFinalizedBuilderRunAndGetCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFinalizedBuilderRunAndGetCall) Do(f func() (string, string, error)) *MockFinalizedBuilderRunAndGetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFinalizedBuilderRunAndGetCall) DoAndReturn(f func() (string, string, error)) *MockFinalizedBuilderRunAndGetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RunAndGetLimited mocks base method.
+func (m *MockFinalizedBuilder) RunAndGetLimited(maxOut, maxErr int, mode CaptureMode) (Capture, Capture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunAndGetLimited", maxOut, maxErr, mode)
+	ret0, _ := ret[0].(Capture)
+	ret1, _ := ret[1].(Capture)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RunAndGetLimited indicates an expected call of RunAndGetLimited.
+func (mr *MockFinalizedBuilderMockRecorder) RunAndGetLimited(maxOut, maxErr, mode any) *MockFinalizedBuilderRunAndGetLimitedCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinShellCmd", reflect.TypeOf((*MockCommandBuilder)(nil).JoinShellCmd), command)
-	return &MockCommandBuilderJoinShellCmdCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunAndGetLimited", reflect.TypeOf((*MockFinalizedBuilder)(nil).RunAndGetLimited), maxOut, maxErr, mode)
+	return &MockFinalizedBuilderRunAndGetLimitedCall{Call: call}
 }
 
-// MockCommandBuilderJoinShellCmdCall wrap *gomock.Call
-type MockCommandBuilderJoinShellCmdCall struct {
+// MockFinalizedBuilderRunAndGetLimitedCall wrap *gomock.Call
+type MockFinalizedBuilderRunAndGetLimitedCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderJoinShellCmdCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinShellCmdCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockFinalizedBuilderRunAndGetLimitedCall) Return(arg0, arg1 Capture, arg2 error) *MockFinalizedBuilderRunAndGetLimitedCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderJoinShellCmdCall) Do(f func(string) CommandBuilder) *MockCommandBuilderJoinShellCmdCall {
+func (c *MockFinalizedBuilderRunAndGetLimitedCall) Do(f func(int, int, CaptureMode) (Capture, Capture, error)) *MockFinalizedBuilderRunAndGetLimitedCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderJoinShellCmdCall) DoAndReturn(f func(string) CommandBuilder) *MockCommandBuilderJoinShellCmdCall {
+func (c *MockFinalizedBuilderRunAndGetLimitedCall) DoAndReturn(f func(int, int, CaptureMode) (Capture, Capture, error)) *MockFinalizedBuilderRunAndGetLimitedCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// JoinShellCmdWithContext mocks base method.
-func (m *MockCommandBuilder) JoinShellCmdWithContext(ctx context.Context, command string) CommandBuilder {
+// RunLines mocks base method.
+func (m *MockFinalizedBuilder) RunLines(ctx context.Context) (<-chan string, <-chan error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "JoinShellCmdWithContext", ctx, command)
-	ret0, _ := ret[0].(CommandBuilder)
-	return ret0
+	ret := m.ctrl.Call(m, "RunLines", ctx)
+	ret0, _ := ret[0].(<-chan string)
+	ret1, _ := ret[1].(<-chan error)
+	return ret0, ret1
 }
 
-// JoinShellCmdWithContext indicates an expected call of JoinShellCmdWithContext.
-func (mr *MockCommandBuilderMockRecorder) JoinShellCmdWithContext(ctx, command any) *MockCommandBuilderJoinShellCmdWithContextCall {
+// RunLines indicates an expected call of RunLines.
+func (mr *MockFinalizedBuilderMockRecorder) RunLines(ctx any) *MockFinalizedBuilderRunLinesCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinShellCmdWithContext", reflect.TypeOf((*MockCommandBuilder)(nil).JoinShellCmdWithContext), ctx, command)
-	return &MockCommandBuilderJoinShellCmdWithContextCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunLines", reflect.TypeOf((*MockFinalizedBuilder)(nil).RunLines), ctx)
+	return &MockFinalizedBuilderRunLinesCall{Call: call}
 }
 
-// MockCommandBuilderJoinShellCmdWithContextCall wrap *gomock.Call
-type MockCommandBuilderJoinShellCmdWithContextCall struct {
+// MockFinalizedBuilderRunLinesCall wrap *gomock.Call
+type MockFinalizedBuilderRunLinesCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderJoinShellCmdWithContextCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinShellCmdWithContextCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockFinalizedBuilderRunLinesCall) Return(arg0 <-chan string, arg1 <-chan error) *MockFinalizedBuilderRunLinesCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderJoinShellCmdWithContextCall) Do(f func(context.Context, string) CommandBuilder) *MockCommandBuilderJoinShellCmdWithContextCall {
+func (c *MockFinalizedBuilderRunLinesCall) Do(f func(context.Context) (<-chan string, <-chan error)) *MockFinalizedBuilderRunLinesCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderJoinShellCmdWithContextCall) DoAndReturn(f func(context.Context, string) CommandBuilder) *MockCommandBuilderJoinShellCmdWithContextCall {
+func (c *MockFinalizedBuilderRunLinesCall) DoAndReturn(f func(context.Context) (<-chan string, <-chan error)) *MockFinalizedBuilderRunLinesCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// JoinWithContext mocks base method.
-func (m *MockCommandBuilder) JoinWithContext(ctx context.Context, name string, args ...string) CommandBuilder {
+// RunRecords mocks base method.
+func (m *MockFinalizedBuilder) RunRecords(ctx context.Context, split bufio.SplitFunc) (<-chan string, <-chan error) {
 	m.ctrl.T.Helper()
-	varargs := []any{ctx, name}
-	for _, a := range args {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "JoinWithContext", varargs...)
-	ret0, _ := ret[0].(CommandBuilder)
-	return ret0
+	ret := m.ctrl.Call(m, "RunRecords", ctx, split)
+	ret0, _ := ret[0].(<-chan string)
+	ret1, _ := ret[1].(<-chan error)
+	return ret0, ret1
 }
 
-// JoinWithContext indicates an expected call of JoinWithContext.
-func (mr *MockCommandBuilderMockRecorder) JoinWithContext(ctx, name any, args ...any) *MockCommandBuilderJoinWithContextCall {
+// RunRecords indicates an expected call of RunRecords.
+func (mr *MockFinalizedBuilderMockRecorder) RunRecords(ctx, split any) *MockFinalizedBuilderRunRecordsCall {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]any{ctx, name}, args...)
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinWithContext", reflect.TypeOf((*MockCommandBuilder)(nil).JoinWithContext), varargs...)
-	return &MockCommandBuilderJoinWithContextCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunRecords", reflect.TypeOf((*MockFinalizedBuilder)(nil).RunRecords), ctx, split)
+	return &MockFinalizedBuilderRunRecordsCall{Call: call}
 }
 
-// MockCommandBuilderJoinWithContextCall wrap *gomock.Call
-type MockCommandBuilderJoinWithContextCall struct {
+// MockFinalizedBuilderRunRecordsCall wrap *gomock.Call
+type MockFinalizedBuilderRunRecordsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderJoinWithContextCall) Return(arg0 CommandBuilder) *MockCommandBuilderJoinWithContextCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockFinalizedBuilderRunRecordsCall) Return(arg0 <-chan string, arg1 <-chan error) *MockFinalizedBuilderRunRecordsCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderJoinWithContextCall) Do(f func(context.Context, string, ...string) CommandBuilder) *MockCommandBuilderJoinWithContextCall {
+func (c *MockFinalizedBuilderRunRecordsCall) Do(f func(context.Context, bufio.SplitFunc) (<-chan string, <-chan error)) *MockFinalizedBuilderRunRecordsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderJoinWithContextCall) DoAndReturn(f func(context.Context, string, ...string) CommandBuilder) *MockCommandBuilderJoinWithContextCall {
+func (c *MockFinalizedBuilderRunRecordsCall) DoAndReturn(f func(context.Context, bufio.SplitFunc) (<-chan string, <-chan error)) *MockFinalizedBuilderRunRecordsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithAdditionalEnvironment mocks base method.
-func (m *MockCommandBuilder) WithAdditionalEnvironment(envMap ...any) CommandBuilder {
+// RunWithReport mocks base method.
+func (m *MockFinalizedBuilder) RunWithReport() (RunReport, error) {
 	m.ctrl.T.Helper()
-	varargs := []any{}
-	for _, a := range envMap {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "WithAdditionalEnvironment", varargs...)
-	ret0, _ := ret[0].(CommandBuilder)
-	return ret0
+	ret := m.ctrl.Call(m, "RunWithReport")
+	ret0, _ := ret[0].(RunReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// WithAdditionalEnvironment indicates an expected call of WithAdditionalEnvironment.
-func (mr *MockCommandBuilderMockRecorder) WithAdditionalEnvironment(envMap ...any) *MockCommandBuilderWithAdditionalEnvironmentCall {
+// RunWithReport indicates an expected call of RunWithReport.
+func (mr *MockFinalizedBuilderMockRecorder) RunWithReport() *MockFinalizedBuilderRunWithReportCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalEnvironment", reflect.TypeOf((*MockCommandBuilder)(nil).WithAdditionalEnvironment), envMap...)
-	return &MockCommandBuilderWithAdditionalEnvironmentCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunWithReport", reflect.TypeOf((*MockFinalizedBuilder)(nil).RunWithReport))
+	return &MockFinalizedBuilderRunWithReportCall{Call: call}
 }
 
-// MockCommandBuilderWithAdditionalEnvironmentCall wrap *gomock.Call
-type MockCommandBuilderWithAdditionalEnvironmentCall struct {
+// MockFinalizedBuilderRunWithReportCall wrap *gomock.Call
+type MockFinalizedBuilderRunWithReportCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithAdditionalEnvironmentCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockFinalizedBuilderRunWithReportCall) Return(arg0 RunReport, arg1 error) *MockFinalizedBuilderRunWithReportCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithAdditionalEnvironmentCall) Do(f func(...any) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentCall {
+func (c *MockFinalizedBuilderRunWithReportCall) Do(f func() (RunReport, error)) *MockFinalizedBuilderRunWithReportCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithAdditionalEnvironmentCall) DoAndReturn(f func(...any) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentCall {
+func (c *MockFinalizedBuilderRunWithReportCall) DoAndReturn(f func() (RunReport, error)) *MockFinalizedBuilderRunWithReportCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithAdditionalEnvironmentMap mocks base method.
-func (m *MockCommandBuilder) WithAdditionalEnvironmentMap(envMap map[any]any) CommandBuilder {
+// Start mocks base method.
+func (m *MockFinalizedBuilder) Start() (RunningChain, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WithAdditionalEnvironmentMap", envMap)
-	ret0, _ := ret[0].(CommandBuilder)
-	return ret0
+	ret := m.ctrl.Call(m, "Start")
+	ret0, _ := ret[0].(RunningChain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// WithAdditionalEnvironmentMap indicates an expected call of WithAdditionalEnvironmentMap.
-func (mr *MockCommandBuilderMockRecorder) WithAdditionalEnvironmentMap(envMap any) *MockCommandBuilderWithAdditionalEnvironmentMapCall {
+// Start indicates an expected call of Start.
+func (mr *MockFinalizedBuilderMockRecorder) Start() *MockFinalizedBuilderStartCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalEnvironmentMap", reflect.TypeOf((*MockCommandBuilder)(nil).WithAdditionalEnvironmentMap), envMap)
-	return &MockCommandBuilderWithAdditionalEnvironmentMapCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockFinalizedBuilder)(nil).Start))
+	return &MockFinalizedBuilderStartCall{Call: call}
 }
 
-// MockCommandBuilderWithAdditionalEnvironmentMapCall wrap *gomock.Call
-type MockCommandBuilderWithAdditionalEnvironmentMapCall struct {
+// MockFinalizedBuilderStartCall wrap *gomock.Call
+type MockFinalizedBuilderStartCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithAdditionalEnvironmentMapCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentMapCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockFinalizedBuilderStartCall) Return(arg0 RunningChain, arg1 error) *MockFinalizedBuilderStartCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithAdditionalEnvironmentMapCall) Do(f func(map[any]any) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentMapCall {
+func (c *MockFinalizedBuilderStartCall) Do(f func() (RunningChain, error)) *MockFinalizedBuilderStartCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithAdditionalEnvironmentMapCall) DoAndReturn(f func(map[any]any) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentMapCall {
+func (c *MockFinalizedBuilderStartCall) DoAndReturn(f func() (RunningChain, error)) *MockFinalizedBuilderStartCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithAdditionalEnvironmentPairs mocks base method.
-func (m *MockCommandBuilder) WithAdditionalEnvironmentPairs(envMap ...string) CommandBuilder {
+// StartReader mocks base method.
+func (m *MockFinalizedBuilder) StartReader() io.ReadCloser {
 	m.ctrl.T.Helper()
-	varargs := []any{}
-	for _, a := range envMap {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "WithAdditionalEnvironmentPairs", varargs...)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "StartReader")
+	ret0, _ := ret[0].(io.ReadCloser)
 	return ret0
 }
 
-// WithAdditionalEnvironmentPairs indicates an expected call of WithAdditionalEnvironmentPairs.
-func (mr *MockCommandBuilderMockRecorder) WithAdditionalEnvironmentPairs(envMap ...any) *MockCommandBuilderWithAdditionalEnvironmentPairsCall {
+// StartReader indicates an expected call of StartReader.
+func (mr *MockFinalizedBuilderMockRecorder) StartReader() *MockFinalizedBuilderStartReaderCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalEnvironmentPairs", reflect.TypeOf((*MockCommandBuilder)(nil).WithAdditionalEnvironmentPairs), envMap...)
-	return &MockCommandBuilderWithAdditionalEnvironmentPairsCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartReader", reflect.TypeOf((*MockFinalizedBuilder)(nil).StartReader))
+	return &MockFinalizedBuilderStartReaderCall{Call: call}
 }
 
-// MockCommandBuilderWithAdditionalEnvironmentPairsCall wrap *gomock.Call
-type MockCommandBuilderWithAdditionalEnvironmentPairsCall struct {
+// MockFinalizedBuilderStartReaderCall wrap *gomock.Call
+type MockFinalizedBuilderStartReaderCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithAdditionalEnvironmentPairsCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentPairsCall {
+func (c *MockFinalizedBuilderStartReaderCall) Return(arg0 io.ReadCloser) *MockFinalizedBuilderStartReaderCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithAdditionalEnvironmentPairsCall) Do(f func(...string) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentPairsCall {
+func (c *MockFinalizedBuilderStartReaderCall) Do(f func() io.ReadCloser) *MockFinalizedBuilderStartReaderCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithAdditionalEnvironmentPairsCall) DoAndReturn(f func(...string) CommandBuilder) *MockCommandBuilderWithAdditionalEnvironmentPairsCall {
+func (c *MockFinalizedBuilderStartReaderCall) DoAndReturn(f func() io.ReadCloser) *MockFinalizedBuilderStartReaderCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithAdditionalErrorForks mocks base method.
-func (m *MockCommandBuilder) WithAdditionalErrorForks(targets ...io.Writer) CommandBuilder {
+// String mocks base method.
+func (m *MockFinalizedBuilder) String() string {
 	m.ctrl.T.Helper()
-	varargs := []any{}
-	for _, a := range targets {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "WithAdditionalErrorForks", varargs...)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "String")
+	ret0, _ := ret[0].(string)
 	return ret0
 }
 
-// WithAdditionalErrorForks indicates an expected call of WithAdditionalErrorForks.
-func (mr *MockCommandBuilderMockRecorder) WithAdditionalErrorForks(targets ...any) *MockCommandBuilderWithAdditionalErrorForksCall {
+// String indicates an expected call of String.
+func (mr *MockFinalizedBuilderMockRecorder) String() *MockFinalizedBuilderStringCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalErrorForks", reflect.TypeOf((*MockCommandBuilder)(nil).WithAdditionalErrorForks), targets...)
-	return &MockCommandBuilderWithAdditionalErrorForksCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "String", reflect.TypeOf((*MockFinalizedBuilder)(nil).String))
+	return &MockFinalizedBuilderStringCall{Call: call}
 }
 
-// MockCommandBuilderWithAdditionalErrorForksCall wrap *gomock.Call
-type MockCommandBuilderWithAdditionalErrorForksCall struct {
+// MockFinalizedBuilderStringCall wrap *gomock.Call
+type MockFinalizedBuilderStringCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithAdditionalErrorForksCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithAdditionalErrorForksCall {
+func (c *MockFinalizedBuilderStringCall) Return(arg0 string) *MockFinalizedBuilderStringCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithAdditionalErrorForksCall) Do(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithAdditionalErrorForksCall {
+func (c *MockFinalizedBuilderStringCall) Do(f func() string) *MockFinalizedBuilderStringCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithAdditionalErrorForksCall) DoAndReturn(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithAdditionalErrorForksCall {
+func (c *MockFinalizedBuilderStringCall) DoAndReturn(f func() string) *MockFinalizedBuilderStringCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithAdditionalOutputForks mocks base method.
-func (m *MockCommandBuilder) WithAdditionalOutputForks(targets ...io.Writer) CommandBuilder {
+// WithAdditionalError mocks base method.
+func (m *MockFinalizedBuilder) WithAdditionalError(targets ...io.Writer) FinalizedBuilder {
 	m.ctrl.T.Helper()
 	varargs := []any{}
 	for _, a := range targets {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "WithAdditionalOutputForks", varargs...)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "WithAdditionalError", varargs...)
+	ret0, _ := ret[0].(FinalizedBuilder)
 	return ret0
 }
 
-// WithAdditionalOutputForks indicates an expected call of WithAdditionalOutputForks.
-func (mr *MockCommandBuilderMockRecorder) WithAdditionalOutputForks(targets ...any) *MockCommandBuilderWithAdditionalOutputForksCall {
+// WithAdditionalError indicates an expected call of WithAdditionalError.
+func (mr *MockFinalizedBuilderMockRecorder) WithAdditionalError(targets ...any) *MockFinalizedBuilderWithAdditionalErrorCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalOutputForks", reflect.TypeOf((*MockCommandBuilder)(nil).WithAdditionalOutputForks), targets...)
-	return &MockCommandBuilderWithAdditionalOutputForksCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalError", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithAdditionalError), targets...)
+	return &MockFinalizedBuilderWithAdditionalErrorCall{Call: call}
 }
 
-// MockCommandBuilderWithAdditionalOutputForksCall wrap *gomock.Call
-type MockCommandBuilderWithAdditionalOutputForksCall struct {
+// MockFinalizedBuilderWithAdditionalErrorCall wrap *gomock.Call
+type MockFinalizedBuilderWithAdditionalErrorCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithAdditionalOutputForksCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithAdditionalOutputForksCall {
+func (c *MockFinalizedBuilderWithAdditionalErrorCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithAdditionalErrorCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithAdditionalOutputForksCall) Do(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithAdditionalOutputForksCall {
+func (c *MockFinalizedBuilderWithAdditionalErrorCall) Do(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithAdditionalErrorCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithAdditionalOutputForksCall) DoAndReturn(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithAdditionalOutputForksCall {
+func (c *MockFinalizedBuilderWithAdditionalErrorCall) DoAndReturn(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithAdditionalErrorCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithEmptyEnvironment mocks base method.
-func (m *MockCommandBuilder) WithEmptyEnvironment() CommandBuilder {
+// WithAdditionalOutput mocks base method.
+func (m *MockFinalizedBuilder) WithAdditionalOutput(targets ...io.Writer) FinalizedBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WithEmptyEnvironment")
-	ret0, _ := ret[0].(CommandBuilder)
+	varargs := []any{}
+	for _, a := range targets {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithAdditionalOutput", varargs...)
+	ret0, _ := ret[0].(FinalizedBuilder)
 	return ret0
 }
 
-// WithEmptyEnvironment indicates an expected call of WithEmptyEnvironment.
-func (mr *MockCommandBuilderMockRecorder) WithEmptyEnvironment() *MockCommandBuilderWithEmptyEnvironmentCall {
+// WithAdditionalOutput indicates an expected call of WithAdditionalOutput.
+func (mr *MockFinalizedBuilderMockRecorder) WithAdditionalOutput(targets ...any) *MockFinalizedBuilderWithAdditionalOutputCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithEmptyEnvironment", reflect.TypeOf((*MockCommandBuilder)(nil).WithEmptyEnvironment))
-	return &MockCommandBuilderWithEmptyEnvironmentCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalOutput", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithAdditionalOutput), targets...)
+	return &MockFinalizedBuilderWithAdditionalOutputCall{Call: call}
 }
 
-// MockCommandBuilderWithEmptyEnvironmentCall wrap *gomock.Call
-type MockCommandBuilderWithEmptyEnvironmentCall struct {
+// MockFinalizedBuilderWithAdditionalOutputCall wrap *gomock.Call
+type MockFinalizedBuilderWithAdditionalOutputCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithEmptyEnvironmentCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithEmptyEnvironmentCall {
+func (c *MockFinalizedBuilderWithAdditionalOutputCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithAdditionalOutputCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithEmptyEnvironmentCall) Do(f func() CommandBuilder) *MockCommandBuilderWithEmptyEnvironmentCall {
+func (c *MockFinalizedBuilderWithAdditionalOutputCall) Do(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithAdditionalOutputCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithEmptyEnvironmentCall) DoAndReturn(f func() CommandBuilder) *MockCommandBuilderWithEmptyEnvironmentCall {
+func (c *MockFinalizedBuilderWithAdditionalOutputCall) DoAndReturn(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithAdditionalOutputCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithEnvironment mocks base method.
-func (m *MockCommandBuilder) WithEnvironment(envMap ...any) CommandBuilder {
+// WithCancelSignal mocks base method.
+func (m *MockFinalizedBuilder) WithCancelSignal(sig os.Signal, gracePeriod time.Duration) FinalizedBuilder {
 	m.ctrl.T.Helper()
-	varargs := []any{}
-	for _, a := range envMap {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "WithEnvironment", varargs...)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "WithCancelSignal", sig, gracePeriod)
+	ret0, _ := ret[0].(FinalizedBuilder)
 	return ret0
 }
 
-// WithEnvironment indicates an expected call of WithEnvironment.
-func (mr *MockCommandBuilderMockRecorder) WithEnvironment(envMap ...any) *MockCommandBuilderWithEnvironmentCall {
+// WithCancelSignal indicates an expected call of WithCancelSignal.
+func (mr *MockFinalizedBuilderMockRecorder) WithCancelSignal(sig, gracePeriod any) *MockFinalizedBuilderWithCancelSignalCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithEnvironment", reflect.TypeOf((*MockCommandBuilder)(nil).WithEnvironment), envMap...)
-	return &MockCommandBuilderWithEnvironmentCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithCancelSignal", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithCancelSignal), sig, gracePeriod)
+	return &MockFinalizedBuilderWithCancelSignalCall{Call: call}
 }
 
-// MockCommandBuilderWithEnvironmentCall wrap *gomock.Call
-type MockCommandBuilderWithEnvironmentCall struct {
+// MockFinalizedBuilderWithCancelSignalCall wrap *gomock.Call
+type MockFinalizedBuilderWithCancelSignalCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithEnvironmentCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithEnvironmentCall {
+func (c *MockFinalizedBuilderWithCancelSignalCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithCancelSignalCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithEnvironmentCall) Do(f func(...any) CommandBuilder) *MockCommandBuilderWithEnvironmentCall {
+func (c *MockFinalizedBuilderWithCancelSignalCall) Do(f func(os.Signal, time.Duration) FinalizedBuilder) *MockFinalizedBuilderWithCancelSignalCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithEnvironmentCall) DoAndReturn(f func(...any) CommandBuilder) *MockCommandBuilderWithEnvironmentCall {
+func (c *MockFinalizedBuilderWithCancelSignalCall) DoAndReturn(f func(os.Signal, time.Duration) FinalizedBuilder) *MockFinalizedBuilderWithCancelSignalCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithEnvironmentMap mocks base method.
-func (m *MockCommandBuilder) WithEnvironmentMap(envMap map[any]any) CommandBuilder {
+// WithContext mocks base method.
+func (m *MockFinalizedBuilder) WithContext(ctx context.Context) FinalizedBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WithEnvironmentMap", envMap)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(FinalizedBuilder)
 	return ret0
 }
 
-// WithEnvironmentMap indicates an expected call of WithEnvironmentMap.
-func (mr *MockCommandBuilderMockRecorder) WithEnvironmentMap(envMap any) *MockCommandBuilderWithEnvironmentMapCall {
+// WithContext indicates an expected call of WithContext.
+func (mr *MockFinalizedBuilderMockRecorder) WithContext(ctx any) *MockFinalizedBuilderWithContextCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithEnvironmentMap", reflect.TypeOf((*MockCommandBuilder)(nil).WithEnvironmentMap), envMap)
-	return &MockCommandBuilderWithEnvironmentMapCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithContext), ctx)
+	return &MockFinalizedBuilderWithContextCall{Call: call}
 }
 
-// MockCommandBuilderWithEnvironmentMapCall wrap *gomock.Call
-type MockCommandBuilderWithEnvironmentMapCall struct {
+// MockFinalizedBuilderWithContextCall wrap *gomock.Call
+type MockFinalizedBuilderWithContextCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithEnvironmentMapCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithEnvironmentMapCall {
+func (c *MockFinalizedBuilderWithContextCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithContextCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithEnvironmentMapCall) Do(f func(map[any]any) CommandBuilder) *MockCommandBuilderWithEnvironmentMapCall {
+func (c *MockFinalizedBuilderWithContextCall) Do(f func(context.Context) FinalizedBuilder) *MockFinalizedBuilderWithContextCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithEnvironmentMapCall) DoAndReturn(f func(map[any]any) CommandBuilder) *MockCommandBuilderWithEnvironmentMapCall {
+func (c *MockFinalizedBuilderWithContextCall) DoAndReturn(f func(context.Context) FinalizedBuilder) *MockFinalizedBuilderWithContextCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithEnvironmentPairs mocks base method.
-func (m *MockCommandBuilder) WithEnvironmentPairs(envMap ...string) CommandBuilder {
+// WithError mocks base method.
+func (m *MockFinalizedBuilder) WithError(targets ...io.Writer) FinalizedBuilder {
 	m.ctrl.T.Helper()
 	varargs := []any{}
-	for _, a := range envMap {
+	for _, a := range targets {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "WithEnvironmentPairs", varargs...)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "WithError", varargs...)
+	ret0, _ := ret[0].(FinalizedBuilder)
 	return ret0
 }
 
-// WithEnvironmentPairs indicates an expected call of WithEnvironmentPairs.
-func (mr *MockCommandBuilderMockRecorder) WithEnvironmentPairs(envMap ...any) *MockCommandBuilderWithEnvironmentPairsCall {
+// WithError indicates an expected call of WithError.
+func (mr *MockFinalizedBuilderMockRecorder) WithError(targets ...any) *MockFinalizedBuilderWithErrorCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithEnvironmentPairs", reflect.TypeOf((*MockCommandBuilder)(nil).WithEnvironmentPairs), envMap...)
-	return &MockCommandBuilderWithEnvironmentPairsCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithError", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithError), targets...)
+	return &MockFinalizedBuilderWithErrorCall{Call: call}
 }
 
-// MockCommandBuilderWithEnvironmentPairsCall wrap *gomock.Call
-type MockCommandBuilderWithEnvironmentPairsCall struct {
+// MockFinalizedBuilderWithErrorCall wrap *gomock.Call
+type MockFinalizedBuilderWithErrorCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithEnvironmentPairsCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithEnvironmentPairsCall {
+func (c *MockFinalizedBuilderWithErrorCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithErrorCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithEnvironmentPairsCall) Do(f func(...string) CommandBuilder) *MockCommandBuilderWithEnvironmentPairsCall {
+func (c *MockFinalizedBuilderWithErrorCall) Do(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithErrorCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithEnvironmentPairsCall) DoAndReturn(f func(...string) CommandBuilder) *MockCommandBuilderWithEnvironmentPairsCall {
+func (c *MockFinalizedBuilderWithErrorCall) DoAndReturn(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithErrorCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithErrorChecker mocks base method.
-func (m *MockCommandBuilder) WithErrorChecker(arg0 ErrorChecker) CommandBuilder {
+// WithGlobalErrorChecker mocks base method.
+func (m *MockFinalizedBuilder) WithGlobalErrorChecker(arg0 ErrorChecker) FinalizedBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WithErrorChecker", arg0)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "WithGlobalErrorChecker", arg0)
+	ret0, _ := ret[0].(FinalizedBuilder)
 	return ret0
 }
 
-// WithErrorChecker indicates an expected call of WithErrorChecker.
-func (mr *MockCommandBuilderMockRecorder) WithErrorChecker(arg0 any) *MockCommandBuilderWithErrorCheckerCall {
-	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithErrorChecker", reflect.TypeOf((*MockCommandBuilder)(nil).WithErrorChecker), arg0)
-	return &MockCommandBuilderWithErrorCheckerCall{Call: call}
+// WithGlobalErrorChecker indicates an expected call of WithGlobalErrorChecker.
+func (mr *MockFinalizedBuilderMockRecorder) WithGlobalErrorChecker(arg0 any) *MockFinalizedBuilderWithGlobalErrorCheckerCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithGlobalErrorChecker", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithGlobalErrorChecker), arg0)
+	return &MockFinalizedBuilderWithGlobalErrorCheckerCall{Call: call}
 }
 
-// MockCommandBuilderWithErrorCheckerCall wrap *gomock.Call
-type MockCommandBuilderWithErrorCheckerCall struct {
+// MockFinalizedBuilderWithGlobalErrorCheckerCall wrap *gomock.Call
+type MockFinalizedBuilderWithGlobalErrorCheckerCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithErrorCheckerCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithErrorCheckerCall {
+func (c *MockFinalizedBuilderWithGlobalErrorCheckerCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithGlobalErrorCheckerCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithErrorCheckerCall) Do(f func(ErrorChecker) CommandBuilder) *MockCommandBuilderWithErrorCheckerCall {
+func (c *MockFinalizedBuilderWithGlobalErrorCheckerCall) Do(f func(ErrorChecker) FinalizedBuilder) *MockFinalizedBuilderWithGlobalErrorCheckerCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithErrorCheckerCall) DoAndReturn(f func(ErrorChecker) CommandBuilder) *MockCommandBuilderWithErrorCheckerCall {
+func (c *MockFinalizedBuilderWithGlobalErrorCheckerCall) DoAndReturn(f func(ErrorChecker) FinalizedBuilder) *MockFinalizedBuilderWithGlobalErrorCheckerCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithErrorForks mocks base method.
-func (m *MockCommandBuilder) WithErrorForks(targets ...io.Writer) CommandBuilder {
+// WithOutput mocks base method.
+func (m *MockFinalizedBuilder) WithOutput(targets ...io.Writer) FinalizedBuilder {
 	m.ctrl.T.Helper()
 	varargs := []any{}
 	for _, a := range targets {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "WithErrorForks", varargs...)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "WithOutput", varargs...)
+	ret0, _ := ret[0].(FinalizedBuilder)
 	return ret0
 }
 
-// WithErrorForks indicates an expected call of WithErrorForks.
-func (mr *MockCommandBuilderMockRecorder) WithErrorForks(targets ...any) *MockCommandBuilderWithErrorForksCall {
+// WithOutput indicates an expected call of WithOutput.
+func (mr *MockFinalizedBuilderMockRecorder) WithOutput(targets ...any) *MockFinalizedBuilderWithOutputCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithErrorForks", reflect.TypeOf((*MockCommandBuilder)(nil).WithErrorForks), targets...)
-	return &MockCommandBuilderWithErrorForksCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithOutput", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithOutput), targets...)
+	return &MockFinalizedBuilderWithOutputCall{Call: call}
 }
 
-// MockCommandBuilderWithErrorForksCall wrap *gomock.Call
-type MockCommandBuilderWithErrorForksCall struct {
+// MockFinalizedBuilderWithOutputCall wrap *gomock.Call
+type MockFinalizedBuilderWithOutputCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithErrorForksCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithErrorForksCall {
+func (c *MockFinalizedBuilderWithOutputCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithOutputCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithErrorForksCall) Do(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithErrorForksCall {
+func (c *MockFinalizedBuilderWithOutputCall) Do(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithOutputCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithErrorForksCall) DoAndReturn(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithErrorForksCall {
+func (c *MockFinalizedBuilderWithOutputCall) DoAndReturn(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithOutputCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithInjections mocks base method.
-func (m *MockCommandBuilder) WithInjections(sources ...io.Reader) CommandBuilder {
+// WithResultPolicy mocks base method.
+func (m *MockFinalizedBuilder) WithResultPolicy(policy ResultPolicy) FinalizedBuilder {
 	m.ctrl.T.Helper()
-	varargs := []any{}
-	for _, a := range sources {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "WithInjections", varargs...)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "WithResultPolicy", policy)
+	ret0, _ := ret[0].(FinalizedBuilder)
 	return ret0
 }
 
-// WithInjections indicates an expected call of WithInjections.
-func (mr *MockCommandBuilderMockRecorder) WithInjections(sources ...any) *MockCommandBuilderWithInjectionsCall {
+// WithResultPolicy indicates an expected call of WithResultPolicy.
+func (mr *MockFinalizedBuilderMockRecorder) WithResultPolicy(policy any) *MockFinalizedBuilderWithResultPolicyCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithInjections", reflect.TypeOf((*MockCommandBuilder)(nil).WithInjections), sources...)
-	return &MockCommandBuilderWithInjectionsCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithResultPolicy", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithResultPolicy), policy)
+	return &MockFinalizedBuilderWithResultPolicyCall{Call: call}
 }
 
-// MockCommandBuilderWithInjectionsCall wrap *gomock.Call
-type MockCommandBuilderWithInjectionsCall struct {
+// MockFinalizedBuilderWithResultPolicyCall wrap *gomock.Call
+type MockFinalizedBuilderWithResultPolicyCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithInjectionsCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithInjectionsCall {
+func (c *MockFinalizedBuilderWithResultPolicyCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithResultPolicyCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithInjectionsCall) Do(f func(...io.Reader) CommandBuilder) *MockCommandBuilderWithInjectionsCall {
+func (c *MockFinalizedBuilderWithResultPolicyCall) Do(f func(ResultPolicy) FinalizedBuilder) *MockFinalizedBuilderWithResultPolicyCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithInjectionsCall) DoAndReturn(f func(...io.Reader) CommandBuilder) *MockCommandBuilderWithInjectionsCall {
+func (c *MockFinalizedBuilderWithResultPolicyCall) DoAndReturn(f func(ResultPolicy) FinalizedBuilder) *MockFinalizedBuilderWithResultPolicyCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithOutputForks mocks base method.
-func (m *MockCommandBuilder) WithOutputForks(targets ...io.Writer) CommandBuilder {
+// WithStderrTail mocks base method.
+func (m *MockFinalizedBuilder) WithStderrTail(maxBytes int) FinalizedBuilder {
 	m.ctrl.T.Helper()
-	varargs := []any{}
-	for _, a := range targets {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "WithOutputForks", varargs...)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "WithStderrTail", maxBytes)
+	ret0, _ := ret[0].(FinalizedBuilder)
 	return ret0
 }
 
-// WithOutputForks indicates an expected call of WithOutputForks.
-func (mr *MockCommandBuilderMockRecorder) WithOutputForks(targets ...any) *MockCommandBuilderWithOutputForksCall {
+// WithStderrTail indicates an expected call of WithStderrTail.
+func (mr *MockFinalizedBuilderMockRecorder) WithStderrTail(maxBytes any) *MockFinalizedBuilderWithStderrTailCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithOutputForks", reflect.TypeOf((*MockCommandBuilder)(nil).WithOutputForks), targets...)
-	return &MockCommandBuilderWithOutputForksCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithStderrTail", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithStderrTail), maxBytes)
+	return &MockFinalizedBuilderWithStderrTailCall{Call: call}
 }
 
-// MockCommandBuilderWithOutputForksCall wrap *gomock.Call
-type MockCommandBuilderWithOutputForksCall struct {
+// MockFinalizedBuilderWithStderrTailCall wrap *gomock.Call
+type MockFinalizedBuilderWithStderrTailCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithOutputForksCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithOutputForksCall {
+func (c *MockFinalizedBuilderWithStderrTailCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithStderrTailCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithOutputForksCall) Do(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithOutputForksCall {
+func (c *MockFinalizedBuilderWithStderrTailCall) Do(f func(int) FinalizedBuilder) *MockFinalizedBuilderWithStderrTailCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithOutputForksCall) DoAndReturn(f func(...io.Writer) CommandBuilder) *MockCommandBuilderWithOutputForksCall {
+func (c *MockFinalizedBuilderWithStderrTailCall) DoAndReturn(f func(int) FinalizedBuilder) *MockFinalizedBuilderWithStderrTailCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithWorkingDirectory mocks base method.
-func (m *MockCommandBuilder) WithWorkingDirectory(workingDir string) CommandBuilder {
+// WithStdoutTail mocks base method.
+func (m *MockFinalizedBuilder) WithStdoutTail(maxBytes int) FinalizedBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WithWorkingDirectory", workingDir)
-	ret0, _ := ret[0].(CommandBuilder)
+	ret := m.ctrl.Call(m, "WithStdoutTail", maxBytes)
+	ret0, _ := ret[0].(FinalizedBuilder)
 	return ret0
 }
 
-// WithWorkingDirectory indicates an expected call of WithWorkingDirectory.
-func (mr *MockCommandBuilderMockRecorder) WithWorkingDirectory(workingDir any) *MockCommandBuilderWithWorkingDirectoryCall {
+// WithStdoutTail indicates an expected call of WithStdoutTail.
+func (mr *MockFinalizedBuilderMockRecorder) WithStdoutTail(maxBytes any) *MockFinalizedBuilderWithStdoutTailCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithWorkingDirectory", reflect.TypeOf((*MockCommandBuilder)(nil).WithWorkingDirectory), workingDir)
-	return &MockCommandBuilderWithWorkingDirectoryCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithStdoutTail", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithStdoutTail), maxBytes)
+	return &MockFinalizedBuilderWithStdoutTailCall{Call: call}
 }
 
-// MockCommandBuilderWithWorkingDirectoryCall wrap *gomock.Call
-type MockCommandBuilderWithWorkingDirectoryCall struct {
+// MockFinalizedBuilderWithStdoutTailCall wrap *gomock.Call
+type MockFinalizedBuilderWithStdoutTailCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockCommandBuilderWithWorkingDirectoryCall) Return(arg0 CommandBuilder) *MockCommandBuilderWithWorkingDirectoryCall {
+func (c *MockFinalizedBuilderWithStdoutTailCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithStdoutTailCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockCommandBuilderWithWorkingDirectoryCall) Do(f func(string) CommandBuilder) *MockCommandBuilderWithWorkingDirectoryCall {
+func (c *MockFinalizedBuilderWithStdoutTailCall) Do(f func(int) FinalizedBuilder) *MockFinalizedBuilderWithStdoutTailCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockCommandBuilderWithWorkingDirectoryCall) DoAndReturn(f func(string) CommandBuilder) *MockCommandBuilderWithWorkingDirectoryCall {
+func (c *MockFinalizedBuilderWithStdoutTailCall) DoAndReturn(f func(int) FinalizedBuilder) *MockFinalizedBuilderWithStdoutTailCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// MockFinalizedBuilder is a mock of FinalizedBuilder interface.
-type MockFinalizedBuilder struct {
-	ctrl     *gomock.Controller
-	recorder *MockFinalizedBuilderMockRecorder
-	isgomock struct{}
-}
-
-// MockFinalizedBuilderMockRecorder is the mock recorder for MockFinalizedBuilder.
-type MockFinalizedBuilderMockRecorder struct {
-	mock *MockFinalizedBuilder
-}
-
-// NewMockFinalizedBuilder creates a new mock instance.
-func NewMockFinalizedBuilder(ctrl *gomock.Controller) *MockFinalizedBuilder {
-	mock := &MockFinalizedBuilder{ctrl: ctrl}
-	mock.recorder = &MockFinalizedBuilderMockRecorder{mock}
-	return mock
-}
-
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockFinalizedBuilder) EXPECT() *MockFinalizedBuilderMockRecorder {
-	return m.recorder
-}
-
-// Run mocks base method.
-func (m *MockFinalizedBuilder) Run() error {
+// WithTimeout mocks base method.
+func (m *MockFinalizedBuilder) WithTimeout(timeout time.Duration) FinalizedBuilder {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Run")
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "WithTimeout", timeout)
+	ret0, _ := ret[0].(FinalizedBuilder)
 	return ret0
 }
 
-// Run indicates an expected call of Run.
-func (mr *MockFinalizedBuilderMockRecorder) Run() *MockFinalizedBuilderRunCall {
+// WithTimeout indicates an expected call of WithTimeout.
+func (mr *MockFinalizedBuilderMockRecorder) WithTimeout(timeout any) *MockFinalizedBuilderWithTimeoutCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockFinalizedBuilder)(nil).Run))
-	return &MockFinalizedBuilderRunCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTimeout", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithTimeout), timeout)
+	return &MockFinalizedBuilderWithTimeoutCall{Call: call}
 }
 
-// MockFinalizedBuilderRunCall wrap *gomock.Call
-type MockFinalizedBuilderRunCall struct {
+// MockFinalizedBuilderWithTimeoutCall wrap *gomock.Call
+type MockFinalizedBuilderWithTimeoutCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFinalizedBuilderRunCall) Return(arg0 error) *MockFinalizedBuilderRunCall {
+func (c *MockFinalizedBuilderWithTimeoutCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithTimeoutCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFinalizedBuilderRunCall) Do(f func() error) *MockFinalizedBuilderRunCall {
+func (c *MockFinalizedBuilderWithTimeoutCall) Do(f func(time.Duration) FinalizedBuilder) *MockFinalizedBuilderWithTimeoutCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFinalizedBuilderRunCall) DoAndReturn(f func() error) *MockFinalizedBuilderRunCall {
+func (c *MockFinalizedBuilderWithTimeoutCall) DoAndReturn(f func(time.Duration) FinalizedBuilder) *MockFinalizedBuilderWithTimeoutCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// RunAndGet mocks base method.
-func (m *MockFinalizedBuilder) RunAndGet() (string, string, error) {
+// MockRunningChain is a mock of RunningChain interface.
+type MockRunningChain struct {
+	ctrl     *gomock.Controller
+	recorder *MockRunningChainMockRecorder
+	isgomock struct{}
+}
+
+// MockRunningChainMockRecorder is the mock recorder for MockRunningChain.
+type MockRunningChainMockRecorder struct {
+	mock *MockRunningChain
+}
+
+// NewMockRunningChain creates a new mock instance.
+func NewMockRunningChain(ctrl *gomock.Controller) *MockRunningChain {
+	mock := &MockRunningChain{ctrl: ctrl}
+	mock.recorder = &MockRunningChainMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRunningChain) EXPECT() *MockRunningChainMockRecorder {
+	return m.recorder
+}
+
+// Done mocks base method.
+func (m *MockRunningChain) Done() <-chan struct{} {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RunAndGet")
-	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(string)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
 }
 
-// RunAndGet indicates an expected call of RunAndGet.
-func (mr *MockFinalizedBuilderMockRecorder) RunAndGet() *MockFinalizedBuilderRunAndGetCall {
+// Done indicates an expected call of Done.
+func (mr *MockRunningChainMockRecorder) Done() *MockRunningChainDoneCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunAndGet", reflect.TypeOf((*MockFinalizedBuilder)(nil).RunAndGet))
-	return &MockFinalizedBuilderRunAndGetCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockRunningChain)(nil).Done))
+	return &MockRunningChainDoneCall{Call: call}
 }
 
-// MockFinalizedBuilderRunAndGetCall wrap *gomock.Call
-type MockFinalizedBuilderRunAndGetCall struct {
+// MockRunningChainDoneCall wrap *gomock.Call
+type MockRunningChainDoneCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFinalizedBuilderRunAndGetCall) Return(arg0, arg1 string, arg2 error) *MockFinalizedBuilderRunAndGetCall {
-	c.Call = c.Call.Return(arg0, arg1, arg2)
+func (c *MockRunningChainDoneCall) Return(arg0 <-chan struct{}) *MockRunningChainDoneCall {
+	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFinalizedBuilderRunAndGetCall) Do(f func() (string, string, error)) *MockFinalizedBuilderRunAndGetCall {
+func (c *MockRunningChainDoneCall) Do(f func() <-chan struct{}) *MockRunningChainDoneCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFinalizedBuilderRunAndGetCall) DoAndReturn(f func() (string, string, error)) *MockFinalizedBuilderRunAndGetCall {
+func (c *MockRunningChainDoneCall) DoAndReturn(f func() <-chan struct{}) *MockRunningChainDoneCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// String mocks base method.
-func (m *MockFinalizedBuilder) String() string {
+// Kill mocks base method.
+func (m *MockRunningChain) Kill() error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "String")
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "Kill")
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// String indicates an expected call of String.
-func (mr *MockFinalizedBuilderMockRecorder) String() *MockFinalizedBuilderStringCall {
+// Kill indicates an expected call of Kill.
+func (mr *MockRunningChainMockRecorder) Kill() *MockRunningChainKillCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "String", reflect.TypeOf((*MockFinalizedBuilder)(nil).String))
-	return &MockFinalizedBuilderStringCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Kill", reflect.TypeOf((*MockRunningChain)(nil).Kill))
+	return &MockRunningChainKillCall{Call: call}
 }
 
-// MockFinalizedBuilderStringCall wrap *gomock.Call
-type MockFinalizedBuilderStringCall struct {
+// MockRunningChainKillCall wrap *gomock.Call
+type MockRunningChainKillCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFinalizedBuilderStringCall) Return(arg0 string) *MockFinalizedBuilderStringCall {
+func (c *MockRunningChainKillCall) Return(arg0 error) *MockRunningChainKillCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFinalizedBuilderStringCall) Do(f func() string) *MockFinalizedBuilderStringCall {
+func (c *MockRunningChainKillCall) Do(f func() error) *MockRunningChainKillCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFinalizedBuilderStringCall) DoAndReturn(f func() string) *MockFinalizedBuilderStringCall {
+func (c *MockRunningChainKillCall) DoAndReturn(f func() error) *MockRunningChainKillCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithAdditionalError mocks base method.
-func (m *MockFinalizedBuilder) WithAdditionalError(targets ...io.Writer) FinalizedBuilder {
+// Pids mocks base method.
+func (m *MockRunningChain) Pids() []int {
 	m.ctrl.T.Helper()
-	varargs := []any{}
-	for _, a := range targets {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "WithAdditionalError", varargs...)
-	ret0, _ := ret[0].(FinalizedBuilder)
+	ret := m.ctrl.Call(m, "Pids")
+	ret0, _ := ret[0].([]int)
 	return ret0
 }
 
-// WithAdditionalError indicates an expected call of WithAdditionalError.
-func (mr *MockFinalizedBuilderMockRecorder) WithAdditionalError(targets ...any) *MockFinalizedBuilderWithAdditionalErrorCall {
+// Pids indicates an expected call of Pids.
+func (mr *MockRunningChainMockRecorder) Pids() *MockRunningChainPidsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalError", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithAdditionalError), targets...)
-	return &MockFinalizedBuilderWithAdditionalErrorCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pids", reflect.TypeOf((*MockRunningChain)(nil).Pids))
+	return &MockRunningChainPidsCall{Call: call}
 }
 
-// MockFinalizedBuilderWithAdditionalErrorCall wrap *gomock.Call
-type MockFinalizedBuilderWithAdditionalErrorCall struct {
+// MockRunningChainPidsCall wrap *gomock.Call
+type MockRunningChainPidsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFinalizedBuilderWithAdditionalErrorCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithAdditionalErrorCall {
+func (c *MockRunningChainPidsCall) Return(arg0 []int) *MockRunningChainPidsCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFinalizedBuilderWithAdditionalErrorCall) Do(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithAdditionalErrorCall {
+func (c *MockRunningChainPidsCall) Do(f func() []int) *MockRunningChainPidsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFinalizedBuilderWithAdditionalErrorCall) DoAndReturn(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithAdditionalErrorCall {
+func (c *MockRunningChainPidsCall) DoAndReturn(f func() []int) *MockRunningChainPidsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithAdditionalOutput mocks base method.
-func (m *MockFinalizedBuilder) WithAdditionalOutput(targets ...io.Writer) FinalizedBuilder {
+// Report mocks base method.
+func (m *MockRunningChain) Report() RunReport {
 	m.ctrl.T.Helper()
-	varargs := []any{}
-	for _, a := range targets {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "WithAdditionalOutput", varargs...)
-	ret0, _ := ret[0].(FinalizedBuilder)
+	ret := m.ctrl.Call(m, "Report")
+	ret0, _ := ret[0].(RunReport)
 	return ret0
 }
 
-// WithAdditionalOutput indicates an expected call of WithAdditionalOutput.
-func (mr *MockFinalizedBuilderMockRecorder) WithAdditionalOutput(targets ...any) *MockFinalizedBuilderWithAdditionalOutputCall {
+// Report indicates an expected call of Report.
+func (mr *MockRunningChainMockRecorder) Report() *MockRunningChainReportCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithAdditionalOutput", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithAdditionalOutput), targets...)
-	return &MockFinalizedBuilderWithAdditionalOutputCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockRunningChain)(nil).Report))
+	return &MockRunningChainReportCall{Call: call}
 }
 
-// MockFinalizedBuilderWithAdditionalOutputCall wrap *gomock.Call
-type MockFinalizedBuilderWithAdditionalOutputCall struct {
+// MockRunningChainReportCall wrap *gomock.Call
+type MockRunningChainReportCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFinalizedBuilderWithAdditionalOutputCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithAdditionalOutputCall {
+func (c *MockRunningChainReportCall) Return(arg0 RunReport) *MockRunningChainReportCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFinalizedBuilderWithAdditionalOutputCall) Do(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithAdditionalOutputCall {
+func (c *MockRunningChainReportCall) Do(f func() RunReport) *MockRunningChainReportCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFinalizedBuilderWithAdditionalOutputCall) DoAndReturn(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithAdditionalOutputCall {
+func (c *MockRunningChainReportCall) DoAndReturn(f func() RunReport) *MockRunningChainReportCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithError mocks base method.
-func (m *MockFinalizedBuilder) WithError(targets ...io.Writer) FinalizedBuilder {
+// Signal mocks base method.
+func (m *MockRunningChain) Signal(sig os.Signal) error {
 	m.ctrl.T.Helper()
-	varargs := []any{}
-	for _, a := range targets {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "WithError", varargs...)
-	ret0, _ := ret[0].(FinalizedBuilder)
+	ret := m.ctrl.Call(m, "Signal", sig)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// WithError indicates an expected call of WithError.
-func (mr *MockFinalizedBuilderMockRecorder) WithError(targets ...any) *MockFinalizedBuilderWithErrorCall {
+// Signal indicates an expected call of Signal.
+func (mr *MockRunningChainMockRecorder) Signal(sig any) *MockRunningChainSignalCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithError", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithError), targets...)
-	return &MockFinalizedBuilderWithErrorCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Signal", reflect.TypeOf((*MockRunningChain)(nil).Signal), sig)
+	return &MockRunningChainSignalCall{Call: call}
 }
 
-// MockFinalizedBuilderWithErrorCall wrap *gomock.Call
-type MockFinalizedBuilderWithErrorCall struct {
+// MockRunningChainSignalCall wrap *gomock.Call
+type MockRunningChainSignalCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFinalizedBuilderWithErrorCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithErrorCall {
+func (c *MockRunningChainSignalCall) Return(arg0 error) *MockRunningChainSignalCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFinalizedBuilderWithErrorCall) Do(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithErrorCall {
+func (c *MockRunningChainSignalCall) Do(f func(os.Signal) error) *MockRunningChainSignalCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFinalizedBuilderWithErrorCall) DoAndReturn(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithErrorCall {
+func (c *MockRunningChainSignalCall) DoAndReturn(f func(os.Signal) error) *MockRunningChainSignalCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithGlobalErrorChecker mocks base method.
-func (m *MockFinalizedBuilder) WithGlobalErrorChecker(arg0 ErrorChecker) FinalizedBuilder {
+// States mocks base method.
+func (m *MockRunningChain) States() []CommandState {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WithGlobalErrorChecker", arg0)
-	ret0, _ := ret[0].(FinalizedBuilder)
+	ret := m.ctrl.Call(m, "States")
+	ret0, _ := ret[0].([]CommandState)
 	return ret0
 }
 
-// WithGlobalErrorChecker indicates an expected call of WithGlobalErrorChecker.
-func (mr *MockFinalizedBuilderMockRecorder) WithGlobalErrorChecker(arg0 any) *MockFinalizedBuilderWithGlobalErrorCheckerCall {
+// States indicates an expected call of States.
+func (mr *MockRunningChainMockRecorder) States() *MockRunningChainStatesCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithGlobalErrorChecker", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithGlobalErrorChecker), arg0)
-	return &MockFinalizedBuilderWithGlobalErrorCheckerCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "States", reflect.TypeOf((*MockRunningChain)(nil).States))
+	return &MockRunningChainStatesCall{Call: call}
 }
 
-// MockFinalizedBuilderWithGlobalErrorCheckerCall wrap *gomock.Call
-type MockFinalizedBuilderWithGlobalErrorCheckerCall struct {
+// MockRunningChainStatesCall wrap *gomock.Call
+type MockRunningChainStatesCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFinalizedBuilderWithGlobalErrorCheckerCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithGlobalErrorCheckerCall {
+func (c *MockRunningChainStatesCall) Return(arg0 []CommandState) *MockRunningChainStatesCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFinalizedBuilderWithGlobalErrorCheckerCall) Do(f func(ErrorChecker) FinalizedBuilder) *MockFinalizedBuilderWithGlobalErrorCheckerCall {
+func (c *MockRunningChainStatesCall) Do(f func() []CommandState) *MockRunningChainStatesCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFinalizedBuilderWithGlobalErrorCheckerCall) DoAndReturn(f func(ErrorChecker) FinalizedBuilder) *MockFinalizedBuilderWithGlobalErrorCheckerCall {
+func (c *MockRunningChainStatesCall) DoAndReturn(f func() []CommandState) *MockRunningChainStatesCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// WithOutput mocks base method.
-func (m *MockFinalizedBuilder) WithOutput(targets ...io.Writer) FinalizedBuilder {
+// Wait mocks base method.
+func (m *MockRunningChain) Wait() error {
 	m.ctrl.T.Helper()
-	varargs := []any{}
-	for _, a := range targets {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "WithOutput", varargs...)
-	ret0, _ := ret[0].(FinalizedBuilder)
+	ret := m.ctrl.Call(m, "Wait")
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// WithOutput indicates an expected call of WithOutput.
-func (mr *MockFinalizedBuilderMockRecorder) WithOutput(targets ...any) *MockFinalizedBuilderWithOutputCall {
+// Wait indicates an expected call of Wait.
+func (mr *MockRunningChainMockRecorder) Wait() *MockRunningChainWaitCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithOutput", reflect.TypeOf((*MockFinalizedBuilder)(nil).WithOutput), targets...)
-	return &MockFinalizedBuilderWithOutputCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Wait", reflect.TypeOf((*MockRunningChain)(nil).Wait))
+	return &MockRunningChainWaitCall{Call: call}
 }
 
-// MockFinalizedBuilderWithOutputCall wrap *gomock.Call
-type MockFinalizedBuilderWithOutputCall struct {
+// MockRunningChainWaitCall wrap *gomock.Call
+type MockRunningChainWaitCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockFinalizedBuilderWithOutputCall) Return(arg0 FinalizedBuilder) *MockFinalizedBuilderWithOutputCall {
+func (c *MockRunningChainWaitCall) Return(arg0 error) *MockRunningChainWaitCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockFinalizedBuilderWithOutputCall) Do(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithOutputCall {
+func (c *MockRunningChainWaitCall) Do(f func() error) *MockRunningChainWaitCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockFinalizedBuilderWithOutputCall) DoAndReturn(f func(...io.Writer) FinalizedBuilder) *MockFinalizedBuilderWithOutputCall {
+func (c *MockRunningChainWaitCall) DoAndReturn(f func() error) *MockRunningChainWaitCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
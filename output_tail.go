@@ -0,0 +1,86 @@
+package cmdchain
+
+import (
+	"io"
+	"sync"
+)
+
+// tailWriter is a writer which keeps the last bytes which were written into it. All bytes will be written into the
+// delegate (if there is one) too.
+type tailWriter struct {
+	mutex    sync.Mutex
+	delegate io.Writer
+	size     int
+	buf      []byte
+}
+
+func (t *tailWriter) Write(p []byte) (int, error) {
+	t.mutex.Lock()
+	if len(p) >= t.size {
+		t.buf = append(t.buf[:0], p[len(p)-t.size:]...)
+	} else {
+		t.buf = append(t.buf, p...)
+		if len(t.buf) > t.size {
+			t.buf = append(t.buf[:0], t.buf[len(t.buf)-t.size:]...)
+		}
+	}
+	t.mutex.Unlock()
+
+	if t.delegate == nil {
+		return len(p), nil
+	}
+	return t.delegate.Write(p)
+}
+
+// Bytes returns a copy of the captured bytes.
+func (t *tailWriter) Bytes() []byte {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return append([]byte(nil), t.buf...)
+}
+
+// capturedOutput returns the captured bytes of the given stream of a command (see FinalizedBuilder.WithStdoutTail
+// and FinalizedBuilder.WithStderrTail). If the stream was not captured, false will be returned.
+func capturedOutput(stream io.Writer) ([]byte, bool) {
+	if tail, ok := stream.(*tailWriter); ok {
+		return tail.Bytes(), true
+	}
+	return nil, false
+}
+
+// captureOutputTails redirects the stdout and stderr of the given command additionally into a tailWriter (if it is
+// enabled, see FinalizedBuilder.WithStdoutTail and FinalizedBuilder.WithStderrTail). Pipes into the next command are
+// not touched.
+func (r *runningChain) captureOutputTails(cmdIndex int) {
+	c := r.chain
+	cmdDescriptor := c.cmdDescriptors[cmdIndex]
+	command := cmdDescriptor.command
+
+	outSize, errSize := c.stdoutTail, c.stderrTail
+	if !c.isPipelineEnd(cmdIndex) {
+		if cmdDescriptor.outToIn {
+			outSize = 0
+		}
+		if cmdDescriptor.errToIn {
+			errSize = 0
+		}
+	}
+
+	if command.Stdout != nil && sameWriter(command.Stdout, command.Stderr) {
+		// both streams must stay the same writer, otherwise the order of the output is not guaranteed anymore
+		if size := max(outSize, errSize); size > 0 {
+			tail := &tailWriter{delegate: command.Stdout, size: size}
+			command.Stdout = tail
+			command.Stderr = tail
+		}
+		return
+	}
+
+	if outSize > 0 {
+		command.Stdout = &tailWriter{delegate: command.Stdout, size: outSize}
+	}
+	if errSize > 0 {
+		command.Stderr = &tailWriter{delegate: command.Stderr, size: errSize}
+	}
+}
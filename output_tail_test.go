@@ -0,0 +1,57 @@
+package cmdchain
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTailWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		expect string
+	}{
+		{"nothing", nil, ""},
+		{"smaller than size", []string{"ab"}, "ab"},
+		{"exact size", []string{"abcd"}, "abcd"},
+		{"larger than size", []string{"abcdef"}, "cdef"},
+		{"multiple writes", []string{"ab", "cd", "ef"}, "cdef"},
+		{"large write after small one", []string{"ab", "cdefgh"}, "efgh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delegate := &bytes.Buffer{}
+			toTest := &tailWriter{delegate: delegate, size: 4}
+
+			for _, write := range tt.writes {
+				n, err := toTest.Write([]byte(write))
+				assert.NoError(t, err)
+				assert.Equal(t, len(write), n)
+			}
+
+			assert.Equal(t, tt.expect, string(toTest.Bytes()))
+
+			captured, ok := capturedOutput(toTest)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expect, string(captured))
+		})
+	}
+}
+
+func TestTailWriter_withoutDelegate(t *testing.T) {
+	toTest := &tailWriter{size: 4}
+
+	n, err := toTest.Write([]byte("abcdef"))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.Equal(t, "cdef", string(toTest.Bytes()))
+}
+
+func TestCapturedOutput_notCaptured(t *testing.T) {
+	captured, ok := capturedOutput(&bytes.Buffer{})
+
+	assert.False(t, ok)
+	assert.Nil(t, captured)
+}
@@ -0,0 +1,121 @@
+package cmdchain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// processSubstitution connects a side chain with a command through a pipe (e.g. `diff <(sort a) <(sort b)`).
+// The command can access the pipe by the path /dev/fd/N (N is the file descriptor of the pipe inside the
+// command's process). The side chain will be started right before the command is started.
+type processSubstitution struct {
+	chain *chain
+
+	// output is true if the command writes into the side chain's input (>(...)). Otherwise, the command
+	// reads the side chain's output (<(...)).
+	output bool
+
+	// extraFile is the index of the pipe inside the command's exec.Cmd.ExtraFiles
+	extraFile int
+
+	reader *os.File
+	writer *os.File
+
+	// err is the error of the side chain. It will be merged into the command's stream errors after the side chain
+	// is done (see mergeSubstitutionErrors).
+	err error
+}
+
+func (p *processSubstitution) path() string {
+	// the first three file descriptors are stdin, stdout and stderr
+	return fmt.Sprintf("/dev/fd/%d", 3+p.extraFile)
+}
+
+// applier returns a CommandApplier which will create the pipe and start the side chain.
+func (p *processSubstitution) applier(parent *chain) CommandApplier {
+	return func(index int, command *exec.Cmd) {
+		var err error
+		p.reader, p.writer, err = os.Pipe()
+		if err != nil {
+			parent.setStreamError(index, fmt.Errorf("unable to create pipe for process substitution: %w", err))
+			return
+		}
+
+		// the command's end of the pipe
+		commandFile, sideFile := p.reader, p.writer
+		if p.output {
+			commandFile, sideFile = p.writer, p.reader
+		}
+
+		for len(command.ExtraFiles) <= p.extraFile {
+			command.ExtraFiles = append(command.ExtraFiles, nil)
+		}
+		command.ExtraFiles[p.extraFile] = commandFile
+
+		// the command's end must be closed after the command is started. Otherwise, the side chain would
+		// never be notified that the command has closed its end of the pipe.
+		parent.cmdDescriptors[index].closeAfterStart = append(parent.cmdDescriptors[index].closeAfterStart, commandFile)
+
+		// such as the commands of a group, the side chain inherits the working directory and environment
+		p.chain.inherit(command)
+
+		var finalized FinalizedBuilder
+		if p.output {
+			finalized = p.chain.WithInput(sideFile).Finalize()
+		} else {
+			finalized = p.chain.Finalize().WithOutput(sideFile)
+		}
+		if parent.runCtx != nil {
+			// the side chain will be canceled together with its parent
+			finalized = finalized.WithContext(parent.runCtx).WithCancelSignal(parent.cancelSignal, parent.cancelGracePeriod)
+		}
+
+		parent.streamRoutinesWg.Add(1)
+		go func(sideFile io.Closer) {
+			defer parent.streamRoutinesWg.Done()
+
+			err := finalized.Run()
+			_ = sideFile.Close()
+
+			if err != nil {
+				p.err = fmt.Errorf("process substitution failed: %w", err)
+			}
+		}(sideFile)
+	}
+}
+
+func (p *processSubstitution) BeforeRun() {
+	// the pipe will be created right before the command is started
+	p.err = nil
+}
+
+func (p *processSubstitution) AfterRun() {
+	// the pipe's ends could be left open (e.g. if the command could not be started)
+	if p.reader != nil {
+		_ = p.reader.Close()
+		p.reader = nil
+	}
+	if p.writer != nil {
+		_ = p.writer.Close()
+		p.writer = nil
+	}
+}
+
+// mergeSubstitutionErrors merges the errors of the process substitutions into the stream errors of their commands.
+// It must be called after all side chains are done (see streamRoutinesWg).
+func (c *chain) mergeSubstitutionErrors() {
+	c.streamErrorsMutex.Lock()
+	defer c.streamErrorsMutex.Unlock()
+
+	for _, h := range c.hooks {
+		substitution, isSubstitution := h.hook.(*processSubstitution)
+		if !isSubstitution || substitution.err == nil {
+			continue
+		}
+
+		c.streamErrors.setError(h.cmdIndex, errors.Join(c.streamErrors.errors[h.cmdIndex], substitution.err))
+	}
+}
@@ -0,0 +1,90 @@
+package cmdchain
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// errReaderClosed is the cause of the chain's cancellation if its reader is closed before the chain is done (see
+// FinalizedBuilder.StartReader).
+var errReaderClosed = errors.New("reader closed")
+
+// chainReader reads the output of a running chain (see FinalizedBuilder.StartReader).
+type chainReader struct {
+	*io.PipeReader
+
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+	err    error
+}
+
+func (c *finalizedChain) StartReader() io.ReadCloser {
+	pipeReader, pipeWriter := io.Pipe()
+
+	r := &finalizedChain{c.runnable()}
+
+	// the chain will be canceled if the reader is closed early. Such as the output stream, this context is only
+	// relevant for this run - so it is given to its instance only
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	r.WithAdditionalOutput(pipeWriter).WithContext(ctx)
+
+	reader := &chainReader{PipeReader: pipeReader, cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(reader.done)
+		defer cancel(nil)
+
+		reader.err = r.run()
+
+		// the reader will get the chain's error (or io.EOF) after all output is read
+		_ = pipeWriter.CloseWithError(reader.err)
+	}()
+
+	return reader
+}
+
+func (r *chainReader) Close() error {
+	select {
+	case <-r.done:
+		_ = r.PipeReader.Close()
+		return r.err
+	default:
+	}
+
+	// the chain is still running. The pipe must be closed at first, so that the last command will not wait for
+	// eternity while writing its output. After that, the chain will be terminated such as it would be canceled
+	_ = r.PipeReader.Close()
+	r.cancel(errReaderClosed)
+	<-r.done
+
+	return withoutTermination(r.err)
+}
+
+// withoutTermination removes all errors which are caused by the termination of the chain after its reader was closed
+// early: the errors of the canceled commands and of the writes into the closed reader. All errors which have occurred
+// before (e.g. a command which has already exited unsuccessfully) are kept.
+func withoutTermination(err error) error {
+	if mError, ok := err.(MultipleErrors); ok {
+		filtered := mError
+		filtered.errors = make([]error, 0, len(mError.errors))
+		filtered.hasError = false
+
+		for _, nested := range mError.errors {
+			filtered.addError(withoutTermination(nested))
+		}
+		return filtered.orNil()
+	}
+
+	if canceledErr, ok := err.(*CanceledError); ok && canceledErr.Cause == errReaderClosed {
+		return nil
+	}
+	if errors.Is(err, io.ErrClosedPipe) {
+		return nil
+	}
+	return err
+}
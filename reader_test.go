@@ -0,0 +1,134 @@
+package cmdchain
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartReader(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	reader := Builder().
+		Join("echo", "hello world").
+		Join("tr", "a-z", "A-Z").
+		Finalize().WithOutput(output).StartReader()
+
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.NoError(t, reader.Close())
+
+	assert.Equal(t, "HELLO WORLD\n", string(content))
+	assert.Equal(t, "HELLO WORLD\n", output.String())
+}
+
+func TestStartReader_decoder(t *testing.T) {
+	reader := Builder().
+		Join("echo", `{"name": "value"}`).
+		Finalize().StartReader()
+	defer reader.Close()
+
+	var result map[string]string
+	require.NoError(t, json.NewDecoder(reader).Decode(&result))
+	assert.Equal(t, map[string]string{"name": "value"}, result)
+}
+
+func TestStartReader_failing(t *testing.T) {
+	reader := Builder().
+		Join("echo", "hello world").
+		Join(testHelper, "-x", "1").
+		Finalize().StartReader()
+
+	_, err := io.ReadAll(reader)
+	assert.ErrorIs(t, err, ErrRun)
+
+	var cmdErr *CommandError
+	assert.ErrorAs(t, err, &cmdErr)
+	assert.ErrorIs(t, reader.Close(), ErrRun)
+}
+
+func TestStartReader_invalid(t *testing.T) {
+	reader := Builder().
+		Join("echo", "hello world").DiscardStdOut().
+		Join("grep", "hello").
+		Finalize().StartReader()
+
+	_, err := io.ReadAll(reader)
+	assert.ErrorIs(t, err, ErrBuild)
+	assert.ErrorIs(t, reader.Close(), ErrBuild)
+}
+
+func TestStartReader_closeEarly(t *testing.T) {
+	tests := []struct {
+		name   string
+		toTest FinalizedBuilder
+	}{
+		{"command", Builder().Join("yes").Finalize()},
+		{"function", Builder().Join("yes").JoinLineMapper(strings.ToUpper).Finalize()},
+		{"waiting", Builder().JoinFunc(func(ctx context.Context, _ io.Reader, stdout, _ io.Writer) error {
+			_, _ = io.WriteString(stdout, "y\n")
+			<-ctx.Done()
+			return ctx.Err()
+		}).Finalize()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := tt.toTest.StartReader()
+
+			line, err := bufio.NewReader(reader).ReadString('\n')
+			require.NoError(t, err)
+			assert.Equal(t, "y\n", strings.ToLower(line))
+
+			start := time.Now()
+			assert.NoError(t, reader.Close())
+			assert.Less(t, time.Since(start), 5*time.Second)
+
+			_, err = reader.Read(make([]byte, 1))
+			assert.ErrorIs(t, err, io.ErrClosedPipe)
+		})
+	}
+}
+
+func TestStartReader_closeEarlyAfterFailure(t *testing.T) {
+	reader := Builder().
+		JoinShellCmd("false; yes").
+		Finalize().StartReader()
+
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "y\n", line)
+
+	err = reader.Close()
+	assert.ErrorIs(t, err, ErrRun, "the error of the first statement must be kept")
+
+	var cmdErr *CommandError
+	assert.ErrorAs(t, err, &cmdErr)
+	assert.Equal(t, 1, cmdErr.ExitCode)
+
+	var canceledErr *CanceledError
+	assert.False(t, errors.As(err, &canceledErr), "the errors of the termination must be dropped")
+}
+
+func TestStartReader_multipleTimes(t *testing.T) {
+	toTest := Builder().
+		Join("echo", "hello world").
+		Finalize()
+
+	for i := 0; i < 2; i++ {
+		reader := toTest.StartReader()
+
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "hello world\n", string(content))
+		assert.NoError(t, reader.Close())
+	}
+}
@@ -0,0 +1,138 @@
+package cmdchain
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+)
+
+// ScanNull is a split function (see bufio.SplitFunc) which splits the data into records which are terminated by a
+// NUL character (e.g. the output of `find -print0`). The last record may be unterminated.
+func ScanNull(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+
+	// request more data
+	return 0, nil, nil
+}
+
+// sendRecords splits the content of the given reader into records and sends them into the given channel. It returns
+// if the reader is at its end or the given context is done.
+func sendRecords(ctx context.Context, reader io.Reader, split bufio.SplitFunc, records chan<- string) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, maxLineLength)
+	scanner.Split(split)
+
+	for scanner.Scan() {
+		select {
+		case records <- scanner.Text():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (c *finalizedChain) RunLines(ctx context.Context) (<-chan string, <-chan error) {
+	return c.RunRecords(ctx, bufio.ScanLines)
+}
+
+func (c *finalizedChain) RunRecords(ctx context.Context, split bufio.SplitFunc) (<-chan string, <-chan error) {
+	records := make(chan string)
+	errs := make(chan error, 1)
+
+	reader := c.StartReader()
+
+	// the chain will be terminated if the consumer is gone
+	stop := context.AfterFunc(ctx, func() { _ = reader.Close() })
+
+	go func() {
+		defer close(errs)
+		defer close(records)
+
+		err := sendRecords(ctx, reader, split, records)
+		if stop() {
+			// the reader returns the error of the chain
+			if closeErr := reader.Close(); err == nil {
+				err = closeErr
+			}
+		} else {
+			// the chain was terminated because of the context
+			err = ctx.Err()
+		}
+
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return records, errs
+}
+
+// RecordWriter is a writer which splits the written data into records and sends them into a channel (see Records).
+// So it can be used to consume the output of commands incrementally (e.g. as fork of a command, see
+// CommandBuilder.WithOutputForks). The channel is unbuffered: each write will be blocked until its records are
+// received. The writer must be closed after all data is written (e.g. after the chain is done). Otherwise, the
+// channel will never be closed.
+type RecordWriter struct {
+	writer  *io.PipeWriter
+	records chan string
+	errs    chan error
+}
+
+// NewRecordWriter creates a new RecordWriter which splits the written data by the given split function (e.g.
+// bufio.ScanLines or ScanNull). If the given context is done, no further records will be sent. But the written data
+// will still be accepted (and discarded). So the chain will not be affected by a gone consumer.
+func NewRecordWriter(ctx context.Context, split bufio.SplitFunc) *RecordWriter {
+	reader, writer := io.Pipe()
+
+	w := &RecordWriter{
+		writer:  writer,
+		records: make(chan string),
+		errs:    make(chan error, 1),
+	}
+
+	go func() {
+		defer close(w.errs)
+		defer close(w.records)
+
+		err := sendRecords(ctx, reader, split, w.records)
+		if err != nil {
+			w.errs <- err
+		}
+
+		// the writer must not be blocked (or failed) if the records are not sent anymore
+		_, _ = io.Copy(io.Discard, reader)
+	}()
+
+	return w
+}
+
+func (w *RecordWriter) Write(p []byte) (int, error) {
+	return w.writer.Write(p)
+}
+
+// Close closes the writer. The last (unterminated) record will be sent and the channels will be closed after that.
+func (w *RecordWriter) Close() error {
+	return w.writer.Close()
+}
+
+// Records returns the channel of the records. It will be closed after the writer is closed.
+func (w *RecordWriter) Records() <-chan string {
+	return w.records
+}
+
+// Errors returns a channel which will receive the error (if any) which has occurred while splitting the data into
+// records (e.g. bufio.ErrTooLong) or the error of the given context. It will be closed after the writer is closed.
+func (w *RecordWriter) Errors() <-chan error {
+	return w.errs
+}
@@ -0,0 +1,136 @@
+package cmdchain
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func collect(records <-chan string, errs <-chan error) ([]string, error) {
+	var result []string
+	for record := range records {
+		result = append(result, record)
+	}
+	return result, <-errs
+}
+
+func TestRunLines(t *testing.T) {
+	lines, errs := Builder().
+		Join("printf", "first\nsecond\nthird").
+		Finalize().RunLines(t.Context())
+
+	result, err := collect(lines, errs)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "third"}, result)
+}
+
+func TestRunLines_failing(t *testing.T) {
+	lines, errs := Builder().
+		Join("echo", "first").
+		Join(testHelper, "-o", "second", "-x", "1").
+		Finalize().RunLines(t.Context())
+
+	result, err := collect(lines, errs)
+	assert.ErrorIs(t, err, ErrRun)
+	assert.Equal(t, []string{"second"}, result)
+}
+
+func TestRunLines_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+
+	lines, errs := Builder().
+		Join("yes").
+		Finalize().RunLines(ctx)
+
+	assert.Equal(t, "y", <-lines)
+	cancel()
+
+	_, err := collect(lines, errs)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRunRecords(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		split  bufio.SplitFunc
+		expect []string
+	}{
+		{"words", "hello  world\nagain", bufio.ScanWords, []string{"hello", "world", "again"}},
+		{"null", "first\x00second line\x00", ScanNull, []string{"first", "second line"}},
+		{"null unterminated", "first\x00second", ScanNull, []string{"first", "second"}},
+		{"null empty records", "\x00\x00", ScanNull, []string{"", ""}},
+		{"nothing", "", ScanNull, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, errs := Builder().
+				Join("printf", strings.ReplaceAll(tt.output, "\x00", `\000`)).
+				Finalize().RunRecords(t.Context(), tt.split)
+
+			result, err := collect(records, errs)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, result)
+		})
+	}
+}
+
+func TestRecordWriter(t *testing.T) {
+	forkLines := NewRecordWriter(t.Context(), bufio.ScanLines)
+
+	var result []string
+	var forkErr error
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		result, forkErr = collect(forkLines.Records(), forkLines.Errors())
+	}()
+
+	sOut, _, err := Builder().
+		Join("printf", "first\nsecond\n").WithOutputForks(forkLines).
+		Join("wc", "-l").
+		Finalize().RunAndGet()
+	require.NoError(t, err)
+	require.NoError(t, forkLines.Close())
+	wg.Wait()
+
+	assert.Equal(t, "2\n", sOut)
+	assert.NoError(t, forkErr)
+	assert.Equal(t, []string{"first", "second"}, result)
+}
+
+func TestRecordWriter_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	forkLines := NewRecordWriter(ctx, bufio.ScanLines)
+
+	// the chain must not be affected by the gone consumer
+	sOut, _, err := Builder().
+		Join("seq", "1000").WithOutputForks(forkLines).
+		Join("wc", "-l").
+		Finalize().RunAndGet()
+	require.NoError(t, err)
+	require.NoError(t, forkLines.Close())
+
+	assert.Equal(t, "1000\n", sOut)
+	_, forkErr := collect(forkLines.Records(), forkLines.Errors())
+	assert.ErrorIs(t, forkErr, context.Canceled)
+}
+
+func TestRecordWriter_tooLong(t *testing.T) {
+	forkLines := NewRecordWriter(t.Context(), bufio.ScanLines)
+
+	_, err := forkLines.Write([]byte(strings.Repeat("a", maxLineLength+1)))
+	assert.NoError(t, err)
+	assert.NoError(t, forkLines.Close())
+
+	_, forkErr := collect(forkLines.Records(), forkLines.Errors())
+	assert.ErrorIs(t, forkErr, bufio.ErrTooLong)
+}
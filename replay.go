@@ -0,0 +1,206 @@
+package cmdchain
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+)
+
+// runnable returns the chain which should be run. A template will never be run itself. Instead, each run will use
+// its own instance (see instance).
+func (c *chain) runnable() *chain {
+	if !c.template {
+		return c
+	}
+
+	return c.instance()
+}
+
+// instance returns a new chain for a single run of the template. Because a command can not be started twice, each
+// run needs its own commands and streams. The first run will get the original commands (e.g. the ones which are
+// given to JoinCmd) and each further run will get copies of them. The shell commands will be parsed again, so their
+// stages, here-documents and redirections belong to a single run too. After all commands are joined, the streams of
+// the instance will be linked and finalized such as the template was configured.
+func (c *chain) instance() *chain {
+	c.runMutex.Lock()
+	defer c.runMutex.Unlock()
+
+	i := &chain{
+		inputs:            c.inputs,
+		variables:         c.variables,
+		globPolicy:        c.globPolicy,
+		builtins:          c.builtins,
+		buildErrors:       buildErrors(),
+		streamErrors:      streamErrors(),
+		errorChecker:      c.errorChecker,
+		resultPolicy:      c.resultPolicy,
+		stdoutTail:        c.stdoutTail,
+		stderrTail:        c.stderrTail,
+		ctx:               c.ctx,
+		cancelSignal:      c.cancelSignal,
+		cancelGracePeriod: c.cancelGracePeriod,
+		timeout:           c.timeout,
+	}
+
+	for cmdIndex, cmdDesc := range c.cmdDescriptors {
+		if cmdDesc.contextLost && !c.buildErrors.hasError {
+			c.buildErrors.addError(fmt.Errorf(
+				"command %d can not be run again: its context can not be passed to a copy - use JoinWithContext instead",
+				cmdIndex,
+			))
+		}
+	}
+	if c.buildErrors.hasError {
+		// the instance will never be started
+		i.buildErrors = c.buildErrors
+		return i
+	}
+
+	sequences := c.outerSequences()
+	beginSequences := func(cmdIndex int) {
+		for len(sequences) > 0 && sequences[0].start == cmdIndex {
+			i.sequences = append(i.sequences, sequences[0])
+			sequences = sequences[1:]
+		}
+	}
+
+	shellCommands := c.shellCommands
+	for cmdIndex := 0; cmdIndex < len(c.cmdDescriptors); cmdIndex++ {
+		beginSequences(cmdIndex)
+
+		if len(shellCommands) > 0 && shellCommands[0].from == cmdIndex {
+			joined := shellCommands[0]
+			shellCommands = shellCommands[1:]
+
+			(&shellChain{ctx: joined.ctx, command: joined.command, actions: joined.actions, chain: i}).build()
+			cmdIndex = joined.to - 1
+			continue
+		}
+
+		cmdDesc := c.cmdDescriptors[cmdIndex]
+		i.joinDescriptor(cmdDescriptor{
+			command:        c.nextCommand(cmdIndex),
+			outToIn:        cmdDesc.outToIn,
+			errToIn:        cmdDesc.errToIn,
+			outFork:        cmdDesc.outFork,
+			errFork:        cmdDesc.errFork,
+			commandApplier: slices.Clone(cmdDesc.commandApplier),
+			errorChecker:   cmdDesc.errorChecker,
+			preparers:      slices.Clone(cmdDesc.preparers),
+			stage:          copyStage(cmdDesc.stage),
+			ctx:            cmdDesc.ctx,
+			timeout:        cmdDesc.timeout,
+			outputStreams:  slices.Clone(cmdDesc.outputStreams),
+			errorStreams:   slices.Clone(cmdDesc.errorStreams),
+		})
+		i.WithInjections(cmdDesc.inputStreams...)
+	}
+	beginSequences(len(c.cmdDescriptors))
+
+	finalized := i.Finalize().(*finalizedChain)
+	if c.replaceOutputs {
+		finalized.WithOutput(c.outputTargets...)
+	} else if len(c.outputTargets) > 0 {
+		finalized.WithAdditionalOutput(c.outputTargets...)
+	}
+	if c.replaceErrors {
+		finalized.WithError(c.errorTargets...)
+	} else if len(c.errorTargets) > 0 {
+		finalized.WithAdditionalError(c.errorTargets...)
+	}
+
+	return i
+}
+
+// outerSequences returns the sequences of the template which do not belong to a shell command. The sequences of a
+// shell command will be created again while it is parsed.
+func (c *chain) outerSequences() []sequence {
+	return slices.DeleteFunc(slices.Clone(c.sequences), func(seq sequence) bool {
+		for _, joined := range c.shellCommands {
+			if seq.start > joined.from && seq.start < joined.to {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// nextCommand returns the command of the template for the next run. The template keeps a copy of it for the run
+// after.
+func (c *chain) nextCommand(cmdIndex int) *exec.Cmd {
+	cmdDesc := &(c.cmdDescriptors[cmdIndex])
+
+	command := cmdDesc.command
+	cmdDesc.command = cloneCommand(cmdDesc.ctx, command)
+
+	// the context of a command which was created by exec.CommandContext (e.g. given to JoinCmd) is not accessible.
+	// So the copy is not bound to it anymore.
+	cmdDesc.contextLost = cmdDesc.ctx == nil && command.Cancel != nil
+
+	return command
+}
+
+// cloneCommand returns a new command with the same configuration as the given command. It will be bound to the
+// given context (if any).
+func cloneCommand(ctx context.Context, cmd *exec.Cmd) *exec.Cmd {
+	clone := &exec.Cmd{}
+	if ctx != nil {
+		clone = exec.CommandContext(ctx, cmd.Path)
+	}
+
+	clone.Path = cmd.Path
+	clone.Args = slices.Clone(cmd.Args)
+	clone.Env = slices.Clone(cmd.Env)
+	clone.Dir = cmd.Dir
+	clone.Stdin = cmd.Stdin
+	clone.Stdout = cmd.Stdout
+	clone.Stderr = cmd.Stderr
+	clone.ExtraFiles = slices.Clone(cmd.ExtraFiles)
+	clone.SysProcAttr = cmd.SysProcAttr
+	clone.WaitDelay = cmd.WaitDelay
+	clone.Err = cmd.Err
+
+	return clone
+}
+
+// copyStage returns a new stage with the configuration of the given one, because the stages hold the state of their
+// run. Only the stages of commands which are not joined by a shell command must be copied (see instance).
+func copyStage(s stage) stage {
+	switch s := s.(type) {
+	case *builtinStage:
+		return &builtinStage{fn: s.fn, ctx: s.ctx}
+	case *retryStage:
+		return &retryStage{index: s.index, policy: s.policy, ctx: s.ctx}
+	default:
+		return s
+	}
+}
+
+// finalizedDescriptors returns the commands of the template with the streams which they will get for each run (see
+// instance). So the template can be described such as its instances (see String).
+func (c *chain) finalizedDescriptors() []cmdDescriptor {
+	result := slices.Clone(c.cmdDescriptors)
+	if !c.finalized || len(result) == 0 {
+		return result
+	}
+
+	if !result[0].inputRedirected {
+		result[0].inputStreams = append(append([]io.Reader{}, c.inputs...), result[0].inputStreams...)
+	}
+	for _, p := range c.pipelines() {
+		lastCmdDesc := &(result[p.to-1])
+		lastCmdDesc.outputStreams = finalizedStreams(lastCmdDesc.outputStreams, c.outputTargets, c.replaceOutputs)
+		lastCmdDesc.errorStreams = finalizedStreams(lastCmdDesc.errorStreams, c.errorTargets, c.replaceErrors)
+	}
+
+	return result
+}
+
+func finalizedStreams(streams, targets []io.Writer, replace bool) []io.Writer {
+	if replace {
+		return targets
+	}
+	return append(slices.Clone(streams), targets...)
+}
@@ -0,0 +1,161 @@
+package cmdchain
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRun_multipleTimes(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	toTest := Builder().
+		Join("echo", "hello world").
+		Join("grep", "hello").
+		Finalize().WithOutput(output)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, toTest.Run())
+	}
+
+	assert.Equal(t, strings.Repeat("hello world\n", 3), output.String())
+}
+
+func TestRun_multipleTimes_failing(t *testing.T) {
+	toTest := Builder().
+		Join("sh", "-c", "exit 3").
+		Finalize()
+
+	for i := 0; i < 2; i++ {
+		err := toTest.Run()
+
+		var cmdErr *CommandError
+		require.ErrorAs(t, err, &cmdErr)
+		assert.Equal(t, 3, cmdErr.ExitCode)
+	}
+}
+
+func TestRun_multipleTimes_joinCmd(t *testing.T) {
+	cmd := exec.Command("echo", "hello world")
+	cmd.Env = []string{}
+
+	toTest := Builder().
+		JoinCmd(cmd).
+		Join("wc", "-w").
+		Finalize()
+
+	for i := 0; i < 2; i++ {
+		sOut, _, err := toTest.RunAndGet()
+		require.NoError(t, err)
+		assert.Equal(t, "2", strings.TrimSpace(sOut))
+	}
+}
+
+func TestRun_multipleTimes_joinCmdWithContext(t *testing.T) {
+	cmd := exec.CommandContext(t.Context(), "echo", "hello world")
+
+	toTest := Builder().
+		JoinCmd(cmd).
+		Finalize()
+
+	require.NoError(t, toTest.Run())
+	assert.NotNil(t, cmd.ProcessState, "the first run should use the given command")
+
+	err := toTest.Run()
+	assert.ErrorIs(t, err, ErrBuild)
+	assert.ErrorContains(t, err, "command 0 can not be run again")
+}
+
+func TestRun_multipleTimes_joinWithContext(t *testing.T) {
+	toTest := Builder().
+		JoinWithContext(t.Context(), "echo", "hello world").
+		Finalize()
+
+	for i := 0; i < 2; i++ {
+		sOut, _, err := toTest.RunAndGet()
+		require.NoError(t, err)
+		assert.Equal(t, "hello world\n", sOut)
+	}
+}
+
+func TestRun_multipleTimes_mixed(t *testing.T) {
+	toTest := Builder().
+		Join("echo", "hello").WithInjections(strings.NewReader("")).
+		JoinShellCmd(`cat; cat <<< world`).
+		JoinLineMapper(strings.ToUpper).
+		Finalize()
+
+	for i := 0; i < 2; i++ {
+		sOut, _, err := toTest.RunAndGet()
+		require.NoError(t, err)
+		assert.Equal(t, "hello\nWORLD\n", sOut)
+	}
+}
+
+func TestRun_multipleTimes_shellCommand(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "out.txt")
+
+	toTest := Builder().
+		WithVariables(map[string]string{"NAME": "world"}).
+		JoinShellCmd(`cat <<EOF | tr a-z A-Z >> ` + target + `
+hello $NAME
+EOF`).
+		Finalize()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, toTest.Run())
+	}
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO WORLD\nHELLO WORLD\n", string(content))
+}
+
+func TestRunAndGet_multipleTimes(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	toTest := Builder().
+		Join("echo", "hello world").
+		Finalize().WithOutput(output)
+
+	for i := 0; i < 2; i++ {
+		sOut, sErr, err := toTest.RunAndGet()
+		require.NoError(t, err)
+
+		// the streams of previous runs must not be reused
+		assert.Equal(t, "hello world\n", sOut)
+		assert.Empty(t, sErr)
+	}
+	assert.Equal(t, "hello world\nhello world\n", output.String())
+}
+
+func TestStart_concurrently(t *testing.T) {
+	toTest := Builder().
+		Join("echo", "hello world").
+		Join("wc", "-c").
+		Finalize()
+
+	wg := sync.WaitGroup{}
+	outputs := make([]string, 5)
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			sOut, _, err := toTest.RunAndGet()
+			assert.NoError(t, err)
+			outputs[i] = strings.TrimSpace(sOut)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, output := range outputs {
+		assert.Equal(t, "12", output)
+	}
+}
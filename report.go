@@ -0,0 +1,229 @@
+package cmdchain
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// RunReport contains the details of all commands of an executed chain (see FinalizedBuilder.RunWithReport and
+// RunningChain.Report).
+type RunReport struct {
+	// Commands contains the reports of all commands of the chain (same order as the commands are joined).
+	Commands []CommandReport
+}
+
+// CommandReport contains the details of a single command of an executed chain.
+type CommandReport struct {
+	CommandState
+
+	// Path is the path of the command.
+	Path string
+
+	// Args are the arguments of the command (including the command's name).
+	Args []string
+
+	// Attempts is the number of started attempts of the command (see CommandBuilder.WithRetry). It is 0 if the
+	// command was not started.
+	Attempts int
+
+	// Signal is the signal which has terminated the command. It is nil if the command was not terminated
+	// by a signal.
+	Signal os.Signal
+
+	// StartTime is the time when the command was started. It is zero if the command was not started.
+	StartTime time.Time
+
+	// EndTime is the time when the command was exited. It is zero if the command is not exited yet.
+	EndTime time.Time
+
+	// Duration is the wall time of the command.
+	Duration time.Duration
+
+	// UserTime is the user CPU time of the command's process.
+	UserTime time.Duration
+
+	// SystemTime is the system CPU time of the command's process.
+	SystemTime time.Duration
+
+	// MaxRSS is the maximum resident set size of the command's process in bytes. It is -1 if it is unknown (e.g. the
+	// platform does not support it or the command is not executed as its own process).
+	MaxRSS int64
+
+	// StdinBytes is the number of bytes which were read from the command's stdin. StdoutBytes and StderrBytes are
+	// the number of bytes which were written into the command's stdout and stderr. For a pipe between two commands,
+	// the stdout (or stderr) of the writing command and the stdin of the reading command contain the number of bytes
+	// which were passed through the pipe. They are -1 if the stream is a file (e.g. a redirection), which is passed
+	// directly to the command's process and can not be counted. They are -1 too, if the chain was neither started by
+	// FinalizedBuilder.Start nor FinalizedBuilder.RunWithReport. If stdout and stderr are the same stream (e.g. `2>&1`),
+	// both contain the number of bytes of both streams.
+	StdinBytes  int64
+	StdoutBytes int64
+	StderrBytes int64
+}
+
+// streamCounters contains the number of bytes of each stream of a command.
+type streamCounters struct {
+	stdin  *atomic.Int64
+	stdout *atomic.Int64
+	stderr *atomic.Int64
+}
+
+// countStreams wraps the streams of the given command, so the number of their bytes can be counted without changing
+// their behaviour. Files are passed directly to the command's process, so they can not be counted. Except the pipe
+// from the previous command: its reading end will be wrapped and the counter is shared with the previous command. The
+// streams are only counted if a report is requested (see Start).
+func (r *runningChain) countStreams(cmdIndex int) {
+	if !r.withReport {
+		return
+	}
+
+	c := r.chain
+	cmdDesc := c.cmdDescriptors[cmdIndex]
+	command := cmdDesc.command
+	s := &r.streams[cmdIndex]
+
+	if command.Stdin != nil {
+		piped := !c.isPipelineStart(cmdIndex) && !cmdDesc.inputRedirected
+		if _, isFile := command.Stdin.(*os.File); !isFile || piped {
+			s.stdin = &atomic.Int64{}
+			command.Stdin = &countingReader{delegate: command.Stdin, counter: s.stdin}
+		}
+		if piped {
+			// the previous command is already started - but its streams are not counted yet
+			prevCmdDesc := c.cmdDescriptors[cmdIndex-1]
+			if prevCmdDesc.outToIn && !prevCmdDesc.errToIn {
+				r.streams[cmdIndex-1].stdout = s.stdin
+			} else if prevCmdDesc.errToIn && !prevCmdDesc.outToIn {
+				r.streams[cmdIndex-1].stderr = s.stdin
+			}
+		}
+	}
+	if command.Stdout != nil {
+		if _, isFile := command.Stdout.(*os.File); !isFile {
+			s.stdout = &atomic.Int64{}
+			command.Stdout = &countingWriter{delegate: command.Stdout, counter: s.stdout}
+		}
+	}
+	if command.Stderr != nil {
+		if _, isFile := command.Stderr.(*os.File); !isFile {
+			if s.stdout != nil && sameWriter(command.Stderr, command.Stdout.(*countingWriter).delegate) {
+				// both streams must be the same writer, otherwise the command will get two different pipes
+				// and the order of the output is not guaranteed anymore
+				s.stderr = s.stdout
+				command.Stderr = command.Stdout
+			} else {
+				s.stderr = &atomic.Int64{}
+				command.Stderr = &countingWriter{delegate: command.Stderr, counter: s.stderr}
+			}
+		}
+	}
+}
+
+// sameWriter checks if both writers are the same. Writers which can not be compared (e.g. a struct which contains a
+// slice) are never the same.
+func sameWriter(w1, w2 io.Writer) bool {
+	if w1 == nil || w2 == nil || !reflect.ValueOf(w1).Comparable() {
+		return false
+	}
+	return w1 == w2
+}
+
+func countOf(counter *atomic.Int64) int64 {
+	if counter == nil {
+		return -1
+	}
+	return counter.Load()
+}
+
+// countingReader counts the bytes which are read from the delegate. It must be closable such as the delegate
+// (see FinalizedBuilder.Run). The delegate is still accessible (see runningChain.inputClosed).
+type countingReader struct {
+	delegate io.Reader
+	counter  *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.delegate.Read(p)
+	c.counter.Add(int64(n))
+	return n, err
+}
+
+func (c *countingReader) Close() error {
+	if closer, isCloser := c.delegate.(io.Closer); isCloser {
+		return closer.Close()
+	}
+	return nil
+}
+
+// countingWriter counts the bytes which are written into the delegate.
+type countingWriter struct {
+	delegate io.Writer
+	counter  *atomic.Int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.delegate.Write(p)
+	c.counter.Add(int64(n))
+	return n, err
+}
+
+func (r *runningChain) Report() RunReport {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	report := RunReport{Commands: make([]CommandReport, len(r.states))}
+	for i, state := range r.states {
+		command := r.chain.cmdDescriptors[i].command
+
+		cmdReport := CommandReport{
+			CommandState: state,
+			Path:         command.Path,
+			Args:         slices.Clone(command.Args),
+			StartTime:    r.startTimes[i],
+			EndTime:      r.endTimes[i],
+			MaxRSS:       -1,
+			StdinBytes:   countOf(r.streams[i].stdin),
+			StdoutBytes:  countOf(r.streams[i].stdout),
+			StderrBytes:  countOf(r.streams[i].stderr),
+		}
+		if !cmdReport.EndTime.IsZero() {
+			cmdReport.Duration = cmdReport.EndTime.Sub(cmdReport.StartTime)
+		}
+
+		if retry, isRetry := r.chain.cmdDescriptors[i].stage.(*retryStage); isRetry {
+			cmdReport.Attempts = retry.attemptCount()
+		} else if state.Status == CommandRunning || state.Status == CommandExited {
+			cmdReport.Attempts = 1
+		}
+
+		// the process state is only available for exited processes (not for builtins or groups of shell commands)
+		if state.Status == CommandExited && command.ProcessState != nil {
+			cmdReport.UserTime = command.ProcessState.UserTime()
+			cmdReport.SystemTime = command.ProcessState.SystemTime()
+			cmdReport.MaxRSS = maxRSS(command.ProcessState)
+
+			if status, ok := command.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				cmdReport.Signal = status.Signal()
+			}
+		}
+
+		report.Commands[i] = cmdReport
+	}
+
+	return report
+}
+
+func (c *finalizedChain) RunWithReport() (RunReport, error) {
+	running, err := c.Start()
+	if err != nil {
+		return RunReport{}, err
+	}
+
+	err = running.Wait()
+	return running.Report(), err
+}
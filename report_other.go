@@ -0,0 +1,10 @@
+//go:build !unix
+
+package cmdchain
+
+import "os"
+
+// maxRSS returns the maximum resident set size of the given process in bytes (or -1 if it is unknown).
+func maxRSS(*os.ProcessState) int64 {
+	return -1
+}
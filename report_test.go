@@ -0,0 +1,147 @@
+package cmdchain
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestRunWithReport(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	report, err := Builder().
+		WithInput(strings.NewReader("TEST\nOTHER\n")).
+		Join("cat").
+		Join("grep", "TEST").
+		Finalize().WithOutput(output).RunWithReport()
+
+	require.NoError(t, err)
+	require.Len(t, report.Commands, 2)
+	assert.Equal(t, "TEST\n", output.String())
+
+	for i, cmdReport := range report.Commands {
+		assert.Equal(t, i, cmdReport.Index)
+		assert.Equal(t, CommandExited, cmdReport.Status)
+		assert.Equal(t, 0, cmdReport.ExitCode)
+		assert.NotZero(t, cmdReport.Pid)
+		assert.NotEmpty(t, cmdReport.Path)
+		assert.Nil(t, cmdReport.Signal)
+		assert.False(t, cmdReport.StartTime.IsZero())
+		assert.False(t, cmdReport.EndTime.IsZero())
+		assert.Equal(t, cmdReport.EndTime.Sub(cmdReport.StartTime), cmdReport.Duration)
+		assert.Greater(t, cmdReport.MaxRSS, int64(0))
+	}
+
+	assert.Equal(t, []string{"cat"}, report.Commands[0].Args)
+	assert.Equal(t, []string{"grep", "TEST"}, report.Commands[1].Args)
+
+	assert.Equal(t, int64(11), report.Commands[0].StdinBytes)
+	assert.Equal(t, int64(11), report.Commands[0].StdoutBytes, "bytes passed through the pipe")
+	assert.Equal(t, int64(11), report.Commands[1].StdinBytes, "bytes passed through the pipe")
+	assert.Equal(t, int64(5), report.Commands[1].StdoutBytes)
+	assert.Equal(t, int64(-1), report.Commands[1].StderrBytes, "unset stream can not be counted")
+}
+
+func TestRunWithReport_failing(t *testing.T) {
+	errOutput := &bytes.Buffer{}
+
+	report, err := Builder().
+		Join(testHelper, "-e", "ERROR", "-x", "13").
+		Finalize().WithError(errOutput).RunWithReport()
+
+	assert.Error(t, err)
+	require.Len(t, report.Commands, 1)
+	assert.Equal(t, 13, report.Commands[0].ExitCode)
+	assert.Error(t, report.Commands[0].Err)
+	assert.Equal(t, int64(errOutput.Len()), report.Commands[0].StderrBytes)
+}
+
+func TestRunWithReport_skipped(t *testing.T) {
+	report, err := Builder().
+		JoinShellCmd(`false && true`).
+		Finalize().RunWithReport()
+
+	assert.Error(t, err)
+	require.Len(t, report.Commands, 2)
+	assert.Equal(t, CommandSkipped, report.Commands[1].Status)
+	assert.True(t, report.Commands[1].StartTime.IsZero())
+	assert.Zero(t, report.Commands[1].Duration)
+}
+
+func TestRunWithReport_invalidCommand(t *testing.T) {
+	report, err := Builder().
+		Join("invalidApplication").
+		Finalize().RunWithReport()
+
+	assert.Error(t, err)
+	assert.Empty(t, report.Commands)
+}
+
+func TestRunningChain_Report_killed(t *testing.T) {
+	running, err := Builder().
+		Join("sleep", "10").
+		Finalize().Start()
+	require.NoError(t, err)
+
+	require.NoError(t, running.Kill())
+	assert.Error(t, running.Wait())
+
+	report := running.Report()
+	require.Len(t, report.Commands, 1)
+	assert.Equal(t, syscall.SIGKILL, report.Commands[0].Signal)
+	assert.Equal(t, -1, report.Commands[0].ExitCode)
+}
+
+func TestRunWithReport_pipeIntoShortReader(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	report, err := Builder().
+		Join("yes", "TEST").
+		Join("head", "-1").
+		Finalize().WithOutput(output).RunWithReport()
+
+	require.NoError(t, err, "broken pipe of the first command must be ignored")
+	require.Len(t, report.Commands, 2)
+	assert.Equal(t, "TEST\n", output.String())
+	assert.True(t, report.Commands[1].InputClosedEarly)
+	assert.Greater(t, report.Commands[0].StdoutBytes, int64(0))
+	assert.Equal(t, report.Commands[0].StdoutBytes, report.Commands[1].StdinBytes)
+}
+
+func TestRun_streamsAreNotCounted(t *testing.T) {
+	toTest := Builder().
+		WithInput(strings.NewReader("TEST\n")).
+		Join("cat").
+		Join("grep", "TEST").
+		Finalize().WithOutput(&bytes.Buffer{})
+
+	// the instance of the chain is run such as by Run
+	instance := toTest.(*finalizedChain).runnable()
+	require.NoError(t, instance.run())
+
+	cmdDescriptors := instance.cmdDescriptors
+	assert.IsType(t, &strings.Reader{}, cmdDescriptors[0].command.Stdin)
+	assert.IsType(t, &os.File{}, cmdDescriptors[1].command.Stdin)
+	assert.IsType(t, &bytes.Buffer{}, cmdDescriptors[1].command.Stdout)
+}
+
+type nonComparableWriter struct {
+	written []byte
+}
+
+func (n nonComparableWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func TestSameWriter(t *testing.T) {
+	buffer := &bytes.Buffer{}
+
+	assert.True(t, sameWriter(buffer, buffer))
+	assert.False(t, sameWriter(buffer, &bytes.Buffer{}))
+	assert.False(t, sameWriter(buffer, nil))
+	assert.False(t, sameWriter(nonComparableWriter{}, nonComparableWriter{}))
+}
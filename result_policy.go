@@ -0,0 +1,56 @@
+package cmdchain
+
+// ResultPolicy defines which errors of the commands of a pipeline will be part of the pipeline's result (see
+// FinalizedBuilder.WithResultPolicy). The result decides if the pipeline has failed. So it also affects the
+// conditional execution of the following pipelines (see ChainBuilder.JoinShellCmd).
+type ResultPolicy int
+
+const (
+	// ResultAll will take the errors of all commands into account. This is the default policy.
+	ResultAll ResultPolicy = iota
+
+	// ResultLastCommand will only take the error of the last command of the pipeline into account. This is the
+	// default behaviour of POSIX shells.
+	ResultLastCommand
+
+	// ResultPipefail will only take the error of the rightmost failed command of the pipeline into account (such
+	// as the shell option "pipefail").
+	ResultPipefail
+
+	// ResultFirstFailure will only take the error of the leftmost failed command of the pipeline into account.
+	ResultFirstFailure
+)
+
+// apply returns the errors of the given pipeline errors which are relevant for this policy. All other errors
+// will be nil.
+func (p ResultPolicy) apply(pipelineErrors MultipleErrors) MultipleErrors {
+	if p == ResultAll || !pipelineErrors.hasError {
+		return pipelineErrors
+	}
+
+	relevant := -1
+	switch p {
+	case ResultLastCommand:
+		relevant = len(pipelineErrors.errors) - 1
+	case ResultPipefail:
+		for i := len(pipelineErrors.errors) - 1; i >= 0 && relevant < 0; i-- {
+			if pipelineErrors.errors[i] != nil {
+				relevant = i
+			}
+		}
+	case ResultFirstFailure:
+		for i := 0; i < len(pipelineErrors.errors) && relevant < 0; i++ {
+			if pipelineErrors.errors[i] != nil {
+				relevant = i
+			}
+		}
+	}
+
+	result := runErrors()
+	result.errors = make([]error, len(pipelineErrors.errors))
+	if relevant >= 0 {
+		result.setError(relevant, pipelineErrors.errors[relevant])
+	}
+
+	return result
+}
@@ -0,0 +1,270 @@
+package cmdchain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// RetryPolicy describes how often and under which conditions a failed command will be started again (see
+// CommandBuilder.WithRetry).
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts (including the first one). Values less than 2 mean that the
+	// command will not be retried.
+	MaxAttempts int
+
+	// Backoff returns the duration to wait before the given attempt (beginning with the second attempt). If it is
+	// nil, the next attempt will be started immediately. See ConstantBackoff and ExponentialBackoff.
+	Backoff func(attempt int) time.Duration
+
+	// Retry decides if a failed attempt should be retried. Such as for the error checking (see ErrorChecker), the
+	// attempt will be retried if the checker decides that its error is a "real" error. If it is nil, all failed
+	// attempts will be retried.
+	Retry ErrorChecker
+}
+
+// ConstantBackoff returns a backoff function (see RetryPolicy.Backoff) which waits always the given delay.
+func ConstantBackoff(delay time.Duration) func(int) time.Duration {
+	return func(int) time.Duration {
+		return delay
+	}
+}
+
+// ExponentialBackoff returns a backoff function (see RetryPolicy.Backoff) which waits the initial delay before the
+// second attempt and doubles the delay for each further attempt. The delay will never exceed the given maximum
+// (0 means no maximum).
+func ExponentialBackoff(initial, maximum time.Duration) func(int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := initial
+		for i := 2; i < attempt && (maximum <= 0 || delay < maximum); i++ {
+			delay *= 2
+		}
+		if maximum > 0 && delay > maximum {
+			return maximum
+		}
+		return delay
+	}
+}
+
+func (c *chain) WithRetry(policy RetryPolicy) CommandBuilder {
+	cmdIndex := len(c.cmdDescriptors) - 1
+	cmdDesc := &(c.cmdDescriptors[cmdIndex])
+
+	// only commands which are executed as their own process and whose input does not come from a previous
+	// command can be retried (the input of the previous command can not be replayed)
+	if cmdDesc.stage != nil {
+		c.buildErrors.addError(fmt.Errorf("command %d can not be retried: it is not executed as its own process", cmdIndex))
+		return c
+	}
+	if !c.isPipelineStart(cmdIndex) {
+		c.buildErrors.addError(
+			fmt.Errorf("command %d can not be retried: its input comes from the previous command", cmdIndex),
+		)
+		return c
+	}
+
+	cmdDesc.stage = &retryStage{index: cmdIndex, policy: policy, ctx: cmdDesc.ctx}
+	return c
+}
+
+// retryStage executes a command as a single stage. If the command fails, it will be started again (according to
+// its RetryPolicy). Each attempt will be executed by its own process which inherits the streams of the command.
+type retryStage struct {
+	index  int
+	policy RetryPolicy
+	ctx    context.Context
+
+	// cancelSignal will be sent to the current attempt if the context is done. If the attempt is still running after
+	// the cancelGracePeriod, it will be killed (see cancelGracefully).
+	cancelSignal      os.Signal
+	cancelGracePeriod time.Duration
+
+	// chainCtx is the context of the chain's run (see chain.runCtx). If it is done, no further attempts will be
+	// started. It is nil if the chain can not be canceled.
+	chainCtx context.Context
+
+	mutex    sync.Mutex
+	current  *exec.Cmd
+	running  bool
+	attempts int
+
+	// stopped is true if no further attempts should be started (e.g. the stage was signaled). In that case the
+	// interrupt channel is closed, so the backoff will be interrupted.
+	stopped   bool
+	interrupt chan struct{}
+
+	done chan error
+}
+
+func (r *retryStage) start(command *exec.Cmd, pipes []io.Closer) error {
+	var input *replayReader
+	if command.Stdin != nil {
+		input = &replayReader{source: command.Stdin}
+	}
+
+	r.mutex.Lock()
+	r.attempts = 0
+	r.stopped = false
+	r.interrupt = make(chan struct{})
+	r.done = make(chan error, 1)
+	err := r.startAttempt(command, input)
+	r.mutex.Unlock()
+
+	if err != nil {
+		for _, pipe := range pipes {
+			_ = pipe.Close()
+		}
+		return err
+	}
+
+	go func() {
+		err := r.run(command, input)
+
+		// the pipes must be open until the last attempt is done
+		for _, pipe := range pipes {
+			_ = pipe.Close()
+		}
+		r.done <- err
+	}()
+
+	return nil
+}
+
+// run waits for the current attempt and starts the next attempts (if necessary).
+func (r *retryStage) run(command *exec.Cmd, input *replayReader) error {
+	for {
+		err := r.current.Wait()
+
+		r.mutex.Lock()
+		r.running = false
+		command.ProcessState = r.current.ProcessState
+		attempts := r.attempts
+		r.mutex.Unlock()
+
+		if err == nil || attempts >= r.policy.MaxAttempts {
+			return err
+		}
+		if r.policy.Retry != nil && !r.policy.Retry(r.index, command, err) {
+			return err
+		}
+
+		var chainDone <-chan struct{}
+		if r.chainCtx != nil {
+			chainDone = r.chainCtx.Done()
+		}
+
+		if r.policy.Backoff != nil {
+			timer := time.NewTimer(r.policy.Backoff(attempts + 1))
+			select {
+			case <-timer.C:
+			case <-r.interrupt:
+				timer.Stop()
+			case <-chainDone:
+				timer.Stop()
+			}
+		}
+
+		r.mutex.Lock()
+		if r.stopped || (r.chainCtx != nil && r.chainCtx.Err() != nil) {
+			r.mutex.Unlock()
+			return err
+		}
+		startErr := r.startAttempt(command, input)
+		r.mutex.Unlock()
+
+		if startErr != nil {
+			return startErr
+		}
+	}
+}
+
+// startAttempt starts a new process for the given command. The mutex must be locked by the caller.
+func (r *retryStage) startAttempt(command *exec.Cmd, input *replayReader) error {
+	if command.Err != nil {
+		return command.Err
+	}
+
+	attempt := &exec.Cmd{}
+	if r.ctx != nil {
+		attempt = exec.CommandContext(r.ctx, command.Path)
+	}
+	attempt.Path, attempt.Args, attempt.Err = command.Path, command.Args, nil
+	attempt.Env = command.Env
+	attempt.Dir = command.Dir
+	attempt.Stdout = command.Stdout
+	attempt.Stderr = command.Stderr
+	attempt.ExtraFiles = command.ExtraFiles
+	attempt.SysProcAttr = command.SysProcAttr
+	attempt.WaitDelay = command.WaitDelay
+	if r.ctx != nil {
+		cancelGracefully(attempt, r.cancelSignal, r.cancelGracePeriod)
+	}
+	if input != nil {
+		attempt.Stdin = input.replay()
+	}
+
+	if err := attempt.Start(); err != nil {
+		return err
+	}
+
+	r.current = attempt
+	r.running = true
+	r.attempts++
+	if r.attempts == 1 {
+		// the process of the first attempt represents the command (e.g. for its pid)
+		command.Process = attempt.Process
+	}
+
+	return nil
+}
+
+func (r *retryStage) wait() error {
+	return <-r.done
+}
+
+func (r *retryStage) signal(sig os.Signal) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// a signaled command will not be retried anymore
+	if !r.stopped {
+		r.stopped = true
+		close(r.interrupt)
+	}
+
+	if !r.running {
+		return nil
+	}
+	return r.current.Process.Signal(sig)
+}
+
+// attemptCount returns the number of started attempts.
+func (r *retryStage) attemptCount() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.attempts
+}
+
+// replayReader records all bytes which are read from the source. So the input can be replayed for the next
+// attempt of a command.
+type replayReader struct {
+	source   io.Reader
+	recorded []byte
+}
+
+func (r *replayReader) Read(p []byte) (int, error) {
+	n, err := r.source.Read(p)
+	r.recorded = append(r.recorded, p[:n]...)
+	return n, err
+}
+
+// replay returns a reader which contains all recorded bytes followed by the rest of the source.
+func (r *replayReader) replay() io.Reader {
+	return io.MultiReader(bytes.NewReader(r.recorded), r)
+}
@@ -0,0 +1,179 @@
+package cmdchain
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyCommand returns a shell script which fails until it was executed the given times. Each attempt will
+// print its number and saves its input into the given directory.
+func flakyCommand(dir string, successfulAttempt int) []string {
+	script := fmt.Sprintf(`n=$(cat count 2>/dev/null || echo 0); n=$((n+1)); echo $n > count; cat > input.$n; echo attempt $n; [ $n -ge %d ]`, successfulAttempt)
+	return []string{"sh", "-c", fmt.Sprintf("cd %s && %s", dir, script)}
+}
+
+func TestWithRetry(t *testing.T) {
+	flaky := flakyCommand(t.TempDir(), 3)
+	output := &bytes.Buffer{}
+
+	report, err := Builder().
+		Join(flaky[0], flaky[1:]...).WithRetry(RetryPolicy{MaxAttempts: 3}).
+		Finalize().WithOutput(output).RunWithReport()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "attempt 1\nattempt 2\nattempt 3\n", output.String())
+	assert.Equal(t, 3, report.Commands[0].Attempts)
+	assert.Equal(t, 0, report.Commands[0].ExitCode)
+	assert.NotZero(t, report.Commands[0].Pid)
+}
+
+func TestWithRetry_exhausted(t *testing.T) {
+	flaky := flakyCommand(t.TempDir(), 3)
+
+	report, err := Builder().
+		Join(flaky[0], flaky[1:]...).WithRetry(RetryPolicy{MaxAttempts: 2}).
+		Finalize().RunWithReport()
+
+	var cmdErr *CommandError
+	if assert.ErrorAs(t, err, &cmdErr) {
+		assert.Equal(t, 1, cmdErr.ExitCode)
+	}
+	assert.Equal(t, 2, report.Commands[0].Attempts)
+	assert.Equal(t, 1, report.Commands[0].ExitCode)
+}
+
+func TestWithRetry_predicate(t *testing.T) {
+	flaky := flakyCommand(t.TempDir(), 3)
+
+	report, err := Builder().
+		Join(flaky[0], flaky[1:]...).WithRetry(RetryPolicy{MaxAttempts: 3, Retry: IgnoreExitCode(1)}).
+		Finalize().RunWithReport()
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, report.Commands[0].Attempts, "exit code 1 should not be retried")
+}
+
+func TestWithRetry_backoff(t *testing.T) {
+	flaky := flakyCommand(t.TempDir(), 2)
+
+	start := time.Now()
+	err := Builder().
+		Join(flaky[0], flaky[1:]...).WithRetry(RetryPolicy{MaxAttempts: 2, Backoff: ConstantBackoff(200 * time.Millisecond)}).
+		Finalize().Run()
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestWithRetry_replayInput(t *testing.T) {
+	dir := t.TempDir()
+	flaky := flakyCommand(dir, 2)
+	output := &bytes.Buffer{}
+
+	err := Builder().
+		WithInput(strings.NewReader("first\n"), strings.NewReader("second\n")).
+		Join(flaky[0], flaky[1:]...).WithRetry(RetryPolicy{MaxAttempts: 2}).
+		Join("grep", "attempt").
+		Finalize().WithOutput(output).Run()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "attempt 1\nattempt 2\n", output.String(), "the output of all attempts should be piped into the next command")
+
+	for _, attempt := range []string{"1", "2"} {
+		input, err := os.ReadFile(path.Join(dir, "input."+attempt))
+		require.NoError(t, err)
+		assert.Contains(t, string(input), "first\n", "attempt %s should receive the complete input", attempt)
+		assert.Contains(t, string(input), "second\n", "attempt %s should receive the complete input", attempt)
+	}
+}
+
+func TestWithRetry_notReplayable(t *testing.T) {
+	flaky := flakyCommand(t.TempDir(), 2)
+
+	err := Builder().
+		Join("echo", "input").
+		Join(flaky[0], flaky[1:]...).WithRetry(RetryPolicy{MaxAttempts: 2}).
+		Finalize().Run()
+
+	assert.ErrorIs(t, err, ErrBuild)
+	assert.ErrorContains(t, err, "command 1 can not be retried: its input comes from the previous command")
+}
+
+func TestWithRetry_notOwnProcess(t *testing.T) {
+	err := Builder().
+		JoinShellCmd("cd /").WithRetry(RetryPolicy{MaxAttempts: 2}).
+		Finalize().Run()
+
+	assert.ErrorIs(t, err, ErrBuild)
+	assert.ErrorContains(t, err, "command 0 can not be retried: it is not executed as its own process")
+}
+
+func TestWithRetry_shellCommand(t *testing.T) {
+	dir := t.TempDir()
+	flaky := flakyCommand(dir, 2)
+
+	sOut, _, err := Builder().
+		JoinShellCmd(fmt.Sprintf(`sh -c '%s' && echo done`, flaky[2])).WithRetry(RetryPolicy{MaxAttempts: 2}).
+		Finalize().RunAndGet()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "attempt 1\nattempt 2\ndone\n", sOut)
+}
+
+func TestWithRetry_signaled(t *testing.T) {
+	running, err := Builder().
+		Join("sleep", "10").WithRetry(RetryPolicy{MaxAttempts: 3}).
+		Finalize().Start()
+	require.NoError(t, err)
+
+	require.NoError(t, running.Kill())
+
+	assert.Error(t, running.Wait())
+	assert.Equal(t, 1, running.Report().Commands[0].Attempts, "a signaled command should not be retried")
+}
+
+func TestWithRetry_signaledWhileBackoff(t *testing.T) {
+	flaky := flakyCommand(t.TempDir(), 2)
+
+	running, err := Builder().
+		Join(flaky[0], flaky[1:]...).WithRetry(RetryPolicy{MaxAttempts: 2, Backoff: ConstantBackoff(10 * time.Second)}).
+		Finalize().WithTimeout(200 * time.Millisecond).Start()
+	require.NoError(t, err)
+
+	select {
+	case <-running.Done():
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the backoff should be interrupted")
+	}
+
+	assert.Error(t, running.Wait())
+	assert.Equal(t, 1, running.Report().Commands[0].Attempts)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	tests := []struct {
+		maximum time.Duration
+		attempt int
+		expect  time.Duration
+	}{
+		{0, 2, 100 * time.Millisecond},
+		{0, 3, 200 * time.Millisecond},
+		{0, 4, 400 * time.Millisecond},
+		{300 * time.Millisecond, 3, 200 * time.Millisecond},
+		{300 * time.Millisecond, 4, 300 * time.Millisecond},
+		{300 * time.Millisecond, 100, 300 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s_%d", tt.maximum, tt.attempt), func(t *testing.T) {
+			assert.Equal(t, tt.expect, ExponentialBackoff(100*time.Millisecond, tt.maximum)(tt.attempt))
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package cmdchain
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// CommandStatus describes in which state a command of a running chain is.
+type CommandStatus int
+
+const (
+	// CommandPending means that the command is not started yet.
+	CommandPending CommandStatus = iota
+
+	// CommandRunning means that the command is started but not exited yet.
+	CommandRunning
+
+	// CommandExited means that the command has been exited (successfully or not).
+	CommandExited
+
+	// CommandFailed means that the command could not be started.
+	CommandFailed
+
+	// CommandSkipped means that the command will never be started because its pipeline was skipped
+	// (e.g. `a && b` if a has failed).
+	CommandSkipped
+)
+
+func (s CommandStatus) String() string {
+	switch s {
+	case CommandPending:
+		return "pending"
+	case CommandRunning:
+		return "running"
+	case CommandExited:
+		return "exited"
+	case CommandFailed:
+		return "failed"
+	case CommandSkipped:
+		return "skipped"
+	default:
+		return "?"
+	}
+}
+
+// CommandState contains the state of a single command of a running chain.
+type CommandState struct {
+	// Index is the index of the command inside the chain.
+	Index int
+
+	// Status is the current status of the command.
+	Status CommandStatus
+
+	// Pid is the process id of the command. It is 0 if the command is not started yet or if the command is not
+	// executed as its own process (e.g. builtins or groups of shell commands).
+	Pid int
+
+	// ExitCode is the exit code of the command. It is -1 if the command is not exited yet or if the exit code
+	// is unknown.
+	ExitCode int
+
+	// Err is the error of the command (see FinalizedBuilder.Run). It is nil if the command is not exited yet or
+	// the command exited successfully. The error checkers are not involved here.
+	Err error
+
+	// InputClosedEarly is true if the command has exited before its input was completely written by the previous
+	// command (e.g. `... | head -1`). In that case, the previous command is allowed to fail because of the broken
+	// pipe (see FinalizedBuilder.WithResultPolicy).
+	InputClosedEarly bool
+}
+
+// defaultCancelGracePeriod is the time between the cancel signal and the kill of the commands (see
+// FinalizedBuilder.WithCancelSignal).
+const defaultCancelGracePeriod = 10 * time.Second
+
+// chainTimeout is the cause of the chain's context if the chain has timed out (see FinalizedBuilder.WithTimeout). The
+// nested chains (e.g. command substitutions) are canceled with the same cause, so they report the chain's timeout.
+type chainTimeout struct {
+	timeout time.Duration
+}
+
+func (t *chainTimeout) Error() string {
+	return "chain timed out"
+}
+
+func (c *chain) cancelSignalAndGracePeriod() (os.Signal, time.Duration) {
+	if c.cancelSignal == nil {
+		return syscall.SIGTERM, defaultCancelGracePeriod
+	}
+	return c.cancelSignal, c.cancelGracePeriod
+}
+
+type runningChain struct {
+	chain *chain
+
+	mutex  sync.Mutex
+	states []CommandState
+
+	// stopped is true if no further pipelines should be started (e.g. the chain's context is done).
+	stopped bool
+
+	// cause is the cause of the cancellation if the chain's context is done. The commands which were running at
+	// this moment are marked in signaled.
+	cause    error
+	signaled []bool
+
+	// timers contains the timers of the commands with a timeout (see CommandBuilder.WithTimeout). After the timeout,
+	// it contains the timer which kills the command after the grace period. The commands which were terminated
+	// because of their timeout are marked in timedOut.
+	timers   []*time.Timer
+	timedOut []bool
+
+	// startTimes, endTimes and streams are collected for the report (see Report). The streams are only counted if
+	// withReport is true.
+	startTimes []time.Time
+	endTimes   []time.Time
+	streams    []streamCounters
+	withReport bool
+
+	// nested contains the nested chains which are running while a command is prepared (e.g. command substitutions).
+	// They will be signaled together with the running commands (see runNested).
+	nested []RunningChain
+
+	// exitRequested is true if the last executed pipeline has requested to stop the execution of the following
+	// statements (e.g. the exit builtin of a shell command)
+	exitRequested bool
+
+	done chan struct{}
+	err  error
+}
+
+func newRunningChain(c *chain) *runningChain {
+	r := &runningChain{
+		chain:    c,
+		states:   make([]CommandState, len(c.cmdDescriptors)),
+		signaled: make([]bool, len(c.cmdDescriptors)),
+		timers:   make([]*time.Timer, len(c.cmdDescriptors)),
+		timedOut: make([]bool, len(c.cmdDescriptors)),
+
+		startTimes: make([]time.Time, len(c.cmdDescriptors)),
+		endTimes:   make([]time.Time, len(c.cmdDescriptors)),
+		streams:    make([]streamCounters, len(c.cmdDescriptors)),
+
+		done: make(chan struct{}),
+	}
+	for i := range r.states {
+		r.states[i] = CommandState{Index: i, ExitCode: -1}
+	}
+
+	return r
+}
+
+// exited updates the state of the given command. If the command was canceled, the returned error will be
+// a CanceledError (or a TimeoutError if the command or the chain has timed out).
+func (r *runningChain) exited(cmdIndex int, err error) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.timers[cmdIndex] != nil {
+		r.timers[cmdIndex].Stop()
+	}
+
+	r.endTimes[cmdIndex] = time.Now()
+
+	state := &r.states[cmdIndex]
+	state.Status = CommandExited
+
+	if err == nil {
+		state.ExitCode = 0
+	} else if exitErr, ok := err.(exitCoder); ok && isExitError(err) {
+		state.ExitCode = exitErr.ExitCode()
+	}
+
+	if r.timedOut[cmdIndex] {
+		cmdDescriptor := r.chain.cmdDescriptors[cmdIndex]
+		err = &TimeoutError{Index: cmdIndex, Command: cmdDescriptor.String(), Timeout: cmdDescriptor.timeout, Err: err}
+	} else if r.signaled[cmdIndex] {
+		err = r.canceledError(cmdIndex, err)
+	}
+	state.Err = err
+
+	return err
+}
+
+// cancelOnDone waits until the chain is done or the given context is done. In the latter case all running commands
+// will be signaled. If they are still running after the grace period, they will be killed.
+func (r *runningChain) cancelOnDone(ctx context.Context) {
+	select {
+	case <-r.done:
+		return
+	case <-ctx.Done():
+	}
+
+	sig, gracePeriod := r.chain.cancelSignalAndGracePeriod()
+
+	r.mutex.Lock()
+	r.stopped = true
+	r.cause = context.Cause(ctx)
+	for i, state := range r.states {
+		r.signaled[i] = state.Status == CommandRunning
+	}
+	r.mutex.Unlock()
+
+	_ = r.Signal(sig)
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-r.done:
+	case <-timer.C:
+		_ = r.Kill()
+	}
+}
+
+// startTimer starts the timer of the given command if the command has a timeout. If the timer expires, the command
+// will be terminated (such as the chain would be canceled).
+func (r *runningChain) startTimer(cmdIndex int) {
+	timeout := r.chain.cmdDescriptors[cmdIndex].timeout
+	if timeout <= 0 {
+		return
+	}
+
+	r.timers[cmdIndex] = time.AfterFunc(timeout, func() {
+		sig, gracePeriod := r.chain.cancelSignalAndGracePeriod()
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		if r.states[cmdIndex].Status != CommandRunning {
+			return
+		}
+
+		r.timedOut[cmdIndex] = true
+		_ = r.signalCommand(cmdIndex, sig)
+
+		// the kill timer replaces the expired timer. So it will be stopped if the command exits in time (see exited).
+		r.timers[cmdIndex] = time.AfterFunc(gracePeriod, func() {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+
+			if r.states[cmdIndex].Status == CommandRunning {
+				_ = r.signalCommand(cmdIndex, os.Kill)
+			}
+		})
+	})
+}
+
+// inputClosed records if the input of the given (exited) command is closed early: the previous command has not closed
+// its end of the pipe yet (it is still running or there is unread output). It must be called right before the input
+// is closed, because a byte of the pipe could be consumed.
+func (r *runningChain) inputClosed(cmdIndex int) {
+	early := true
+
+	// an expired deadline would prevent the read at all. So there must be a (short) time to read.
+	input := r.chain.cmdDescriptors[cmdIndex].command.Stdin
+	if counting, isCounting := input.(*countingReader); isCounting {
+		input = counting.delegate
+	}
+
+	file, isFile := input.(*os.File)
+	if isFile && file.SetReadDeadline(time.Now().Add(time.Millisecond)) == nil {
+		n, err := file.Read(make([]byte, 1))
+		early = n > 0 || errors.Is(err, os.ErrDeadlineExceeded)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.states[cmdIndex].InputClosedEarly = early
+}
+
+// cancelGracefully lets the given command be canceled such as the chain if its own context is done (see
+// ChainBuilder.JoinWithContext): at first, the given signal will be sent. If the command is still running after the
+// grace period, it will be killed. Otherwise, the command would be killed immediately (see exec.CommandContext).
+// The grace period only starts if the context is done: the WaitDelay of the command is not used, because it would
+// also cut off the output of a command which has exited normally.
+func cancelGracefully(command *exec.Cmd, sig os.Signal, gracePeriod time.Duration) {
+	command.Cancel = func() error {
+		// the kill of a process which has exited in the meantime will fail (without any effect)
+		time.AfterFunc(gracePeriod, func() {
+			_ = command.Process.Kill()
+		})
+		return command.Process.Signal(sig)
+	}
+}
+
+// canceledError returns the error of a command which was canceled. If the chain has timed out (see
+// FinalizedBuilder.WithTimeout), it will be a TimeoutError. Otherwise, a CanceledError.
+func (r *runningChain) canceledError(cmdIndex int, err error) error {
+	var timeout *chainTimeout
+	if errors.As(r.cause, &timeout) {
+		return &TimeoutError{
+			Index:   cmdIndex,
+			Command: r.chain.cmdDescriptors[cmdIndex].String(),
+			Timeout: timeout.timeout,
+			Err:     err,
+		}
+	}
+	return &CanceledError{Err: err, Cause: r.cause}
+}
+
+// commandError wraps the given error into a CommandError if the command has exited unsuccessfully. All other errors
+// (e.g. TimeoutError or CanceledError) will be returned as they are.
+func (r *runningChain) commandError(cmdIndex int, err error) error {
+	exitErr, ok := err.(exitCoder)
+	if !ok || !isExitError(err) {
+		return err
+	}
+
+	command := r.chain.cmdDescriptors[cmdIndex].command
+	cmdErr := &CommandError{
+		Index:    cmdIndex,
+		Command:  r.chain.cmdDescriptors[cmdIndex].String(),
+		ExitCode: exitErr.ExitCode(),
+		Signal:   terminatingSignal(err),
+		Err:      err,
+	}
+	if stderr, captured := capturedOutput(command.Stderr); captured {
+		cmdErr.Stderr = stderr
+	}
+
+	return cmdErr
+}
+
+// stop prevents that further pipelines will be started and sends the given signal to all running commands.
+func (r *runningChain) stop(sig os.Signal) error {
+	r.mutex.Lock()
+	r.stopped = true
+	r.mutex.Unlock()
+
+	return r.Signal(sig)
+}
+
+// canceledErrors returns the errors for the given pipeline which could not be started because of the cancellation.
+// If the chain was stopped without cancellation, there are no errors.
+func (r *runningChain) canceledErrors(p pipeline) MultipleErrors {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := runErrors()
+	result.errors = make([]error, p.to-p.from)
+
+	if r.cause != nil {
+		for cmdIndex := p.from; cmdIndex < p.to; cmdIndex++ {
+			result.setError(cmdIndex-p.from, r.canceledError(cmdIndex, nil))
+		}
+	}
+
+	return result
+}
+
+func (r *runningChain) Wait() error {
+	<-r.done
+	return r.err
+}
+
+func (r *runningChain) Done() <-chan struct{} {
+	return r.done
+}
+
+func (r *runningChain) Pids() []int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	pids := make([]int, len(r.states))
+	for i, state := range r.states {
+		pids[i] = state.Pid
+	}
+	return pids
+}
+
+func (r *runningChain) States() []CommandState {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	states := make([]CommandState, len(r.states))
+	copy(states, r.states)
+	return states
+}
+
+func (r *runningChain) Signal(sig os.Signal) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// the downstream commands will be signaled at first. Otherwise, they could see the end of their input and
+	// continue (e.g. a group of shell commands would start its next command)
+	var errs []error
+	for _, nested := range r.nested {
+		if err := nested.Signal(sig); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i := len(r.states) - 1; i >= 0; i-- {
+		if r.states[i].Status != CommandRunning {
+			continue
+		}
+
+		err := r.signalCommand(i, sig)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// runNested runs the given nested chain of a command which is prepared (e.g. a command substitution). While it is
+// running, it will be signaled together with the running commands (see Signal).
+func (r *runningChain) runNested(nested FinalizedBuilder) error {
+	running, err := nested.Start()
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	r.nested = append(r.nested, running)
+	r.mutex.Unlock()
+
+	err = running.Wait()
+
+	r.mutex.Lock()
+	r.nested = slices.DeleteFunc(r.nested, func(n RunningChain) bool {
+		return n == running
+	})
+	r.mutex.Unlock()
+
+	return err
+}
+
+// signalCommand sends the given signal to the given (running) command. The mutex must be locked by the caller.
+func (r *runningChain) signalCommand(cmdIndex int, sig os.Signal) error {
+	cmdDescriptor := r.chain.cmdDescriptors[cmdIndex]
+
+	var err error
+	if cmdDescriptor.stage != nil {
+		err = cmdDescriptor.stage.signal(sig)
+	} else {
+		err = cmdDescriptor.command.Process.Signal(sig)
+	}
+
+	// the command could be exited in the meantime
+	if errors.Is(err, os.ErrProcessDone) {
+		return nil
+	}
+	return err
+}
+
+func (r *runningChain) Kill() error {
+	return r.Signal(os.Kill)
+}
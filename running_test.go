@@ -0,0 +1,155 @@
+package cmdchain
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestStart(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	running, err := Builder().
+		Join(testHelper, "-o", "TEST").
+		Join("grep", "TEST").
+		Finalize().WithOutput(output).Start()
+	require.NoError(t, err)
+
+	assert.NoError(t, running.Wait())
+	assert.NoError(t, running.Wait(), "wait should be callable multiple times")
+	assert.Equal(t, "TEST\n", output.String())
+
+	select {
+	case <-running.Done():
+	default:
+		assert.Fail(t, "done channel should be closed")
+	}
+
+	for i, pid := range running.Pids() {
+		assert.NotZero(t, pid, "pid of command %d should be set", i)
+	}
+	for i, state := range running.States() {
+		assert.Equal(t, i, state.Index)
+		assert.Equal(t, CommandExited, state.Status)
+		assert.Equal(t, 0, state.ExitCode)
+		assert.NoError(t, state.Err)
+	}
+}
+
+func TestStart_failing(t *testing.T) {
+	running, err := Builder().
+		Join(testHelper, "-x", "13").
+		Finalize().Start()
+	require.NoError(t, err)
+
+	assert.Error(t, running.Wait())
+
+	states := running.States()
+	assert.Equal(t, CommandExited, states[0].Status)
+	assert.Equal(t, 13, states[0].ExitCode)
+	assert.Error(t, states[0].Err)
+}
+
+func TestStart_invalidCommand(t *testing.T) {
+	running, err := Builder().
+		Join("invalidApplication").
+		Finalize().Start()
+
+	assert.Error(t, err)
+	assert.Nil(t, running)
+}
+
+func TestStart_buildError(t *testing.T) {
+	running, err := Builder().
+		Join("ls", "-l").DiscardStdOut().
+		Join("grep", "TEST").
+		Finalize().Start()
+
+	assert.Error(t, err)
+	assert.IsType(t, MultipleErrors{}, err)
+	assert.Nil(t, running)
+}
+
+func TestStart_kill(t *testing.T) {
+	running, err := Builder().
+		Join(testHelper, "-to", "10s", "-ti", "10ms").
+		Join("grep", "OUT").
+		Finalize().Start()
+	require.NoError(t, err)
+
+	assert.Equal(t, CommandRunning, running.States()[0].Status)
+	assert.NoError(t, running.Kill())
+
+	select {
+	case <-running.Done():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "chain should be killed")
+	}
+
+	assert.Error(t, running.Wait())
+	assert.Contains(t, running.Wait().Error(), "signal: killed")
+	assert.NoError(t, running.Kill(), "killing an exited chain should not fail")
+}
+
+func TestStart_signal(t *testing.T) {
+	running, err := Builder().
+		JoinShellCmd(testHelper + " -to 10s -ti 10ms | ( grep OUT )").
+		Finalize().Start()
+	require.NoError(t, err)
+
+	assert.NoError(t, running.Signal(syscall.SIGTERM))
+
+	select {
+	case <-running.Done():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "chain should be terminated")
+	}
+
+	assert.Error(t, running.Wait())
+	assert.Contains(t, running.Wait().Error(), "signal: terminated")
+}
+
+func TestStart_skipped(t *testing.T) {
+	running, err := Builder().
+		WithBuiltins(map[string]Builtin{"echo": EchoBuiltin, "false": FalseBuiltin}).
+		JoinShellCmd("false && echo a || echo b").
+		Finalize().Start()
+	require.NoError(t, err)
+	require.NoError(t, running.Wait())
+
+	var statuses []CommandStatus
+	for _, state := range running.States() {
+		statuses = append(statuses, state.Status)
+	}
+	assert.Equal(t, []CommandStatus{CommandExited, CommandSkipped, CommandExited}, statuses)
+	assert.Equal(t, []int{0, 0, 0}, running.Pids(), "builtins are not executed as their own process")
+}
+
+func TestStart_killWhileCommandSubstitution(t *testing.T) {
+	running, err := Builder().
+		JoinShellCmd("true; echo $(sleep 3)").
+		Finalize().Start()
+	require.NoError(t, err)
+
+	// the command substitution is executed right before its command is started
+	time.Sleep(200 * time.Millisecond)
+
+	start := time.Now()
+	assert.Equal(t, CommandPending, running.States()[1].Status)
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "the chain should be accessible while the command is prepared")
+
+	assert.NoError(t, running.Kill())
+
+	select {
+	case <-running.Done():
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "the command substitution should be killed")
+	}
+
+	assert.Error(t, running.Wait())
+	assert.Contains(t, running.Wait().Error(), "signal: killed")
+	assert.Equal(t, CommandFailed, running.States()[1].Status)
+}
@@ -0,0 +1,163 @@
+package cmdchain
+
+import "io"
+
+// sequenceOperator describes under which condition a pipeline will be executed after
+// its predecessor pipeline is finished.
+type sequenceOperator int
+
+const (
+	// sequenceOnSuccess will execute the pipeline only if the previous pipeline was successful (&&)
+	sequenceOnSuccess sequenceOperator = iota
+
+	// sequenceOnFailure will execute the pipeline only if the previous pipeline has failed (||)
+	sequenceOnFailure
+
+	// sequenceAlways will execute the pipeline regardless of the previous pipeline's result (;). It
+	// also marks the beginning of a new statement.
+	sequenceAlways
+)
+
+func (o sequenceOperator) String() string {
+	switch o {
+	case sequenceOnSuccess:
+		return "&&"
+	case sequenceOnFailure:
+		return "||"
+	case sequenceAlways:
+		return ";"
+	default:
+		return "?"
+	}
+}
+
+// sequence marks the command index where a new pipeline begins inside the chain.
+type sequence struct {
+	start    int
+	operator sequenceOperator
+}
+
+// pipeline is a range of commands (from inclusive, to exclusive) which are streamed together.
+// The operator decides if the pipeline should be executed after the previous pipeline. The
+// first pipeline of a chain will always be executed.
+type pipeline struct {
+	from     int
+	to       int
+	operator sequenceOperator
+}
+
+func (p pipeline) shouldRun(previousFailed bool) bool {
+	switch p.operator {
+	case sequenceOnSuccess:
+		return !previousFailed
+	case sequenceOnFailure:
+		return previousFailed
+	default:
+		return true
+	}
+}
+
+// beginSequence will cause that the next joined command is not linked with the previous one. Instead,
+// the next command will start a new pipeline, which will be executed after the current pipeline is done.
+func (c *chain) beginSequence(operator sequenceOperator) {
+	c.sequences = append(c.sequences, sequence{
+		start:    len(c.cmdDescriptors),
+		operator: operator,
+	})
+}
+
+func (c *chain) isPipelineStart(cmdIndex int) bool {
+	if cmdIndex == 0 {
+		return true
+	}
+	for _, seq := range c.sequences {
+		if seq.start == cmdIndex {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *chain) isPipelineEnd(cmdIndex int) bool {
+	return cmdIndex+1 == len(c.cmdDescriptors) || c.isPipelineStart(cmdIndex+1)
+}
+
+func (c *chain) pipelines() []pipeline {
+	result := make([]pipeline, 0, len(c.sequences)+1)
+
+	cur := pipeline{}
+	for _, seq := range c.sequences {
+		cur.to = seq.start
+		result = append(result, cur)
+
+		cur = pipeline{from: seq.start, operator: seq.operator}
+	}
+	cur.to = len(c.cmdDescriptors)
+	result = append(result, cur)
+
+	return result
+}
+
+// lastCmdDescriptors returns the last command of each pipeline. These are the commands
+// which will write into the chain's output.
+func (c *chain) lastCmdDescriptors() []*cmdDescriptor {
+	var result []*cmdDescriptor
+	if len(c.cmdDescriptors) == 0 {
+		return result
+	}
+
+	for _, p := range c.pipelines() {
+		result = append(result, &(c.cmdDescriptors[p.to-1]))
+	}
+	return result
+}
+
+// skipPipeline will release all streams of the pipeline's commands. These commands will never be
+// started, so we have to make sure that no stream goroutine will wait for them endlessly.
+func (c *chain) skipPipeline(p pipeline) {
+	for cmdIndex := p.from; cmdIndex < p.to; cmdIndex++ {
+		cmdDescriptor := c.cmdDescriptors[cmdIndex]
+		if cmdIndex+1 == p.to {
+			continue
+		}
+
+		// the writing end of the pipes (created by StdoutPipe()/StderrPipe()) would be normally
+		// closed after the command is started
+		if closer, isCloser := cmdDescriptor.command.Stdout.(io.Closer); isCloser && cmdDescriptor.outToIn {
+			_ = closer.Close()
+		}
+		if closer, isCloser := cmdDescriptor.command.Stderr.(io.Closer); isCloser && cmdDescriptor.errToIn {
+			_ = closer.Close()
+		}
+	}
+	for cmdIndex := p.from; cmdIndex < p.to; cmdIndex++ {
+		if closer, isCloser := c.cmdDescriptors[cmdIndex].command.Stdin.(io.Closer); isCloser {
+			_ = closer.Close()
+		}
+	}
+}
+
+// streamErrorsWithout returns the chain's stream errors except the errors of the commands of the given pipelines.
+func (c *chain) streamErrorsWithout(pipelines []pipeline) MultipleErrors {
+	c.streamErrorsMutex.Lock()
+	defer c.streamErrorsMutex.Unlock()
+
+	result := streamErrors()
+	result.errors = make([]error, len(c.streamErrors.errors))
+
+	for cmdIndex, err := range c.streamErrors.errors {
+		isSkipped := false
+		for _, p := range pipelines {
+			if cmdIndex >= p.from && cmdIndex < p.to {
+				isSkipped = true
+				break
+			}
+		}
+
+		if !isSkipped {
+			result.setError(cmdIndex, err)
+		}
+	}
+
+	return result
+}
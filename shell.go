@@ -6,7 +6,10 @@ import (
 	"io"
 	"mvdan.cc/sh/v3/syntax"
 	"os/exec"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 )
 
 func (c *chain) JoinShellCmd(command string) CommandBuilder {
@@ -32,6 +35,37 @@ type shellChain struct {
 	chain   *chain
 }
 
+// joinedShellCmd is a shell command of a template and the range of commands (from inclusive, to exclusive) which
+// were joined by parsing it.
+type joinedShellCmd struct {
+	ctx     context.Context
+	command string
+	actions []func(CommandBuilder)
+
+	from int
+	to   int
+}
+
+// build builds the shell command into its chain. The parser will join the commands by itself. The stages, files and
+// states of the parsed commands belong to a single run - so the shell command of a template will be parsed again for
+// each of its instances (see instance).
+func (s *shellChain) build() CommandBuilder {
+	if s.chain.template {
+		joined := joinedShellCmd{
+			ctx:     s.ctx,
+			command: s.command,
+			actions: slices.Clone(s.actions),
+			from:    len(s.chain.cmdDescriptors),
+		}
+		defer func() {
+			joined.to = len(s.chain.cmdDescriptors)
+			s.chain.shellCommands = append(s.chain.shellCommands, joined)
+		}()
+	}
+
+	return buildShellChain(s)
+}
+
 var buildShellChain = func(s *shellChain) CommandBuilder {
 	var err error
 	defer func() {
@@ -44,6 +78,7 @@ var buildShellChain = func(s *shellChain) CommandBuilder {
 		chain:   s.chain,
 		ctx:     s.ctx,
 		actions: s.actions,
+		state:   &ShellState{},
 	}
 
 	parser.program, err = syntax.NewParser().Parse(strings.NewReader(s.command), "")
@@ -61,27 +96,55 @@ var buildShellChain = func(s *shellChain) CommandBuilder {
 ////
 
 func (s *shellChain) Join(name string, args ...string) CommandBuilder {
-	return buildShellChain(s).Join(name, args...)
+	return s.build().Join(name, args...)
 }
 
 func (s *shellChain) JoinCmd(cmd *exec.Cmd) CommandBuilder {
-	return buildShellChain(s).JoinCmd(cmd)
+	return s.build().JoinCmd(cmd)
 }
 
 func (s *shellChain) JoinWithContext(ctx context.Context, name string, args ...string) CommandBuilder {
-	return buildShellChain(s).JoinWithContext(ctx, name, args...)
+	return s.build().JoinWithContext(ctx, name, args...)
+}
+
+func (s *shellChain) JoinFunc(fn CommandFunc) CommandBuilder {
+	return s.build().JoinFunc(fn)
+}
+
+func (s *shellChain) JoinLineMapper(mapper func(line string) string) CommandBuilder {
+	return s.build().JoinLineMapper(mapper)
+}
+
+func (s *shellChain) JoinLineFilter(filter func(line string) bool) CommandBuilder {
+	return s.build().JoinLineFilter(filter)
+}
+
+func (s *shellChain) JoinRegexFilter(pattern *regexp.Regexp) CommandBuilder {
+	return s.build().JoinRegexFilter(pattern)
+}
+
+func (s *shellChain) JoinHead(n int) CommandBuilder {
+	return s.build().JoinHead(n)
+}
+
+func (s *shellChain) JoinTail(n int) CommandBuilder {
+	return s.build().JoinTail(n)
+}
+
+func (s *shellChain) JoinUniq() CommandBuilder {
+	return s.build().JoinUniq()
 }
 
 func (s *shellChain) JoinShellCmd(command string) CommandBuilder {
-	return buildShellChain(s).JoinShellCmd(command)
+	return s.build().JoinShellCmd(command)
 }
 
 func (s *shellChain) JoinShellCmdWithContext(ctx context.Context, command string) CommandBuilder {
-	return buildShellChain(s).JoinShellCmdWithContext(ctx, command)
+	return s.build().JoinShellCmdWithContext(ctx, command)
 }
 
 func (s *shellChain) Finalize() FinalizedBuilder {
-	return buildShellChain(s).Finalize()
+	return s.build().Finalize()
 }
 
 ////
@@ -213,3 +276,17 @@ func (s *shellChain) WithErrorChecker(checker ErrorChecker) CommandBuilder {
 	})
 	return s
 }
+
+func (s *shellChain) WithTimeout(timeout time.Duration) CommandBuilder {
+	s.actions = append(s.actions, func(c CommandBuilder) {
+		c.WithTimeout(timeout)
+	})
+	return s
+}
+
+func (s *shellChain) WithRetry(policy RetryPolicy) CommandBuilder {
+	s.actions = append(s.actions, func(c CommandBuilder) {
+		c.WithRetry(policy)
+	})
+	return s
+}
@@ -0,0 +1,227 @@
+package cmdchain
+
+import (
+	"fmt"
+	"maps"
+	"mvdan.cc/sh/v3/pattern"
+	"mvdan.cc/sh/v3/syntax"
+	"os"
+	"regexp"
+	"unicode/utf8"
+)
+
+var variableNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// lookupVariable returns the value of the variable with the given name. The variable will be searched
+// in the shell variables, the chain's variables and finally in the environment of the current process.
+func (s *shellParser) lookupVariable(name string) (string, bool) {
+	if value, found := s.variables[name]; found {
+		return value, true
+	}
+	if value, found := s.chain.variables[name]; found {
+		return value, true
+	}
+
+	return os.LookupEnv(name)
+}
+
+// variableLookup returns a function which looks up the variables such as lookupVariable. But the variables are
+// looked up as they are at this moment.
+func (s *shellParser) variableLookup() func(name string) (string, bool) {
+	parser := &shellParser{chain: s.chain, variables: maps.Clone(s.variables)}
+	return parser.lookupVariable
+}
+
+func (s *shellParser) setVariable(name, value string) {
+	if s.variables == nil {
+		s.variables = map[string]string{}
+	}
+	s.variables[name] = value
+}
+
+func (s *shellParser) expandParam(p *syntax.ParamExp) (string, error) {
+	if p.Excl || p.Width || p.Index != nil || p.Slice != nil || p.Repl != nil || p.Names != 0 {
+		return "", errorWithPos(p, "unsupported parameter expansion")
+	}
+
+	name := p.Param.Value
+	if !variableNamePattern.MatchString(name) {
+		return "", errorWithPos(p, fmt.Sprintf("unsupported special parameter '%s'", name))
+	}
+
+	value, isSet := s.lookupVariable(name)
+	if p.Length {
+		return fmt.Sprintf("%d", utf8.RuneCountInString(value)), nil
+	}
+	if p.Exp == nil {
+		return value, nil
+	}
+
+	switch p.Exp.Op {
+	case syntax.DefaultUnset, syntax.DefaultUnsetOrNull: // - and :-
+		if isSet && !(p.Exp.Op == syntax.DefaultUnsetOrNull && value == "") {
+			return value, nil
+		}
+		return s.convertWord(p.Exp.Word)
+	case syntax.AssignUnset, syntax.AssignUnsetOrNull: // = and :=
+		if isSet && !(p.Exp.Op == syntax.AssignUnsetOrNull && value == "") {
+			return value, nil
+		}
+
+		value, err := s.convertStaticWord(p.Exp.Word)
+		if err != nil {
+			return "", err
+		}
+		s.setVariable(name, value)
+		return value, nil
+	case syntax.AlternateUnset, syntax.AlternateUnsetOrNull: // + and :+
+		if !isSet || (p.Exp.Op == syntax.AlternateUnsetOrNull && value == "") {
+			return "", nil
+		}
+		return s.convertWord(p.Exp.Word)
+	case syntax.ErrorUnset, syntax.ErrorUnsetOrNull: // ? and :?
+		if isSet && !(p.Exp.Op == syntax.ErrorUnsetOrNull && value == "") {
+			return value, nil
+		}
+
+		message, err := s.convertStaticWord(p.Exp.Word)
+		if err != nil {
+			return "", err
+		}
+		if message == "" {
+			message = "parameter null or not set"
+		}
+		return "", errorWithPos(p, fmt.Sprintf("%s: %s", name, message))
+	case syntax.RemSmallSuffix, syntax.RemLargeSuffix, syntax.RemSmallPrefix, syntax.RemLargePrefix: // % %% # ##
+		expr, err := s.convertPattern(p.Exp.Word)
+		if err != nil {
+			return "", err
+		}
+		matcher, err := regexp.Compile(expr)
+		if err != nil {
+			return "", errorWithPos(p.Exp.Word, "invalid pattern", err)
+		}
+
+		suffix := p.Exp.Op == syntax.RemSmallSuffix || p.Exp.Op == syntax.RemLargeSuffix
+		small := p.Exp.Op == syntax.RemSmallSuffix || p.Exp.Op == syntax.RemSmallPrefix
+		return removePattern(value, matcher, suffix, small), nil
+	default:
+		return "", errorWithPos(p, fmt.Sprintf("unsupported parameter expansion operator '%s'", p.Exp.Op.String()))
+	}
+}
+
+// convertPattern converts the given word into a regular expression which matches the whole string. Quoted
+// parts of the word will be matched literally.
+func (s *shellParser) convertPattern(word *syntax.Word) (string, error) {
+	// the pattern must be known while the command line is parsed
+	defer s.disableCommandSubstitution()()
+
+	_, pat, err := s.expandWord(word)
+	if err != nil {
+		return "", err
+	}
+
+	expr, err := pattern.Regexp(pat, pattern.EntireString)
+	if err != nil {
+		return "", errorWithPos(word, "invalid pattern", err)
+	}
+	return expr, nil
+}
+
+// removePattern removes the shortest (small) or longest suffix or prefix of the value which is
+// matched by the given matcher.
+func removePattern(value string, matcher *regexp.Regexp, suffix, small bool) string {
+	for i := range len(value) + 1 {
+		cut := i
+		if suffix == small {
+			// shortest suffix and longest prefix will be found by starting at the end
+			cut = len(value) - i
+		}
+		if cut < len(value) && !utf8.RuneStart(value[cut]) {
+			continue
+		}
+
+		if suffix && matcher.MatchString(value[cut:]) {
+			return value[:cut]
+		}
+		if !suffix && matcher.MatchString(value[:cut]) {
+			return value[cut:]
+		}
+	}
+
+	return value
+}
+
+// substituteCommand prepares the statements of the command substitution as its own chain. This chain will be
+// executed right before the command is started. Until then, a placeholder will be used as substitution (see
+// commandSubstitution). Variables which are assigned inside the command substitution are not visible outside.
+func (s *shellParser) substituteCommand(cs *syntax.CmdSubst) (string, error) {
+	if s.commandSubstitutions == nil {
+		return "", errorWithPos(cs, "command substitution is only supported in arguments and assignments of commands")
+	}
+	if len(cs.Stmts) == 0 {
+		return "", nil
+	}
+
+	parser := s.nestedParser(cs.Stmts, maps.Clone(s.variables))
+
+	err := parser.Parse()
+	if err != nil {
+		return "", errorWithPos(cs, "error parsing command substitution", err)
+	}
+	if parser.chain.buildErrors.hasError {
+		return "", errorWithPos(cs, "error building command substitution", parser.chain.buildErrors)
+	}
+
+	substitution := &commandSubstitution{
+		chain: parser.chain,
+		node:  cs,
+		index: len(s.commandSubstitutions),
+	}
+	s.commandSubstitutions = append(s.commandSubstitutions, substitution)
+
+	return substitution.placeholder(), nil
+}
+
+// convertStaticWord converts the given word without command substitutions. They are not supported in words
+// whose value must be known while the command line is parsed (e.g. assigned default values).
+func (s *shellParser) convertStaticWord(word *syntax.Word) (string, error) {
+	defer s.disableCommandSubstitution()()
+
+	return s.convertWord(word)
+}
+
+// disableCommandSubstitution disables the command substitution until the returned function is called.
+func (s *shellParser) disableCommandSubstitution() (restore func()) {
+	substitutions := s.commandSubstitutions
+	s.commandSubstitutions = nil
+
+	return func() { s.commandSubstitutions = substitutions }
+}
+
+// substituteProcess prepares the statements of the process substitution as side chain. This side chain will be
+// connected with the command through a pipe. The path of this pipe will be used as substitution.
+func (s *shellParser) substituteProcess(ps *syntax.ProcSubst) (string, error) {
+	if s.processSubstitutions == nil {
+		return "", errorWithPos(ps, "process substitution is only supported in command arguments")
+	}
+
+	parser := s.nestedParser(ps.Stmts, maps.Clone(s.variables))
+
+	err := parser.Parse()
+	if err != nil {
+		return "", errorWithPos(ps, "error parsing process substitution", err)
+	}
+	if parser.chain.buildErrors.hasError {
+		return "", errorWithPos(ps, "error building process substitution", parser.chain.buildErrors)
+	}
+
+	substitution := &processSubstitution{
+		chain:     parser.chain,
+		output:    ps.Op == syntax.CmdOut,
+		extraFile: len(s.processSubstitutions),
+	}
+	s.processSubstitutions = append(s.processSubstitutions, substitution)
+
+	return substitution.path(), nil
+}
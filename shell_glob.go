@@ -0,0 +1,249 @@
+package cmdchain
+
+import (
+	"fmt"
+	"io/fs"
+	"mvdan.cc/sh/v3/pattern"
+	"mvdan.cc/sh/v3/syntax"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// GlobPolicy defines if the pathname expansion of shell commands (see ChainBuilder.JoinShellCmd) is enabled and
+// how patterns are handled which do not match any file.
+type GlobPolicy int
+
+const (
+	// GlobDisabled disables the pathname and tilde expansion. All arguments are passed literally to the command.
+	GlobDisabled GlobPolicy = iota
+
+	// GlobKeepUnmatched will pass patterns which do not match any file literally to the command. This is the
+	// default behaviour of the most shells.
+	GlobKeepUnmatched
+
+	// GlobNullUnmatched will remove patterns which do not match any file (such as the shell option "nullglob").
+	GlobNullUnmatched
+
+	// GlobFailUnmatched will cause an error for patterns which do not match any file (such as the shell option
+	// "failglob"). The patterns are expanded right before their command is started, so the command will not be
+	// started and the error is a start error (see ErrStart).
+	GlobFailUnmatched
+)
+
+// expandTilde splits a literal with a leading tilde (~ or ~user) into the home directory and the rest of the literal.
+func (s *shellParser) expandTilde(lit *syntax.Lit) (home, rest string, err error) {
+	prefix, _, _ := strings.Cut(lit.Value, "/")
+	if !strings.HasPrefix(prefix, "~") {
+		return "", lit.Value, nil
+	}
+	rest = lit.Value[len(prefix):]
+
+	if prefix == "~" {
+		var found bool
+		home, found = s.lookupVariable("HOME")
+		if !found {
+			home, err = os.UserHomeDir()
+		}
+	} else {
+		var u *user.User
+		u, err = user.Lookup(prefix[1:])
+		if err == nil {
+			home = u.HomeDir
+		}
+	}
+	if err != nil {
+		return "", "", errorWithPos(lit, "unable to expand tilde", err)
+	}
+
+	return home, rest, nil
+}
+
+// globExpansion returns a preparer which replaces all arguments of the command which contain a pattern with the
+// matching files. The patterns are resolved relative to the working directory of the command at this moment (it
+// includes the changes of builtins).
+func (s *shellParser) globExpansion(words []*syntax.Word, patterns []string) func(*exec.Cmd) error {
+	policy := s.chain.globPolicy
+
+	return func(command *exec.Cmd) error {
+		arguments := []string{command.Args[0]}
+
+		for i, pat := range patterns {
+			if !pattern.HasMeta(pat, 0) {
+				arguments = append(arguments, command.Args[i+1])
+				continue
+			}
+
+			// such as in a shell, an invalid pattern (e.g. "[") does not match any path. This is the only possible
+			// error of glob.
+			matches, _ := glob(command.Dir, pat)
+			if len(matches) > 0 {
+				arguments = append(arguments, matches...)
+				continue
+			}
+
+			switch policy {
+			case GlobNullUnmatched:
+			case GlobFailUnmatched:
+				return errorWithPos(words[i], fmt.Sprintf("no matches found for pattern '%s'", command.Args[i+1]))
+			default:
+				arguments = append(arguments, command.Args[i+1])
+			}
+		}
+
+		command.Args = arguments
+		return nil
+	}
+}
+
+// glob returns all paths which matches the given pattern. Relative patterns are resolved relative to the
+// given directory (or the current working directory if the directory is empty). The paths will be returned
+// in the same form as they are written in the pattern. The only possible error is filepath.ErrBadPattern (such as
+// filepath.Glob).
+func glob(dir, pat string) ([]string, error) {
+	matches := []string{""}
+	if strings.HasPrefix(pat, "/") {
+		matches = []string{"/"}
+	}
+
+	segments := strings.Split(strings.Trim(pat, "/"), "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+
+		var next []string
+		switch {
+		case segment == "**":
+			for _, match := range matches {
+				next = append(next, walkDirectory(dir, match, !last)...)
+			}
+		case pattern.HasMeta(segment, 0):
+			expr, err := pattern.Regexp(segment, pattern.EntireString)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", filepath.ErrBadPattern, err)
+			}
+			matcher := regexp.MustCompile(expr)
+
+			for _, match := range matches {
+				next = append(next, readDirectory(dir, match, matcher, strings.HasPrefix(segment, "."), !last)...)
+			}
+		default:
+			for _, match := range matches {
+				next = append(next, joinPath(match, unescapePattern(segment)))
+			}
+		}
+		matches = next
+	}
+
+	// the paths without pattern are not checked yet
+	matches = slices.DeleteFunc(matches, func(match string) bool {
+		if match == "" {
+			return true
+		}
+		_, err := os.Lstat(resolvePath(dir, match))
+		return err != nil
+	})
+	if strings.HasSuffix(pat, "/") {
+		for i := range matches {
+			matches[i] += "/"
+		}
+	}
+
+	slices.Sort(matches)
+	return slices.Compact(matches), nil
+}
+
+// readDirectory returns all entries of the directory which are matching the given matcher.
+func readDirectory(dir, path string, matcher *regexp.Regexp, withHidden, onlyDirs bool) []string {
+	entries, err := os.ReadDir(resolvePath(dir, path))
+	if err != nil {
+		return nil
+	}
+
+	var result []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") && !withHidden {
+			continue
+		}
+		if !matcher.MatchString(entry.Name()) {
+			continue
+		}
+		if onlyDirs && !isDirectory(dir, joinPath(path, entry.Name())) {
+			continue
+		}
+
+		result = append(result, joinPath(path, entry.Name()))
+	}
+
+	return result
+}
+
+// walkDirectory returns the given path and all (non-hidden) entries of the directory recursively (**).
+func walkDirectory(dir, path string, onlyDirs bool) []string {
+	result := []string{path}
+
+	root := resolvePath(dir, path)
+	_ = filepath.WalkDir(root, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil || current == root {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if onlyDirs && !entry.IsDir() {
+			return nil
+		}
+
+		relative, _ := filepath.Rel(root, current)
+		result = append(result, joinPath(path, filepath.ToSlash(relative)))
+		return nil
+	})
+
+	return result
+}
+
+func isDirectory(dir, path string) bool {
+	info, err := os.Stat(resolvePath(dir, path))
+	return err == nil && info.IsDir()
+}
+
+func resolvePath(dir, path string) string {
+	if path == "" {
+		path = "."
+	}
+	if filepath.IsAbs(path) || dir == "" {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	if strings.HasSuffix(path, "/") {
+		return path + name
+	}
+	return path + "/" + name
+}
+
+// unescapePattern removes the escaping backslashes of a pattern.
+func unescapePattern(pat string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range pat {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
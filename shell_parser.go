@@ -4,8 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
+	"mvdan.cc/sh/v3/pattern"
 	"mvdan.cc/sh/v3/syntax"
 	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
 )
 
 type shellParser struct {
@@ -13,6 +19,38 @@ type shellParser struct {
 	ctx     context.Context
 	chain   *chain
 	actions []func(CommandBuilder)
+
+	// variables contains the shell variables which are assigned while parsing the command line
+	variables map[string]string
+
+	// processSubstitutions contains the process substitutions of the current command's arguments
+	processSubstitutions []*processSubstitution
+
+	// commandSubstitutions contains the command substitutions of the current command's arguments and assignments
+	commandSubstitutions []*commandSubstitution
+
+	// state contains the state which is changed by builtins (e.g. the working directory) while the chain is running
+	state *ShellState
+}
+
+// nestedParser returns a parser for the given statements. These statements will be joined into their own chain.
+func (s *shellParser) nestedParser(stmts []*syntax.Stmt, variables map[string]string) *shellParser {
+	parser := &shellParser{
+		program: &syntax.File{Stmts: stmts},
+		ctx:     s.ctx,
+		chain: Builder().
+			WithVariables(s.chain.variables).
+			WithGlobExpansion(s.chain.globPolicy).
+			WithBuiltins(s.chain.builtins).(*chain),
+		variables: variables,
+		// the nested state is relative to the state of the command which contains the nested chain
+		state: &ShellState{},
+	}
+
+	// the nested chain will be run by its parent (e.g. see groupStage) - so it is only a template if its parent is one
+	parser.chain.template = s.chain.template
+
+	return parser
 }
 
 func (s *shellParser) applyActions() {
@@ -25,18 +63,33 @@ func (s *shellParser) Parse() error {
 	if len(s.program.Stmts) == 0 {
 		return fmt.Errorf("no statements")
 	}
-	if len(s.program.Stmts) > 1 {
-		return fmt.Errorf("multiple statements are not supported, found %d statements", len(s.program.Stmts))
-	}
-	if s.program.Stmts[0].Background {
-		return fmt.Errorf("background execution is not supported")
-	}
 
-	err := s.handleCommand(s.program.Stmts[0].Cmd, s.program.Stmts[0].Redirs)
-	if err != nil {
-		return err
+	joined := false
+	for _, stmt := range s.program.Stmts {
+		if stmt.Background {
+			return fmt.Errorf("background execution is not supported")
+		}
+		if isAssignmentOnly(stmt.Cmd) {
+			err := s.handleVariableAssigns(stmt.Cmd.(*syntax.CallExpr), stmt.Redirs)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if joined {
+			s.chain.beginSequence(sequenceAlways)
+		}
+
+		err := s.handleCommand(stmt.Cmd, stmt.Redirs)
+		if err != nil {
+			return err
+		}
+		joined = true
 	}
 
+	if !joined {
+		return fmt.Errorf("no commands")
+	}
 	return nil
 }
 
@@ -46,51 +99,243 @@ func (s *shellParser) handleCommand(cmd syntax.Command, redirs []*syntax.Redirec
 		return s.handleCall(c, redirs)
 	case *syntax.BinaryCmd:
 		return s.handleBinary(c)
+	case *syntax.DeclClause:
+		return s.handleDecl(c, redirs)
+	case *syntax.Subshell:
+		return s.handleGroup(c, c.Stmts, redirs, true)
+	case *syntax.Block:
+		return s.handleGroup(c, c.Stmts, redirs, false)
 	default:
 		return errorWithPos(c, "unsupported command")
 	}
 }
 
 func (s *shellParser) handleCall(c *syntax.CallExpr, redirs []*syntax.Redirect) error {
-	commandName, arguments, err := s.extractCommandAndArgs(c.Args)
+	if isAssignmentOnly(c) {
+		return errorWithPos(c, "assignments without command are only supported as separate statements")
+	}
+
+	// process substitutions are only allowed inside the command's arguments
+	s.processSubstitutions = []*processSubstitution{}
+	// command substitutions are only allowed inside the command's arguments and assignments
+	s.commandSubstitutions = []*commandSubstitution{}
+	defer func() { s.commandSubstitutions = nil }()
+
+	commandName, arguments, patterns, err := s.extractCommandAndArgs(c.Args)
+	processSubstitutions := s.processSubstitutions
+	s.processSubstitutions = nil
+
 	if err != nil {
 		return errorWithPos(c, "error extracting command and arguments", err)
 	}
+	if strings.Contains(commandName, substitutionMarker) {
+		return errorWithPos(c.Args[0], "command substitution is not supported in the command name")
+	}
+	for i, argument := range arguments {
+		if strings.Contains(argument, substitutionMarker) {
+			// the output of the command substitution will not be expanded
+			patterns[i] = pattern.QuoteMeta(argument, 0)
+		}
+	}
 
-	if s.ctx == nil {
+	if builtin, isBuiltin := s.lookupBuiltin(commandName); isBuiltin {
+		if len(processSubstitutions) > 0 {
+			// the pipes are passed as file descriptors of the command's process. But a builtin has no own process.
+			return errorWithPos(c, fmt.Sprintf("process substitution is not supported for the builtin '%s'", commandName))
+		}
+
+		call := shellBuiltin(s.ctx, builtin, s.state, s.variableLookup())
+		s.chain = s.chain.joinStage(s.ctx, call, commandName, arguments...).(*chain)
+	} else if s.ctx == nil {
 		s.chain = s.chain.Join(commandName, arguments...).(*chain)
 	} else {
 		s.chain = s.chain.JoinWithContext(s.ctx, commandName, arguments...).(*chain)
 	}
 
-	err = s.handleAssigns(c.Assigns)
+	env, err := s.handleAssigns(c.Assigns)
 	if err != nil {
 		return err
 	}
 
+	commandSubstitutions := s.commandSubstitutions
+	s.commandSubstitutions = nil
+
+	// the state must be applied before the substitutions are executed (they inherit the working directory)
+	s.applyState(env)
+
+	if len(commandSubstitutions) > 0 {
+		s.chain.prepareBeforeStart(substituteCommands(s.chain, commandSubstitutions))
+	}
+
+	for _, substitution := range processSubstitutions {
+		s.chain.addHook(substitution)
+		s.chain.ApplyBeforeStart(substitution.applier(s.chain))
+	}
+
 	err = s.handleRedirects(redirs)
 	if err != nil {
 		return err
 	}
 
 	s.applyActions()
+
+	if s.chain.globPolicy != GlobDisabled {
+		// the patterns must be resolved right before the command is started (the working directory could be
+		// changed by builtins)
+		s.chain.prepareBeforeStart(s.globExpansion(c.Args[1:], patterns))
+	}
+
+	return nil
+}
+
+// handleDecl handles a declaration (e.g. `export A=1 B`). The parser treats such declarations not as command. So
+// it will be converted into a call of the builtin with the same name.
+func (s *shellParser) handleDecl(d *syntax.DeclClause, redirs []*syntax.Redirect) error {
+	call := &syntax.CallExpr{Args: []*syntax.Word{{Parts: []syntax.WordPart{d.Variant}}}}
+
+	for _, assign := range d.Args {
+		if assign.Append || assign.Index != nil || assign.Array != nil {
+			return errorWithPos(assign, "unsupported assignment")
+		}
+
+		word := &syntax.Word{}
+		if assign.Name != nil {
+			name := *assign.Name
+			if !assign.Naked {
+				name.Value += "="
+			}
+			word.Parts = append(word.Parts, &name)
+		}
+		if assign.Value != nil {
+			word.Parts = append(word.Parts, assign.Value.Parts...)
+		}
+		call.Args = append(call.Args, word)
+	}
+
+	err := s.handleCall(call, redirs)
+	if err != nil {
+		return err
+	}
+
+	// the variables are expanded while the command line is parsed. So the declared variables are visible for the
+	// following statements regardless of whether the declaration will be executed.
+	command := s.chain.cmdDescriptors[len(s.chain.cmdDescriptors)-1].command
+	for _, arg := range command.Args[1:] {
+		name, value, isAssign := strings.Cut(arg, "=")
+		if !isAssign || !variableNamePattern.MatchString(name) {
+			continue
+		}
+
+		if strings.Contains(value, substitutionMarker) {
+			// the output of a command substitution is not known until the declaration is executed
+			continue
+		}
+		s.setVariable(name, value)
+	}
 	return nil
 }
 
+// applyState applies the state (changed by builtins) to the last joined command right before it is started. The
+// given environment variables (key=value) of the command itself will take precedence over the state's environment.
+func (s *shellParser) applyState(env []string) {
+	state := s.state
+	s.chain.prepareBeforeStart(func(command *exec.Cmd) error {
+		state.apply(command, env)
+		return nil
+	})
+}
+
+// handleGroup handles a subshell (e.g. `( a | b )`) or a brace group (e.g. `{ a; b; }`). The group will be a single
+// stage of the chain. Inside the group, the statements are executed as their own chain. Variables which are assigned
+// inside a subshell are not visible outside.
+func (s *shellParser) handleGroup(group syntax.Command, stmts []*syntax.Stmt, redirs []*syntax.Redirect, subshell bool) error {
+	if s.variables == nil {
+		s.variables = map[string]string{}
+	}
+
+	variables := s.variables
+	if subshell {
+		variables = maps.Clone(s.variables)
+	}
+
+	parser := s.nestedParser(stmts, variables)
+
+	err := parser.Parse()
+	if err != nil {
+		return errorWithPos(group, "error parsing group", err)
+	}
+	if parser.chain.buildErrors.hasError {
+		return errorWithPos(group, "error building group", parser.chain.buildErrors)
+	}
+
+	name := strings.Builder{}
+	err = syntax.NewPrinter(syntax.SingleLine(true)).Print(&name, group)
+	if err != nil {
+		return errorWithPos(group, "error printing group", err)
+	}
+
+	stage := &groupStage{chain: parser.chain, state: parser.state}
+	if !subshell {
+		// the state changes of a brace group are visible outside
+		stage.parent = s.state
+	}
+
+	s.chain = s.chain.joinStage(s.ctx, stage, name.String()).(*chain)
+	s.applyState(nil)
+
+	err = s.handleRedirects(redirs)
+	if err != nil {
+		return err
+	}
+
+	s.applyActions()
+	return nil
+}
+
+// redirectionState contains the destination of a command's output file descriptor (1 or 2) while the
+// redirects are processed.
+type redirectionState struct {
+	// target is the file descriptor (1 = stdout, 2 = stderr) where the output will be written into
+	target string
+	files  []io.Writer
+}
+
 func (s *shellParser) handleRedirects(redirs []*syntax.Redirect) (err error) {
-	var outputStreams []io.Writer
-	var errorStreams []io.Writer
+	var input io.Reader
+	stdout := redirectionState{target: "1"}
+	stderr := redirectionState{target: "2"}
 
 	for _, redir := range redirs {
 		switch redir.Op {
+		case syntax.RdrIn: // <
+		case syntax.Hdoc: // <<
+		case syntax.DashHdoc: // <<-
+		case syntax.WordHdoc: // <<<
 		case syntax.RdrAll: // &>
 		case syntax.AppAll: // &>>
 		case syntax.RdrOut: // >
 		case syntax.AppOut: // >>
+		case syntax.DplOut: // >&
 		default:
 			return errorWithPos(redir, fmt.Sprintf("unsupported redirection operator '%s'", redir.Op.String()))
 		}
 
+		if isInputRedirect(redir) {
+			// such as in a shell, the last input redirection wins
+			input, err = s.setupInputStream(redir)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if redir.Op == syntax.DplOut {
+			err = s.duplicateStream(redir, &stdout, &stderr)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
 		var targetFile *lazyFile
 		targetFile, err = s.setupStream(redir)
 		if err != nil {
@@ -101,21 +346,160 @@ func (s *shellParser) handleRedirects(redirs []*syntax.Redirect) (err error) {
 		s.chain.addHook(targetFile)
 
 		if redir.Op == syntax.RdrAll || redir.Op == syntax.AppAll {
-			errorStreams = append(errorStreams, targetFile)
-			outputStreams = append(outputStreams, targetFile)
+			stderr.files = append(stderr.files, targetFile)
+			stdout.files = append(stdout.files, targetFile)
 		} else if redir.N != nil && redir.N.Value == "2" {
-			errorStreams = append(errorStreams, targetFile)
+			stderr.files = append(stderr.files, targetFile)
 		} else {
-			outputStreams = append(outputStreams, targetFile)
+			stdout.files = append(stdout.files, targetFile)
 		}
 	}
 
-	s.chain.WithOutputForks(outputStreams...)
-	s.chain.WithErrorForks(errorStreams...)
+	if input != nil {
+		s.chain.redirectInput(input)
+	}
+	s.chain.WithOutputForks(stdout.files...)
+	s.chain.WithErrorForks(stderr.files...)
+
+	if stderr.target == "1" {
+		// stderr should be written where stdout will be written
+		s.chain.ForwardError()
+		s.chain.ApplyBeforeStart(s.redirectOutput(stdout.files, stderr.files, func(cmdDesc *cmdDescriptor) outputRedirection {
+			return outputRedirection{
+				source:  &cmdDesc.command.Stderr,
+				target:  &cmdDesc.command.Stdout,
+				streams: cmdDesc.outputStreams,
+				piped:   cmdDesc.outToIn,
+			}
+		}))
+	}
+	if stdout.target == "2" {
+		// stdout should be written where stderr will be written
+		s.chain.DiscardStdOut()
+		s.chain.ApplyBeforeStart(s.redirectOutput(stderr.files, stdout.files, func(cmdDesc *cmdDescriptor) outputRedirection {
+			return outputRedirection{
+				source:  &cmdDesc.command.Stdout,
+				target:  &cmdDesc.command.Stderr,
+				streams: cmdDesc.errorStreams,
+				piped:   cmdDesc.errToIn,
+			}
+		}))
+	}
 
 	return nil
 }
 
+// outputRedirection describes the redirection of the command's source stream into the destination
+// of the command's target stream.
+type outputRedirection struct {
+	source  *io.Writer
+	target  *io.Writer
+	streams []io.Writer
+	piped   bool
+}
+
+// redirectOutput returns a CommandApplier which will redirect one output stream of the command into the destination
+// of the other one. The destination is the target stream without the files which were redirected after the
+// duplication (targetOnly). The files which are only redirected by the source stream (sourceOnly) will be added.
+func (s *shellParser) redirectOutput(targetFiles, sourceFiles []io.Writer, redirection func(*cmdDescriptor) outputRedirection) CommandApplier {
+	cmdChain := s.chain
+	targetOnly := filesWithout(targetFiles, sourceFiles)
+	sourceOnly := filesWithout(sourceFiles, targetFiles)
+
+	return func(index int, _ *exec.Cmd) {
+		r := redirection(&cmdChain.cmdDescriptors[index])
+
+		if len(targetOnly) == 0 {
+			// write into the same stream - so the order of the written output will be kept
+			*r.source = combineWriters(*r.target, sourceOnly...)
+		} else if !r.piped || cmdChain.isPipelineEnd(index) {
+			// both streams can write into the same destination at the same time
+			mutex := &sync.Mutex{}
+			*r.target = newLockedWriter(mutex, *r.target)
+			*r.source = newLockedWriter(mutex, combineWriters(nil, append(filesWithout(r.streams, targetOnly), sourceOnly...)...))
+		}
+
+		// otherwise the target stream is piped to the next command. The (forwarded) source stream
+		// will be combined with it there.
+	}
+}
+
+// lockedWriter prevents concurrent writes of multiple writers which share the same destination.
+type lockedWriter struct {
+	mutex    *sync.Mutex
+	delegate io.Writer
+}
+
+func newLockedWriter(mutex *sync.Mutex, delegate io.Writer) io.Writer {
+	if delegate == nil {
+		return nil
+	}
+	return &lockedWriter{mutex: mutex, delegate: delegate}
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.delegate.Write(p)
+}
+
+// duplicateStream handles the file descriptor duplication (e.g. 2>&1 or 1>&2). The duplicated file
+// descriptor will write into the same destination as the other file descriptor at this moment.
+func (s *shellParser) duplicateStream(redir *syntax.Redirect, stdout, stderr *redirectionState) error {
+	source := "1"
+	if redir.N != nil {
+		source = redir.N.Value
+	}
+
+	target, err := s.convertWord(redir.Word)
+	if err != nil {
+		return errorWithPos(redir, "error converting file descriptor", err)
+	}
+
+	switch {
+	case (source == "1" || source == "2") && source == target:
+		// nothing to do (e.g. 1>&1)
+	case source == "2" && target == "1":
+		*stderr = redirectionState{
+			target: stdout.target,
+			files:  append([]io.Writer{}, stdout.files...),
+		}
+	case source == "1" && target == "2":
+		*stdout = redirectionState{
+			target: stderr.target,
+			files:  append([]io.Writer{}, stderr.files...),
+		}
+	default:
+		return errorWithPos(redir, fmt.Sprintf("unsupported file descriptor duplication '%s>&%s'", source, target))
+	}
+
+	return nil
+}
+
+// filesWithout returns all files which are not contained in the given exclusions.
+func filesWithout(files []io.Writer, exclusions []io.Writer) (result []io.Writer) {
+	for _, file := range files {
+		if !slices.Contains(exclusions, file) {
+			result = append(result, file)
+		}
+	}
+	return
+}
+
+func combineWriters(writer io.Writer, additional ...io.Writer) io.Writer {
+	if writer != nil {
+		additional = append([]io.Writer{writer}, additional...)
+	}
+
+	if len(additional) == 0 {
+		return nil
+	} else if len(additional) == 1 {
+		return additional[0]
+	}
+	return io.MultiWriter(additional...)
+}
+
 func (s *shellParser) setupStream(redir *syntax.Redirect) (*lazyFile, error) {
 	target, err := s.convertWord(redir.Word)
 	if err != nil {
@@ -142,28 +526,126 @@ func (s *shellParser) setupStream(redir *syntax.Redirect) (*lazyFile, error) {
 	return newLazyFile(target, flag, 0644), nil
 }
 
-func (s *shellParser) handleAssigns(assigns []*syntax.Assign) error {
+func isInputRedirect(redir *syntax.Redirect) bool {
+	switch redir.Op {
+	case syntax.RdrIn, syntax.Hdoc, syntax.DashHdoc, syntax.WordHdoc:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *shellParser) setupInputStream(redir *syntax.Redirect) (io.Reader, error) {
+	if redir.N != nil && redir.N.Value != "0" {
+		return nil, errorWithPos(redir, fmt.Sprintf("unsupported input redirection of file descriptor %s", redir.N.Value))
+	}
+
+	switch redir.Op {
+	case syntax.Hdoc, syntax.DashHdoc:
+		content, err := s.convertWord(redir.Hdoc)
+		if err != nil {
+			return nil, errorWithPos(redir, "error converting here-document", err)
+		}
+		if redir.Op == syntax.DashHdoc {
+			// leading tab characters are stripped from each line
+			lines := strings.Split(content, "\n")
+			for i := range lines {
+				lines[i] = strings.TrimLeft(lines[i], "\t")
+			}
+			content = strings.Join(lines, "\n")
+		}
+
+		return newHereDocument(content, "here-document"), nil
+	case syntax.WordHdoc:
+		content, err := s.convertWord(redir.Word)
+		if err != nil {
+			return nil, errorWithPos(redir, "error converting here-string", err)
+		}
+
+		return newHereDocument(content+"\n", "here-string"), nil
+	default:
+		source, err := s.convertWord(redir.Word)
+		if err != nil {
+			return nil, errorWithPos(redir, "error converting input redirection source", err)
+		}
+		if source == "" {
+			return nil, errorWithPos(redir, "missing input redirection source")
+		}
+
+		sourceFile := newLazyFile(source, os.O_RDONLY, 0)
+
+		// register file-hook to ensure the file is closed after command execution
+		s.chain.addHook(sourceFile)
+
+		return sourceFile, nil
+	}
+}
+
+// handleAssigns handles the assignments of a command (e.g. `A=1 B=$A command`). Such as in a shell, an assignment
+// is visible for the following assignments of the same command, but neither for the command's arguments nor for the
+// following statements.
+func (s *shellParser) handleAssigns(assigns []*syntax.Assign) ([]string, error) {
 	var env []string
 
+	variables := s.variables
+	s.variables = maps.Clone(variables)
+	defer func() { s.variables = variables }()
+
 	for _, assign := range assigns {
-		if assign.Value == nil && assign.Array == nil && assign.Index == nil {
-			// This is a simple assignment without value, e.g., `VAR=`
-			env = append(env, fmt.Sprintf("%s=", assign.Name.Value))
-		} else if assign.Value != nil {
-			value, err := s.convertWord(assign.Value)
-			if err != nil {
-				return errorWithPos(assign, "error converting assignment value", err)
-			}
-			env = append(env, fmt.Sprintf("%s=%s", assign.Name.Value, value))
-		} else {
-			return errorWithPos(assign, "unsupported assignment")
+		value, err := s.convertAssign(assign)
+		if err != nil {
+			return nil, err
+		}
+		s.setVariable(assign.Name.Value, value)
+		env = append(env, fmt.Sprintf("%s=%s", assign.Name.Value, value))
+	}
+
+	if len(env) > 0 {
+		// otherwise, the command would get an own environment and could not inherit one (e.g. of a group)
+		s.chain.WithAdditionalEnvironmentPairs(env...)
+	}
+	return env, nil
+}
+
+// handleVariableAssigns handles a statement which only consists of assignments (e.g. `VAR=value`). These
+// assignments will not be passed to any command's environment. Instead, they are available for the
+// variable expansion of the following statements.
+func (s *shellParser) handleVariableAssigns(c *syntax.CallExpr, redirs []*syntax.Redirect) error {
+	if len(redirs) > 0 {
+		return errorWithPos(redirs[0], "redirection without command is not supported")
+	}
+
+	for _, assign := range c.Assigns {
+		value, err := s.convertAssign(assign)
+		if err != nil {
+			return err
 		}
+		s.setVariable(assign.Name.Value, value)
 	}
 
-	s.chain.WithAdditionalEnvironmentPairs(env...)
 	return nil
 }
 
+func (s *shellParser) convertAssign(assign *syntax.Assign) (string, error) {
+	if assign.Value == nil && assign.Array == nil && assign.Index == nil {
+		// This is a simple assignment without value, e.g., `VAR=`
+		return "", nil
+	} else if assign.Value != nil {
+		value, err := s.convertWord(assign.Value)
+		if err != nil {
+			return "", errorWithPos(assign, "error converting assignment value", err)
+		}
+		return value, nil
+	}
+
+	return "", errorWithPos(assign, "unsupported assignment")
+}
+
+func isAssignmentOnly(cmd syntax.Command) bool {
+	call, isCall := cmd.(*syntax.CallExpr)
+	return isCall && len(call.Args) == 0 && len(call.Assigns) > 0
+}
+
 func (s *shellParser) handleBinary(b *syntax.BinaryCmd) error {
 	if err := s.handleCommand(b.X.Cmd, b.X.Redirs); err != nil {
 		return err
@@ -173,6 +655,10 @@ func (s *shellParser) handleBinary(b *syntax.BinaryCmd) error {
 	case syntax.Pipe: // |
 	case syntax.PipeAll: // |&
 		s.chain = s.chain.ForwardError().(*chain)
+	case syntax.AndStmt: // &&
+		s.chain.beginSequence(sequenceOnSuccess)
+	case syntax.OrStmt: // ||
+		s.chain.beginSequence(sequenceOnFailure)
 	default:
 		return errorWithPos(b, fmt.Sprintf("unsupported binary operator '%s' at '%s'", b.Op.String(), b.OpPos.String()))
 	}
@@ -180,21 +666,19 @@ func (s *shellParser) handleBinary(b *syntax.BinaryCmd) error {
 	return s.handleCommand(b.Y.Cmd, b.Y.Redirs)
 }
 
-func (s *shellParser) extractCommandAndArgs(words []*syntax.Word) (commandName string, arguments []string, err error) {
+func (s *shellParser) extractCommandAndArgs(words []*syntax.Word) (commandName string, arguments, patterns []string, err error) {
 	for i := range words {
+		var value, pat string
+		value, pat, err = s.expandWord(words[i])
+		if err != nil {
+			return
+		}
+
 		if i == 0 {
-			commandName, err = s.convertWord(words[i])
-			if err != nil {
-				return
-			}
+			commandName = value
 		} else {
-			var argument string
-			argument, err = s.convertWord(words[i])
-			if err != nil {
-				return
-			}
-
-			arguments = append(arguments, argument)
+			arguments = append(arguments, value)
+			patterns = append(patterns, pat)
 		}
 	}
 
@@ -214,6 +698,44 @@ func (s *shellParser) convertWord(word *syntax.Word) (string, error) {
 	return s.convertWordParts(word.Parts)
 }
 
+// expandWord converts the given word. Additionally, it returns the word as shell pattern. In this pattern all
+// quoted parts are escaped. If the glob expansion is enabled, a leading tilde will be expanded.
+func (s *shellParser) expandWord(word *syntax.Word) (value, pat string, err error) {
+	if word == nil {
+		return
+	}
+
+	for i, part := range word.Parts {
+		var r string
+		if lit, isLit := part.(*syntax.Lit); isLit && i == 0 && s.chain.globPolicy != GlobDisabled {
+			var home string
+			home, r, err = s.expandTilde(lit)
+			if err != nil {
+				return
+			}
+
+			value += home + r
+			pat += pattern.QuoteMeta(home, 0) + r
+			continue
+		}
+
+		r, err = s.convertWordParts([]syntax.WordPart{part})
+		if err != nil {
+			return
+		}
+
+		value += r
+		switch part.(type) {
+		case *syntax.SglQuoted, *syntax.DblQuoted:
+			pat += pattern.QuoteMeta(r, 0)
+		default:
+			pat += r
+		}
+	}
+
+	return
+}
+
 func (s *shellParser) convertWordParts(parts []syntax.WordPart) (result string, err error) {
 	for i := range parts {
 		switch part := parts[i].(type) {
@@ -228,6 +750,30 @@ func (s *shellParser) convertWordParts(parts []syntax.WordPart) (result string,
 				return
 			}
 
+			result += r
+		case *syntax.ParamExp:
+			var r string
+			r, err = s.expandParam(part)
+			if err != nil {
+				return
+			}
+
+			result += r
+		case *syntax.CmdSubst:
+			var r string
+			r, err = s.substituteCommand(part)
+			if err != nil {
+				return
+			}
+
+			result += r
+		case *syntax.ProcSubst:
+			var r string
+			r, err = s.substituteProcess(part)
+			if err != nil {
+				return
+			}
+
 			result += r
 		default:
 			err = errorWithPos(part, "unsupported word")
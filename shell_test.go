@@ -2,11 +2,16 @@ package cmdchain
 
 import (
 	"bytes"
+	"context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"io"
+	"os"
 	"os/exec"
+	"path"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -147,19 +152,95 @@ func TestJoinShellCmd(t *testing.T) {
 			expectError: "no statements",
 		},
 		{
-			name:        "multiple statements",
-			command:     `date; date`,
-			expectError: "multiple statements are not supported, found 2 statements",
+			name:    "multiple statements",
+			command: `date; date`,
+			expectedString: `
+[SO]               ╿
+[CM] /usr/bin/date ╡
+[SE]               ╽
+[OP] ;
+[SO]               ╿
+[CM] /usr/bin/date ╡
+[SE]               ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				assert.Equal(t, []sequence{{start: 1, operator: sequenceAlways}}, c.sequences)
+			},
+		},
+		{
+			name: "multiple statements (newline)",
+			command: `mkdir -p out
+echo test | gzip > out/x.gz`,
+			expectedString: `
+[SO]                           ╿
+[CM] /usr/bin/mkdir "-p" "out" ╡
+[SE]                           ╽
+[OP] ;
+[OS]                                       ╭ out/x.gz
+[SO]                      ╭╮               │
+[CM] /usr/bin/echo "test" ╡╰ /usr/bin/gzip ╡
+[SE]                      ╽                ╽
+`,
 		},
 		{
-			name:        "logical OR concatenation",
-			command:     `date || date`,
-			expectError: "[1:1 - 1:13] unsupported binary operator '||' at '1:6'",
+			name:        "multiple statements with background execution",
+			command:     `date; date &`,
+			expectError: "background execution is not supported",
+		},
+		{
+			name:    "logical OR concatenation",
+			command: `date || date`,
+			expectedString: `
+[SO]               ╿
+[CM] /usr/bin/date ╡
+[SE]               ╽
+[OP] ||
+[SO]               ╿
+[CM] /usr/bin/date ╡
+[SE]               ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				assert.Equal(t, []sequence{{start: 1, operator: sequenceOnFailure}}, c.sequences)
+			},
+		},
+		{
+			name:    "logical AND concatenation",
+			command: `date && date`,
+			expectedString: `
+[SO]               ╿
+[CM] /usr/bin/date ╡
+[SE]               ╽
+[OP] &&
+[SO]               ╿
+[CM] /usr/bin/date ╡
+[SE]               ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				assert.Equal(t, []sequence{{start: 1, operator: sequenceOnSuccess}}, c.sequences)
+			},
 		},
 		{
-			name:        "logical AND concatenation",
-			command:     `date && date`,
-			expectError: "[1:1 - 1:13] unsupported binary operator '&&' at '1:6'",
+			name:    "logical concatenation with pipes",
+			command: `echo test | grep test && date || echo "Hello" | wc -l`,
+			expectedString: `
+[SO]                      ╭╮                      ╿
+[CM] /usr/bin/echo "test" ╡╰ /usr/bin/grep "test" ╡
+[SE]                      ╽                       ╽
+[OP] &&
+[SO]               ╿
+[CM] /usr/bin/date ╡
+[SE]               ╽
+[OP] ||
+[SO]                       ╭╮                  ╿
+[CM] /usr/bin/echo "Hello" ╡╰ /usr/bin/wc "-l" ╡
+[SE]                       ╽                   ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				assert.Equal(t, []sequence{
+					{start: 2, operator: sequenceOnSuccess},
+					{start: 3, operator: sequenceOnFailure},
+				}, c.sequences)
+			},
 		},
 		{
 			name:        "background execution",
@@ -254,6 +335,193 @@ func TestJoinShellCmd(t *testing.T) {
 [ES]               ╰  /tmp/out (appending)
 `,
 		},
+		{
+			name:    "input redirection",
+			command: `grep 'Hello' < /tmp/in`,
+			expectedString: `
+[IS] /tmp/in ╮
+[OS]         │
+[SO]         │                       ╿
+[CM]         ╰ /usr/bin/grep "Hello" ╡
+[SE]                                 ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				assert.Len(t, c.hooks, 1)
+			},
+		},
+		{
+			name:    "input redirection in pipe",
+			command: `date | grep 'Hello' 0< /tmp/in`,
+			expectedString: `
+[IS]       /tmp/in ╮
+[OS]               │
+[SO]               ├╮                       ╿
+[CM] /usr/bin/date ╡╰ /usr/bin/grep "Hello" ╡
+[SE]               ╽                        ╽
+`,
+		},
+		{
+			name:        "input redirection of unsupported file descriptor",
+			command:     `grep 'Hello' 3< /tmp/in`,
+			expectError: "unsupported input redirection of file descriptor 3",
+		},
+		{
+			name:    "here-string",
+			command: `grep 'Hello' <<< "Hello, World!"`,
+			expectedString: `
+[IS] here-string ╮
+[OS]             │
+[SO]             │                       ╿
+[CM]             ╰ /usr/bin/grep "Hello" ╡
+[SE]                                     ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				content, err := io.ReadAll(c.cmdDescriptors[0].inputStreams[0])
+				require.NoError(t, err)
+				assert.Equal(t, "Hello, World!\n", string(content))
+			},
+		},
+		{
+			name: "here-document",
+			command: `grep 'Hello' <<EOF
+Hello,
+	World!
+EOF`,
+			expectedString: `
+[IS] here-document ╮
+[OS]               │
+[SO]               │                       ╿
+[CM]               ╰ /usr/bin/grep "Hello" ╡
+[SE]                                       ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				content, err := io.ReadAll(c.cmdDescriptors[0].inputStreams[0])
+				require.NoError(t, err)
+				assert.Equal(t, "Hello,\n\tWorld!\n", string(content))
+			},
+		},
+		{
+			name: "here-document (strip tabs)",
+			command: `grep 'Hello' <<-EOF
+	Hello,
+		World!
+	EOF`,
+			expectedString: `
+[IS] here-document ╮
+[OS]               │
+[SO]               │                       ╿
+[CM]               ╰ /usr/bin/grep "Hello" ╡
+[SE]                                       ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				content, err := io.ReadAll(c.cmdDescriptors[0].inputStreams[0])
+				require.NoError(t, err)
+				assert.Equal(t, "Hello,\nWorld!\n", string(content))
+			},
+		},
+		{
+			name:    "duplicate stderr to stdout",
+			command: `date 2>&1 | grep 'Hello'`,
+			expectedString: `
+[SO]               ╭╮                       ╿
+[CM] /usr/bin/date ╡╞ /usr/bin/grep "Hello" ╡
+[SE]               ╰╯                       ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				assert.True(t, c.cmdDescriptors[0].outToIn)
+				assert.True(t, c.cmdDescriptors[0].errToIn)
+				assert.Len(t, c.cmdDescriptors[0].commandApplier, 1)
+			},
+		},
+		{
+			name:    "duplicate stdout to stderr",
+			command: `date >&2 |& grep 'Hello'`,
+			expectedString: `
+[SO]               ╿                        ╿
+[CM] /usr/bin/date ╡╭ /usr/bin/grep "Hello" ╡
+[SE]               ╰╯                       ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				assert.False(t, c.cmdDescriptors[0].outToIn)
+				assert.True(t, c.cmdDescriptors[0].errToIn)
+				assert.Len(t, c.cmdDescriptors[0].commandApplier, 1)
+			},
+		},
+		{
+			name:    "file redirection and duplicate stderr to stdout",
+			command: `date > /tmp/out 2>&1 | grep 'Hello'`,
+			expectedString: `
+[OS]               ╭  /tmp/out
+[SO]               ├╮                       ╿
+[CM] /usr/bin/date ╡╞ /usr/bin/grep "Hello" ╡
+[SE]               ├╯                       ╽
+[ES]               ╰  /tmp/out
+`,
+		},
+		{
+			name:    "duplicate stderr to stdout and file redirection",
+			command: `date 2>&1 > /tmp/out | grep 'Hello'`,
+			expectedString: `
+[OS]               ╭  /tmp/out
+[SO]               ├╮                       ╿
+[CM] /usr/bin/date ╡╞ /usr/bin/grep "Hello" ╡
+[SE]               ╰╯                       ╽
+`,
+		},
+		{
+			name:    "groups",
+			command: `( echo a; echo b ) | { grep a; } > /tmp/out`,
+			expectedString: `
+[OS]                                 ╭ /tmp/out
+[SO]                  ╭╮             │
+[CM] (echo a; echo b) ╡╰ { grep a; } ╡
+[SE]                  ╽              ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				require.IsType(t, &groupStage{}, c.cmdDescriptors[0].stage)
+				assert.Len(t, c.cmdDescriptors[0].stage.(*groupStage).chain.cmdDescriptors, 2)
+				require.IsType(t, &groupStage{}, c.cmdDescriptors[1].stage)
+				assert.Len(t, c.cmdDescriptors[1].stage.(*groupStage).chain.cmdDescriptors, 1)
+			},
+		},
+		{
+			name:    "process substitution",
+			command: `diff <(sort a) <(sort b)`,
+			expectedString: `
+[SO]                                       ╿
+[CM] /usr/bin/diff "/dev/fd/3" "/dev/fd/4" ╡
+[SE]                                       ╽
+`,
+			check: func(t *testing.T, c *chain) {
+				assert.Len(t, c.hooks, 2)
+				assert.Len(t, c.cmdDescriptors[0].commandApplier, 2)
+			},
+		},
+		{
+			name:        "process substitution outside of arguments",
+			command:     `echo a > >(cat)`,
+			expectError: "process substitution is only supported in command arguments",
+		},
+		{
+			name:        "process substitution for builtin",
+			command:     `cd <(pwd)`,
+			expectError: "process substitution is not supported for the builtin 'cd'",
+		},
+		{
+			name:        "unsupported command in group",
+			command:     `( echo a & ) | grep a`,
+			expectError: "error parsing group",
+		},
+		{
+			name:        "duplicate unsupported file descriptor",
+			command:     `date 2>&3`,
+			expectError: "unsupported file descriptor duplication '2>&3'",
+		},
+		{
+			name:        "duplicate unsupported source file descriptor",
+			command:     `date 3>&1`,
+			expectError: "unsupported file descriptor duplication '3>&1'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -280,6 +548,303 @@ func TestJoinShellCmd(t *testing.T) {
 	}
 }
 
+func TestJoinShellCmd_variableExpansion(t *testing.T) {
+	t.Setenv("CMDCHAIN_TEST_ENV", "from-env")
+	t.Setenv("CMDCHAIN_TEST_OVERRIDE", "from-env")
+
+	variables := map[string]string{
+		"FILE":                   "archive.tar.gz",
+		"EMPTY":                  "",
+		"CMDCHAIN_TEST_OVERRIDE": "from-builder",
+	}
+
+	tests := []struct {
+		name         string
+		command      string
+		expectedArgs [][]string
+		expectError  string
+	}{
+		{"simple", `echo $FILE`, [][]string{{"echo", "archive.tar.gz"}}, ""},
+		{"braces", `echo ${FILE}`, [][]string{{"echo", "archive.tar.gz"}}, ""},
+		{"inside double quotes", `echo "file: ${FILE}!"`, [][]string{{"echo", "file: archive.tar.gz!"}}, ""},
+		{"inside single quotes", `echo '${FILE}'`, [][]string{{"echo", "${FILE}"}}, ""},
+		{"process environment", `echo $CMDCHAIN_TEST_ENV`, [][]string{{"echo", "from-env"}}, ""},
+		{"builder variables take precedence", `echo $CMDCHAIN_TEST_OVERRIDE`, [][]string{{"echo", "from-builder"}}, ""},
+		{"unset variable", `echo "$CMDCHAIN_TEST_UNSET"`, [][]string{{"echo", ""}}, ""},
+		{"default", `echo ${CMDCHAIN_TEST_UNSET:-"de fault"} ${EMPTY-unset} ${EMPTY:-empty}`, [][]string{{"echo", "de fault", "", "empty"}}, ""},
+		{"assign default", `echo ${NEW:=assigned} | grep $NEW`, [][]string{{"echo", "assigned"}, {"grep", "assigned"}}, ""},
+		{"alternate", `echo ${FILE:+set} ${EMPTY:+set}`, [][]string{{"echo", "set", ""}}, ""},
+		{"length", `echo ${#FILE} ${#EMPTY}`, [][]string{{"echo", "14", "0"}}, ""},
+		{"remove small suffix", `echo ${FILE%.*}`, [][]string{{"echo", "archive.tar"}}, ""},
+		{"remove large suffix", `echo ${FILE%%.*}`, [][]string{{"echo", "archive"}}, ""},
+		{"remove small prefix", `echo ${FILE#*.}`, [][]string{{"echo", "tar.gz"}}, ""},
+		{"remove large prefix", `echo ${FILE##*.}`, [][]string{{"echo", "gz"}}, ""},
+		{"remove quoted suffix", `echo ${FILE%".*"}`, [][]string{{"echo", "archive.tar.gz"}}, ""},
+		{"shell variable", `GREETING=hello; echo $GREETING world`, [][]string{{"echo", "hello", "world"}}, ""},
+		{"shell variable in next statement", `A=1 B=$A$FILE; echo $B; echo $A`, [][]string{{"echo", "1archive.tar.gz"}, {"echo", "1"}}, ""},
+		{"inline assignment is not expanded", `FILE=other echo $FILE`, [][]string{{"echo", "archive.tar.gz"}}, ""},
+		{"error if unset", `echo ${CMDCHAIN_TEST_UNSET:?must be set}`, nil, "CMDCHAIN_TEST_UNSET: must be set"},
+		{"unsupported special parameter", `echo $?`, nil, "unsupported special parameter '?'"},
+		{"unsupported expansion", `echo ${FILE/tar/zip}`, nil, "unsupported parameter expansion"},
+		{"unsupported operator", `echo ${FILE^^}`, nil, "unsupported parameter expansion operator '^^'"},
+		{"assignments only", `A=1`, nil, "no commands"},
+		{"assignment in pipe", `A=1 | echo`, nil, "assignments without command are only supported as separate statements"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := buildShellChain(Builder().WithVariables(variables).JoinShellCmd(tt.command).(*shellChain)).(*chain)
+
+			if tt.expectError != "" {
+				require.True(t, result.buildErrors.hasError)
+				assert.Contains(t, result.buildErrors.Error(), tt.expectError)
+				return
+			}
+
+			require.False(t, result.buildErrors.hasError, result.buildErrors.Error())
+			require.Len(t, result.cmdDescriptors, len(tt.expectedArgs))
+			for i, expectedArgs := range tt.expectedArgs {
+				assert.Equal(t, expectedArgs, result.cmdDescriptors[i].command.Args)
+			}
+		})
+	}
+}
+
+func TestJoinShellCmd_inlineAssignments(t *testing.T) {
+	variables := map[string]string{"FILE": "archive.tar.gz"}
+
+	result := buildShellChain(Builder().WithVariables(variables).
+		JoinShellCmd(`A=1 B=$A$FILE FILE=other C=$FILE date $A; echo $A $FILE`).(*shellChain)).(*chain)
+
+	require.False(t, result.buildErrors.hasError, result.buildErrors.Error())
+	require.Len(t, result.cmdDescriptors, 2)
+	assert.Contains(t, result.cmdDescriptors[0].command.Env, "A=1")
+	assert.Contains(t, result.cmdDescriptors[0].command.Env, "B=1archive.tar.gz")
+	assert.Contains(t, result.cmdDescriptors[0].command.Env, "C=other")
+	assert.Equal(t, []string{"date", ""}, result.cmdDescriptors[0].command.Args, "the arguments should not see the assignments")
+	assert.Equal(t, []string{"echo", "", "archive.tar.gz"}, result.cmdDescriptors[1].command.Args, "the following statements should not see the assignments")
+}
+
+func TestJoinShellCmd_commandSubstitution(t *testing.T) {
+	variables := map[string]string{"NAME": "world"}
+
+	tests := []struct {
+		name         string
+		command      string
+		expectedArgs [][]string
+		expectError  string
+	}{
+		{"simple", `echo $(echo hello)`, [][]string{{"echo", "hello"}}, ""},
+		{"inside double quotes", `echo "$(echo hello) $NAME"`, [][]string{{"echo", "hello world"}}, ""},
+		{"trailing newlines are trimmed", `echo "$(printf 'a\nb\n\n')"`, [][]string{{"echo", "a\nb"}}, ""},
+		{"pipeline", `echo $(echo hello | tr a-z A-Z)`, [][]string{{"echo", "HELLO"}}, ""},
+		{"nested", `echo $(echo $(echo nested))`, [][]string{{"echo", "nested"}}, ""},
+		{"variables", `GREETING=hi; echo $(echo $GREETING $NAME)`, [][]string{{"echo", "hi world"}}, ""},
+		{"variables of substitution are not visible outside", `echo $(X=1; echo $X) "$X"`, [][]string{{"echo", "1", ""}}, ""},
+		{"empty", `echo $()`, [][]string{{"echo", ""}}, ""},
+		{"not expanded", `echo $(echo '*')`, [][]string{{"echo", "*"}}, ""},
+		{"declaration", `export A=$(echo 1)`, [][]string{{"export", "A=1"}}, ""},
+		{"failing command", `echo $(false)`, nil, "[1:6 - 1:14] error running command substitution"},
+		{"unknown command", `echo $(non-existing-command)`, nil, "error running command substitution"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := buildInstance(Builder().WithVariables(variables).WithGlobExpansion(GlobNullUnmatched).JoinShellCmd(tt.command).(*shellChain))
+			require.False(t, result.buildErrors.hasError, result.buildErrors.Error())
+
+			// the command substitutions will be executed right before the command is started
+			err := prepareCommands(result)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, result.cmdDescriptors, len(tt.expectedArgs))
+			for i, expectedArgs := range tt.expectedArgs {
+				assert.Equal(t, expectedArgs, result.cmdDescriptors[i].command.Args)
+			}
+		})
+	}
+}
+
+func TestJoinShellCmd_commandSubstitution_assignments(t *testing.T) {
+	result := buildInstance(Builder().
+		JoinShellCmd(`A=$(echo 1) B=$A$(echo 2) date`).(*shellChain))
+	require.False(t, result.buildErrors.hasError, result.buildErrors.Error())
+	require.NoError(t, prepareCommands(result))
+
+	assert.Contains(t, result.cmdDescriptors[0].command.Env, "A=1")
+	assert.Contains(t, result.cmdDescriptors[0].command.Env, "B=12")
+}
+
+func TestJoinShellCmd_commandSubstitution_unsupported(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		expectError string
+	}{
+		{"unsupported command", `echo $(echo &)`, "error parsing command substitution"},
+		{"command name", `$(echo ls) -l`, "command substitution is not supported in the command name"},
+		{"assignment statement", `A=$(echo 1); echo $A`, "command substitution is only supported in arguments and assignments of commands"},
+		{"redirection", `echo a > $(echo file)`, "command substitution is only supported in arguments and assignments of commands"},
+		{"pattern", `echo ${A%$(echo 1)}`, "command substitution is only supported in arguments and assignments of commands"},
+		{"assigned default", `echo ${A:=$(echo 1)}`, "command substitution is only supported in arguments and assignments of commands"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := buildShellChain(Builder().JoinShellCmd(tt.command).(*shellChain)).(*chain)
+
+			require.True(t, result.buildErrors.hasError)
+			assert.Contains(t, result.buildErrors.Error(), tt.expectError)
+		})
+	}
+}
+
+// buildInstance builds the given shell command such as for a single run of its chain (see chain.instance). So the
+// preparers of the nested chains (e.g. command substitutions) refer to the chains which are run.
+func buildInstance(s *shellChain) *chain {
+	s.chain.template = false
+	return buildShellChain(s).(*chain)
+}
+
+// prepareCommands calls the preparers of all commands such as they would be called right before the commands are
+// started.
+func prepareCommands(c *chain) error {
+	// the nested chains (e.g. command substitutions) are run by the running chain
+	c.running = newRunningChain(c)
+
+	for _, cmdDescriptor := range c.cmdDescriptors {
+		for _, prepare := range cmdDescriptor.preparers {
+			if err := prepare(cmdDescriptor.command); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestJoinShellCmd_globExpansion(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, file := range []string{"a.log", "b.log", "c.txt", ".hidden.log", "with space.log", "sub/d.log", "sub/deep/e.log"} {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(tmpDir, file)), 0755))
+		require.NoError(t, os.WriteFile(path.Join(tmpDir, file), []byte{}, 0644))
+	}
+
+	tests := []struct {
+		name         string
+		policy       GlobPolicy
+		command      string
+		expectedArgs []string
+		expectError  string
+	}{
+		{"disabled", GlobDisabled, `ls *.log ~/file`, []string{"ls", "*.log", "~/file"}, ""},
+		{"star", GlobKeepUnmatched, `ls *.log`, []string{"ls", "a.log", "b.log", "with space.log"}, ""},
+		{"question mark", GlobKeepUnmatched, `ls ?.txt`, []string{"ls", "c.txt"}, ""},
+		{"character class", GlobKeepUnmatched, `ls [bc].*`, []string{"ls", "b.log", "c.txt"}, ""},
+		{"directory", GlobKeepUnmatched, `ls s*/*.log`, []string{"ls", "sub/d.log"}, ""},
+		{"globstar", GlobKeepUnmatched, `ls **/*.log`, []string{"ls", "a.log", "b.log", "sub/d.log", "sub/deep/e.log", "with space.log"}, ""},
+		{"hidden", GlobKeepUnmatched, `ls .*.log`, []string{"ls", ".hidden.log"}, ""},
+		{"absolute", GlobKeepUnmatched, `ls ` + tmpDir + `/*.txt`, []string{"ls", tmpDir + "/c.txt"}, ""},
+		{"quoted", GlobKeepUnmatched, `ls "*.log" '?.txt' "with "*`, []string{"ls", "*.log", "?.txt", "with space.log"}, ""},
+		{"variable", GlobKeepUnmatched, `EXT=txt; ls *.$EXT`, []string{"ls", "c.txt"}, ""},
+		{"tilde", GlobKeepUnmatched, `ls ~ ~/file "~/file" a~`, []string{"ls", "/home/test", "/home/test/file", "~/file", "a~"}, ""},
+		{"keep unmatched", GlobKeepUnmatched, `ls *.none a.log`, []string{"ls", "*.none", "a.log"}, ""},
+		{"null unmatched", GlobNullUnmatched, `ls *.none a.log`, []string{"ls", "a.log"}, ""},
+		{"fail unmatched", GlobFailUnmatched, `ls *.none a.log`, nil, "no matches found for pattern '*.none'"},
+		{"invalid pattern", GlobKeepUnmatched, `ls [ a[.log`, []string{"ls", "[", "a[.log"}, ""},
+		{"invalid pattern null unmatched", GlobNullUnmatched, `ls [ a.log`, []string{"ls", "a.log"}, ""},
+		{"invalid pattern fail unmatched", GlobFailUnmatched, `ls a.log [`, nil, "no matches found for pattern '['"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toTest := Builder().
+				WithVariables(map[string]string{"HOME": "/home/test"}).
+				WithGlobExpansion(tt.policy).
+				JoinShellCmd(tt.command).WithWorkingDirectory(tmpDir)
+			result := buildShellChain(toTest.(*shellChain)).(*chain)
+			require.False(t, result.buildErrors.hasError, result.buildErrors.Error())
+
+			// the patterns will be expanded right before the command is started
+			err := prepareCommands(result)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedArgs, result.cmdDescriptors[0].command.Args)
+		})
+	}
+}
+
+func TestJoinShellCmd_globExpansionAfterCd(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.Mkdir(path.Join(tmpDir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(tmpDir, "sub", "a.log"), []byte{}, 0644))
+
+	toTest := Builder().
+		WithGlobExpansion(GlobNullUnmatched).
+		JoinShellCmd("cd sub; ls *.log").WithWorkingDirectory(tmpDir)
+
+	runAndCompare(t, toTest, "a.log\n")
+}
+
+func TestJoinShellCmd_globFailUnmatchedIsStartError(t *testing.T) {
+	err := Builder().
+		WithGlobExpansion(GlobFailUnmatched).
+		JoinShellCmd("ls *.none").WithWorkingDirectory(t.TempDir()).
+		Finalize().Run()
+
+	assert.ErrorIs(t, err, ErrStart)
+	assert.NotErrorIs(t, err, ErrBuild)
+}
+
+func TestJoinShellCmd_builtins(t *testing.T) {
+	custom := func(context.Context, *ShellState, *exec.Cmd) error { return nil }
+
+	tests := []struct {
+		name            string
+		builtins        map[string]Builtin
+		command         string
+		expectedArgs    [][]string
+		expectedBuiltin []bool
+	}{
+		{"cd", nil, `cd /tmp && ls`, [][]string{{"cd", "/tmp"}, {"ls"}}, []bool{true, false}},
+		{"export", nil, `export A=1 B; ls $A`, [][]string{{"export", "A=1", "B"}, {"ls", "1"}}, []bool{true, false}},
+		{"exit", nil, `exit 3`, [][]string{{"exit", "3"}}, []bool{true}},
+		{"echo is not a default builtin", nil, `echo hello`, [][]string{{"echo", "hello"}}, []bool{false}},
+		{"opt-in", map[string]Builtin{"echo": EchoBuiltin}, `echo hello`, [][]string{{"echo", "hello"}}, []bool{true}},
+		{"custom", map[string]Builtin{"custom": custom}, `custom value | ls`, [][]string{{"custom", "value"}, {"ls"}}, []bool{true, false}},
+		{"disabled", map[string]Builtin{"cd": nil}, `cd /tmp`, [][]string{{"cd", "/tmp"}}, []bool{false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toTest := Builder().
+				WithBuiltins(tt.builtins).
+				JoinShellCmd(tt.command)
+			result := buildShellChain(toTest.(*shellChain)).(*chain)
+
+			require.False(t, result.buildErrors.hasError, result.buildErrors.Error())
+			require.Len(t, result.cmdDescriptors, len(tt.expectedArgs))
+			for i, cmdDescriptor := range result.cmdDescriptors {
+				_, isBuiltin := cmdDescriptor.stage.(*builtinStage)
+
+				assert.Equal(t, tt.expectedArgs[i], cmdDescriptor.command.Args)
+				assert.Equal(t, tt.expectedBuiltin[i], isBuiltin)
+			}
+		})
+	}
+}
+
 func TestJoinShellCmd_Multiple(t *testing.T) {
 	c := Builder().
 		JoinShellCmd("echo 'Hello, World!' | grep 'Hello'").
@@ -489,6 +1054,22 @@ func TestShellActionCollection(t *testing.T) {
 				s.WithErrorChecker(nil)
 			},
 		},
+		{"WithTimeout",
+			func(builder *MockCommandBuilder) {
+				builder.EXPECT().WithTimeout(gomock.Any())
+			},
+			func(s *shellChain) {
+				s.WithTimeout(0)
+			},
+		},
+		{"WithRetry",
+			func(builder *MockCommandBuilder) {
+				builder.EXPECT().WithRetry(gomock.Any())
+			},
+			func(s *shellChain) {
+				s.WithRetry(RetryPolicy{})
+			},
+		},
 	}
 
 	for _, tt := range testCases {
@@ -499,7 +1080,7 @@ func TestShellActionCollection(t *testing.T) {
 			mChain := NewMockCommandBuilder(ctrl)
 			tt.mock(mChain)
 
-			toTest := &shellChain{}
+			toTest := &shellChain{chain: &chain{}}
 			tt.action(toTest)
 
 			for _, action := range toTest.actions {
@@ -539,6 +1120,62 @@ func TestShellBuildBeforeNext(t *testing.T) {
 				s.JoinWithContext(t.Context(), "echo")
 			},
 		},
+		{"JoinFunc",
+			func(builder *MockCommandBuilder) {
+				builder.EXPECT().JoinFunc(gomock.Any())
+			},
+			func(s *shellChain) {
+				s.JoinFunc(nil)
+			},
+		},
+		{"JoinLineMapper",
+			func(builder *MockCommandBuilder) {
+				builder.EXPECT().JoinLineMapper(gomock.Any())
+			},
+			func(s *shellChain) {
+				s.JoinLineMapper(nil)
+			},
+		},
+		{"JoinLineFilter",
+			func(builder *MockCommandBuilder) {
+				builder.EXPECT().JoinLineFilter(gomock.Any())
+			},
+			func(s *shellChain) {
+				s.JoinLineFilter(nil)
+			},
+		},
+		{"JoinRegexFilter",
+			func(builder *MockCommandBuilder) {
+				builder.EXPECT().JoinRegexFilter(regexp.MustCompile("echo"))
+			},
+			func(s *shellChain) {
+				s.JoinRegexFilter(regexp.MustCompile("echo"))
+			},
+		},
+		{"JoinHead",
+			func(builder *MockCommandBuilder) {
+				builder.EXPECT().JoinHead(1)
+			},
+			func(s *shellChain) {
+				s.JoinHead(1)
+			},
+		},
+		{"JoinTail",
+			func(builder *MockCommandBuilder) {
+				builder.EXPECT().JoinTail(1)
+			},
+			func(s *shellChain) {
+				s.JoinTail(1)
+			},
+		},
+		{"JoinUniq",
+			func(builder *MockCommandBuilder) {
+				builder.EXPECT().JoinUniq()
+			},
+			func(s *shellChain) {
+				s.JoinUniq()
+			},
+		},
 		{"JoinShellCmd",
 			func(builder *MockCommandBuilder) {
 				builder.EXPECT().JoinShellCmd("echo")
@@ -579,7 +1216,7 @@ func TestShellBuildBeforeNext(t *testing.T) {
 			}
 
 			tt.mock(mChain)
-			toTest := &shellChain{}
+			toTest := &shellChain{chain: &chain{}}
 			tt.action(toTest)
 		})
 	}
@@ -0,0 +1,277 @@
+package cmdchain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// stage is a part of the chain which is not executed as a single process. Instead of starting the
+// command, the stage will be started. The command is only used as container for the stage's
+// configuration (streams, working directory, environment and so on).
+type stage interface {
+	// start will start the stage (non-blocking). The given pipes must be closed after the stage is done.
+	// Otherwise, the next command would wait for eternity.
+	start(command *exec.Cmd, pipes []io.Closer) error
+
+	// wait waits until the stage is done.
+	wait() error
+
+	// signal sends the given signal to the running stage.
+	signal(sig os.Signal) error
+}
+
+// funcCommandName is the name of the commands which are joined by ChainBuilder.JoinFunc.
+const funcCommandName = "func"
+
+// joinStage joins a new command for the given stage. The command will be bound to the given context (if any).
+func (c *chain) joinStage(ctx context.Context, s stage, name string, args ...string) CommandBuilder {
+	command := &exec.Cmd{}
+	if ctx != nil {
+		command = exec.CommandContext(ctx, name)
+	}
+	// the command will never be started, so its path must not be resolved
+	command.Path, command.Args, command.Err = name, append([]string{name}, args...), nil
+
+	c.JoinCmd(command)
+	c.cmdDescriptors[len(c.cmdDescriptors)-1].stage = s
+
+	return c
+}
+
+// joinFunc joins the given function with the given name and arguments. The function will be executed such as a
+// builtin.
+func (c *chain) joinFunc(fn CommandFunc, name string, args ...string) CommandBuilder {
+	call := &builtinStage{fn: func(ctx context.Context, command *exec.Cmd) error {
+		return fn(ctx, command.Stdin, command.Stdout, command.Stderr)
+	}}
+
+	return c.joinStage(nil, call, name, args...)
+}
+
+// stagePipes returns the writing end of the pipes (created by StdoutPipe()/StderrPipe()) of the command.
+// These pipes would normally be closed after the command is started.
+func (c *chain) stagePipes(cmdIndex int) (pipes []io.Closer) {
+	if c.isPipelineEnd(cmdIndex) {
+		return nil
+	}
+
+	cmdDescriptor := c.cmdDescriptors[cmdIndex]
+	if closer, isCloser := cmdDescriptor.command.Stdout.(io.Closer); isCloser && cmdDescriptor.outToIn {
+		pipes = append(pipes, closer)
+	}
+	if closer, isCloser := cmdDescriptor.command.Stderr.(io.Closer); isCloser && cmdDescriptor.errToIn {
+		pipes = append(pipes, closer)
+	}
+
+	return
+}
+
+// groupStage executes a nested chain (e.g. a subshell or brace group of a shell command) as a single stage. The
+// stdin of the stage will be the input of the nested chain. And all outputs of the nested chain will be written
+// into the stage's stdout and stderr.
+type groupStage struct {
+	chain   *chain
+	running *runningChain
+	done    chan error
+
+	// state is the shell state of the nested chain. It is relative to the state of the group itself.
+	state *ShellState
+
+	// parent is the shell state of the group (nil for subshells). It will get the changes of the nested state.
+	parent *ShellState
+}
+
+func (g *groupStage) start(command *exec.Cmd, pipes []io.Closer) error {
+	*g.state = ShellState{}
+	g.chain.inherit(command)
+
+	if command.Stdin != nil {
+		g.chain.WithInput(command.Stdin)
+	}
+
+	finalized := g.chain.Finalize()
+	if command.Stdout != nil {
+		finalized.WithOutput(command.Stdout)
+	}
+	if command.Stderr != nil {
+		finalized.WithError(command.Stderr)
+	}
+
+	// the report of the nested chain is never requested
+	running, err := g.chain.runnable().start(false)
+	if err != nil {
+		return err
+	}
+	g.running = running.(*runningChain)
+
+	g.done = make(chan error, 1)
+	go func() {
+		err := running.Wait()
+
+		for _, pipe := range pipes {
+			_ = pipe.Close()
+		}
+		g.done <- err
+	}()
+
+	return nil
+}
+
+func (g *groupStage) wait() error {
+	return <-g.done
+}
+
+func (g *groupStage) signal(sig os.Signal) error {
+	// such as a signaled subshell, the group must not continue with its next statements
+	return g.running.stop(sig)
+}
+
+func (g *groupStage) commit() {
+	if g.parent != nil {
+		g.parent.merge(*g.state)
+	}
+}
+
+func (g *groupStage) exitRequested() bool {
+	// such as in the shell, the exit of a subshell will only exit the subshell itself
+	return g.parent != nil && g.running.exitRequested
+}
+
+// builtinStage executes a builtin (see Builtin) as a single stage.
+type builtinStage struct {
+	fn     func(ctx context.Context, command *exec.Cmd) error
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan error
+
+	// exited is true if the builtin has requested to stop the execution of the following statements
+	exited bool
+
+	// state is the shell state which can be changed by the builtin (nil if the stage is not a builtin of a shell
+	// command). The builtin will change its own copy of the state (changed) at first.
+	state   *ShellState
+	changed ShellState
+	lookup  func(name string) (string, bool)
+}
+
+// shellBuiltin returns a stage for the given builtin of a shell command line. The builtin is able to change the given
+// state of the shell. The given lookup is used to look up the shell's variables.
+func shellBuiltin(ctx context.Context, builtin Builtin, state *ShellState, lookup func(string) (string, bool)) *builtinStage {
+	b := &builtinStage{ctx: ctx, state: state, lookup: lookup}
+	b.fn = func(ctx context.Context, command *exec.Cmd) error {
+		return builtin(ctx, &b.changed, command)
+	}
+	return b
+}
+
+func (b *builtinStage) start(command *exec.Cmd, pipes []io.Closer) error {
+	if command.Stdin == nil {
+		command.Stdin = bytes.NewReader(nil)
+	}
+	if command.Stdout == nil {
+		command.Stdout = io.Discard
+	}
+	if command.Stderr == nil {
+		command.Stderr = io.Discard
+	}
+
+	ctx := b.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, b.cancel = context.WithCancel(ctx)
+
+	b.exited = false
+	if b.state != nil {
+		b.changed = b.state.clone()
+		b.changed.lookup = b.lookup
+	}
+
+	b.done = make(chan error, 1)
+	go func() {
+		err := b.fn(ctx, command)
+		b.cancel()
+
+		for _, pipe := range pipes {
+			_ = pipe.Close()
+		}
+
+		var exit *exitRequest
+		if errors.As(err, &exit) {
+			b.exited = true
+
+			err = nil
+			if exit.code != 0 {
+				err = &BuiltinExitError{Code: exit.code}
+			}
+		}
+		b.done <- err
+	}()
+
+	return nil
+}
+
+func (b *builtinStage) wait() error {
+	return <-b.done
+}
+
+func (b *builtinStage) signal(os.Signal) error {
+	// builtins are executed inside the current process - so they can not be signaled. Instead, their context
+	// will be canceled.
+	b.cancel()
+	return nil
+}
+
+func (b *builtinStage) commit() {
+	if b.state != nil {
+		*b.state = b.changed
+	}
+}
+
+func (b *builtinStage) exitRequested() bool {
+	return b.exited
+}
+
+// shellStage is a stage of a shell command line which is able to change the shell's state (see ShellState) or to
+// stop the execution of the following statements (e.g. the exit builtin).
+type shellStage interface {
+	// commit makes the changes of the shell's state visible for the following statements.
+	commit()
+
+	// exitRequested checks if the stage has requested to stop the execution of the following statements.
+	exitRequested() bool
+}
+
+// completePipeline completes the given (exited) pipeline. Such as in the shell, only a pipeline which consists of a
+// single builtin or brace group is able to change the shell's state or to stop the execution of the following
+// statements. The latter will be returned.
+func (c *chain) completePipeline(p pipeline) (exited bool) {
+	if p.to-p.from != 1 {
+		return false
+	}
+
+	s, isShellStage := c.cmdDescriptors[p.from].stage.(shellStage)
+	if !isShellStage {
+		return false
+	}
+
+	s.commit()
+	return s.exitRequested()
+}
+
+// inherit passes the working directory and the environment of the given command to all commands of the chain which
+// have no own one (e.g. the commands of a group inherit them from the group).
+func (c *chain) inherit(command *exec.Cmd) {
+	for _, cmdDescriptor := range c.cmdDescriptors {
+		if cmdDescriptor.command.Dir == "" {
+			cmdDescriptor.command.Dir = command.Dir
+		}
+		if cmdDescriptor.command.Env == nil {
+			cmdDescriptor.command.Env = command.Env
+		}
+	}
+}
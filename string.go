@@ -710,17 +710,17 @@ func findPipe(c, n *cmdDescriptor) pipe {
 	return pipe{}
 }
 
-func (c *chain) toStringModel() stringModel {
+func toStringModel(cmdDescriptors []cmdDescriptor) stringModel {
 	model := stringModel{
-		Chunks: make([]modelChunk, len(c.cmdDescriptors)+2, len(c.cmdDescriptors)+2),
+		Chunks: make([]modelChunk, len(cmdDescriptors)+2, len(cmdDescriptors)+2),
 	}
-	model.Chunks[0].Pipe = findPipe(nil, &c.cmdDescriptors[0])
+	model.Chunks[0].Pipe = findPipe(nil, &cmdDescriptors[0])
 
-	for i, cmdDesc := range c.cmdDescriptors {
+	for i, cmdDesc := range cmdDescriptors {
 		i++
 
 		//isFirst := i == 1
-		isLast := i == len(c.cmdDescriptors)
+		isLast := i == len(cmdDescriptors)
 		prevChunk := &model.Chunks[i-1]
 		curChunk := &model.Chunks[i]
 		nextChunk := &model.Chunks[i+1]
@@ -768,7 +768,7 @@ func (c *chain) toStringModel() stringModel {
 		// pipes
 		////
 		if !isLast {
-			curChunk.Pipe = findPipe(&cmdDesc, &c.cmdDescriptors[i])
+			curChunk.Pipe = findPipe(&cmdDesc, &cmdDescriptors[i])
 		} else {
 			curChunk.Pipe = findPipe(&cmdDesc, nil)
 		}
@@ -861,6 +861,21 @@ func (s *stringModel) String() string {
 }
 
 func (c *chain) String() string {
-	model := c.toStringModel()
-	return model.String()
+	sb := strings.Builder{}
+
+	cmdDescriptors := c.cmdDescriptors
+	if c.template {
+		cmdDescriptors = c.finalizedDescriptors()
+	}
+
+	for i, p := range c.pipelines() {
+		if i > 0 {
+			sb.WriteString("\n[OP] " + p.operator.String() + "\n")
+		}
+
+		model := toStringModel(cmdDescriptors[p.from:p.to])
+		sb.WriteString(model.String())
+	}
+
+	return sb.String()
 }
@@ -2,8 +2,10 @@ package cmdchain
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"strings"
 	"testing"
 )
@@ -531,6 +533,33 @@ func TestChain_String(t *testing.T) {
 [ES]                             ╰  *bytes.Buffer
 			`,
 		},
+		{
+			c: Builder().
+				Join("echo", "hello world").
+				JoinFunc(func(context.Context, io.Reader, io.Writer, io.Writer) error { return nil }).
+				Finalize(),
+			e: `
+[SO]                             ╭╮      ╿
+[CM] /usr/bin/echo "hello world" ╡╰ func ╡
+[SE]                             ╽       ╽
+			`,
+		},
+		{
+			c: Builder().
+				JoinShellCmd("echo hello && echo world").
+				Finalize().WithOutput(&bytes.Buffer{}),
+			e: `
+[OS]                       ╭ *bytes.Buffer
+[SO]                       │
+[CM] /usr/bin/echo "hello" ╡
+[SE]                       ╽
+[OP] &&
+[OS]                       ╭ *bytes.Buffer
+[SO]                       │
+[CM] /usr/bin/echo "world" ╡
+[SE]                       ╽
+			`,
+		},
 	}
 
 	for i, tt := range tests {
@@ -28,8 +28,14 @@ func main() {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs)
 	go func() {
-		<-sigs
-		os.Exit(125)
+		for sig := range sigs {
+			if sig.String() == "urgent I/O condition" {
+				// SIGURG is used by the go runtime for preempting goroutines (syscall.SIGURG is not available
+				// on all platforms)
+				continue
+			}
+			os.Exit(125)
+		}
 	}()
 
 	if toErr != nil && *toErr != "" {